@@ -9,19 +9,45 @@ import (
 )
 
 // MondooAuditConfigSpec defines the desired state of MondooAuditConfig
+// +kubebuilder:validation:XValidation:rule="(has(self.mondooCredsSecretRef) && has(self.mondooCredsSecretRef.name) && size(self.mondooCredsSecretRef.name) > 0) != has(self.mondooCredsSource)",message="exactly one of mondooCredsSecretRef and mondooCredsSource has to be set"
 type MondooAuditConfigSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Config is an example field of MondooAuditConfig. Edit mondooauditconfig_types.go to remove/update
-	// +kubebuilder:validation:Required
-	// +required
-	MondooCredsSecretRef corev1.LocalObjectReference `json:"mondooCredsSecretRef"`
+	// MondooCredsSecretRef references the Secret with the Mondoo service account in the key "config".
+	// Exactly one of MondooCredsSecretRef and MondooCredsSource has to be set.
+	// +optional
+	MondooCredsSecretRef corev1.LocalObjectReference `json:"mondooCredsSecretRef,omitempty"`
+
+	// MondooCredsSource reads the Mondoo service account from an external secret store instead of a Secret,
+	// so the credentials are never stored in the cluster. It can't be combined with MondooCredsSecretRef,
+	// SpaceID, SpaceRouting or the spaceId overrides of the scans that use it.
+	// +optional
+	MondooCredsSource *MondooCredsSource `json:"mondooCredsSource,omitempty"`
 
 	// MondooTokenSecretRef can optionally hold a time-limited token that the mondoo-operator will use
 	// to create a Mondoo service account saved to the Secret specified in .spec.mondooCredsSecretRef
 	// if that Secret does not exist.
 	MondooTokenSecretRef corev1.LocalObjectReference ` json:"mondooTokenSecretRef,omitempty"`
 
+	// CredentialsCheck configures the periodic validation of the service account of MondooCredsSecretRef
+	// against the Mondoo API.
+	// +optional
+	CredentialsCheck CredentialsCheck `json:"credentialsCheck,omitempty"`
+
+	// GarbageCollection configures the deletion of the assets of resources that were not scanned anymore
+	// after successful scans.
+	// +optional
+	GarbageCollection GarbageCollection `json:"gc,omitempty"`
+
+	// DeletionPolicy decides what happens to the assets of the scans in Mondoo when the MondooAuditConfig
+	// is deleted. Retain keeps them, Purge deletes the assets of all scans before the MondooAuditConfig is
+	// released. If purging doesn't succeed within 5 minutes, the assets are retained. They are retained with
+	// spec.gc.dryRun, too, and while another MondooAuditConfig sends assets to the same space.
+	// +optional
+	// +kubebuilder:validation:Enum=Retain;Purge
+	// +kubebuilder:default=Retain
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	Scanner             Scanner             `json:"scanner,omitempty"`
 	KubernetesResources KubernetesResources `json:"kubernetesResources,omitempty"`
 	Nodes               Nodes               `json:"nodes,omitempty"`
@@ -36,11 +62,27 @@ type MondooAuditConfigSpec struct {
 	// +optional
 	SpaceID string `json:"spaceId,omitempty"`
 
+	// SpaceRouting routes the assets of namespaces of the operator's cluster to other Mondoo spaces. The first
+	// rule that matches a namespace wins. Assets of the other namespaces and cluster-wide assets go to the
+	// default space: the space of SpaceID, or the space of the service account. The service account needs
+	// access to all routed spaces.
+	// +optional
+	SpaceRouting []SpaceRoutingRule `json:"spaceRouting,omitempty"`
+
 	// Annotations allows adding custom annotations to all scanned assets. These key-value pairs
 	// will be attached to every asset discovered by the operator, making them searchable
 	// and filterable in the Mondoo Console.
 	Annotations map[string]string `json:"annotations,omitempty"`
 
+	// AnnotationsFromLabels adds annotations to the scanned assets with values taken from Kubernetes labels.
+	// The key is the annotation key and the value selects the label: metadata.labels['<label>'] for a label of
+	// the scanned resource, or namespace.labels['<label>'] for a label of its namespace. Assets without the
+	// label don't get the annotation, and a label value overrides an annotation with the same key in
+	// Annotations. The scheduled Kubernetes resources scan of the operator's cluster only supports
+	// namespace labels, the resource watcher supports both.
+	// +optional
+	AnnotationsFromLabels map[string]string `json:"annotationsFromLabels,omitempty"`
+
 	// Admission is DEPRECATED and ignored. Admission webhooks were removed in v12.1.0.
 	// The operator will automatically clean up any orphaned admission resources.
 	// See docs/admission-migration-guide.md for migration instructions.
@@ -48,10 +90,146 @@ type MondooAuditConfigSpec struct {
 	Admission *DeprecatedAdmission `json:"admission,omitempty"`
 }
 
+// MondooCredsSource configures the external secret store the Mondoo service account is read from. Exactly one
+// of Vault and CSI has to be set.
+type MondooCredsSource struct {
+	// Vault reads the service account from a secret of a HashiCorp Vault KV version 2 secrets engine.
+	// +optional
+	Vault *VaultCredsSource `json:"vault,omitempty"`
+
+	// CSI mounts the service account with the Secrets Store CSI driver.
+	// +optional
+	CSI *CSICredsSource `json:"csi,omitempty"`
+}
+
+// VaultCredsSource reads the Mondoo service account from Vault with the Kubernetes auth method. The operator
+// authenticates with its own service account, the scan pods with theirs.
+type VaultCredsSource struct {
+	// VaultAddr is the address of the Vault server.
+	// Example: "https://vault.example.com:8200"
+	// +kubebuilder:validation:Required
+	VaultAddr string `json:"vaultAddr"`
+
+	// AuthPath is the Vault Kubernetes auth method mount path.
+	// +optional
+	// +kubebuilder:default="auth/kubernetes"
+	AuthPath string `json:"authPath,omitempty"`
+
+	// AuthRole is the Vault role for authenticating the service accounts of the operator and the scan pods.
+	// +kubebuilder:validation:Required
+	AuthRole string `json:"authRole"`
+
+	// KVMount is the mount path of the KV version 2 secrets engine.
+	// +optional
+	// +kubebuilder:default="secret"
+	KVMount string `json:"kvMount,omitempty"`
+
+	// Path is the path of the secret in the KV secrets engine.
+	// Example: "mondoo/operator"
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Key is the key of the secret that holds the service account. The integration MRN is read from the
+	// key "integrationmrn" of the same secret.
+	// +optional
+	// +kubebuilder:default="config"
+	Key string `json:"key,omitempty"`
+
+	// CACertSecretRef references a Secret containing Vault's CA certificate
+	// for TLS verification. The Secret must have a key "ca.crt".
+	// +optional
+	CACertSecretRef *corev1.LocalObjectReference `json:"caCertSecretRef,omitempty"`
+}
+
+// CSICredsSource mounts the Mondoo service account with a SecretProviderClass of the Secrets Store CSI driver.
+// The operator reads it from the SecretProviderClass mounted into the operator pod at
+// /etc/opt/mondoo/csi-credentials/<secretProviderClass>.
+type CSICredsSource struct {
+	// Driver is the name of the CSI driver.
+	// +optional
+	// +kubebuilder:default="secrets-store.csi.k8s.io"
+	Driver string `json:"driver,omitempty"`
+
+	// SecretProviderClass is the name of the SecretProviderClass in the namespace of the MondooAuditConfig.
+	// +kubebuilder:validation:Required
+	SecretProviderClass string `json:"secretProviderClass"`
+
+	// ObjectName is the file name of the service account in the mounted volume. The integration MRN is read
+	// from the file "integrationmrn" if it exists.
+	// +optional
+	// +kubebuilder:default="config"
+	ObjectName string `json:"objectName,omitempty"`
+}
+
+// DeletionPolicy decides what happens to the assets of a MondooAuditConfig when it is deleted.
+type DeletionPolicy string
+
+const (
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	DeletionPolicyPurge  DeletionPolicy = "Purge"
+)
+
+// GarbageCollection configures the deletion of stale scan assets. Before the operator deletes them, it can list
+// them to report them or to hold back deletions that match unexpectedly many assets.
+type GarbageCollection struct {
+	// DryRun lists the stale assets in status.garbageCollection instead of deleting them.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ConfirmThreshold holds back a garbage collection that matches more assets. It is reported with the
+	// result NeedsConfirmation in status.garbageCollection. To confirm it, raise the threshold, the next
+	// garbage collection then deletes the assets. 0 deletes the stale assets without listing them first.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ConfirmThreshold int32 `json:"confirmThreshold,omitempty"`
+}
+
+// CredentialsCheck configures the periodic validation of the Mondoo service account. Credentials the Mondoo
+// API rejects are reported in the CredentialsInvalid condition.
+type CredentialsCheck struct {
+	// Disable turns off the validation.
+	// +optional
+	Disable bool `json:"disable,omitempty"`
+
+	// Interval is the time between two validations. Defaults to 1h. The credentials are validated again
+	// right away when the Secret changes.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// ReRegister re-creates the service account from the token of MondooTokenSecretRef when the Mondoo API
+	// rejects the credentials. The token has to be valid for the time the service account may be re-created.
+	// Every re-registration is recorded as an Event of the MondooAuditConfig.
+	// +optional
+	ReRegister bool `json:"reRegister,omitempty"`
+}
+
 type Filtering struct {
+	// Namespaces selects the namespaces to watch/scan. Entries of Include and Exclude are namespace names or
+	// glob patterns (e.g. "team-*-prod"). The operator resolves patterns and the NamespaceSelector to the
+	// matching namespaces of its own cluster, and updates them as namespaces are created or deleted. External
+	// clusters only support namespace names.
 	Namespaces FilteringSpec `json:"namespaces,omitempty"`
 }
 
+// SpaceRoutingRule routes the assets of the matching namespaces to a Mondoo space. At least one of
+// Namespaces and NamespaceSelector has to be set.
+type SpaceRoutingRule struct {
+	// SpaceID is the ID of the Mondoo space the assets are routed to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	SpaceID string `json:"spaceId"`
+
+	// Namespaces are the names or glob patterns (e.g. "team-a-*") of the routed namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the routed namespaces by their labels. When Namespaces is set as well,
+	// a namespace has to match both.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
 type FilteringSpec struct {
 	// Include is the list of resources to watch/scan. Setting Include overrides anything in the
 	// Exclude list as specifying an Include list is effectively excluding everything except for what
@@ -61,6 +239,11 @@ type FilteringSpec struct {
 	// Exclude is the list of resources to ignore for any watching/scanning actions. Use this if
 	// the goal is to watch/scan all resources except for this Exclude list.
 	Exclude []string `json:"exclude,omitempty"`
+
+	// NamespaceSelector only selects the namespaces whose labels match the selector. When Include is set as
+	// well, a namespace has to match both. It only applies to namespace filtering.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 type ConsoleIntegration struct {
@@ -119,10 +302,38 @@ type KubernetesResources struct {
 	// +optional
 	ActiveDeadline *metav1.Duration `json:"activeDeadline,omitempty"`
 
+	// Discovery selects the resource kinds the scheduled Kubernetes resources scans discover as assets,
+	// for the operator's cluster and all external clusters. If not specified, all supported kinds are discovered.
+	// +optional
+	Discovery *KubernetesResourcesDiscovery `json:"discovery,omitempty"`
+
 	// ExternalClusters defines remote K8s clusters to scan from this operator instance.
 	// Each external cluster will have its own CronJob created with the appropriate kubeconfig.
 	// +optional
 	ExternalClusters []ExternalCluster `json:"externalClusters,omitempty"`
+
+	// SpaceID overrides spec.spaceId for the Kubernetes resources scans of the operator's cluster and the
+	// resource watcher. External clusters inherit it unless they set their own.
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// MondooCredsSecretRef overrides spec.mondooCredsSecretRef for the Kubernetes resources scans of the
+	// operator's cluster and the resource watcher. External clusters inherit it unless they set their own.
+	// +optional
+	MondooCredsSecretRef *corev1.LocalObjectReference `json:"mondooCredsSecretRef,omitempty"`
+}
+
+// KubernetesResourcesDiscovery selects resource kinds by their plural name, e.g. deployments. Supported kinds
+// are clusters, pods, jobs, cronjobs, statefulsets, deployments, replicasets, daemonsets, ingresses, namespaces
+// and services. Unsupported kinds are reported in the K8sResourcesScanningDegraded condition.
+type KubernetesResourcesDiscovery struct {
+	// Include is the list of resource kinds to discover. If not specified, all supported kinds are discovered.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude is the list of resource kinds not to discover. It is applied after Include.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // ResourceWatcherSpec defines the configuration for real-time resource watching.
@@ -155,6 +366,46 @@ type ResourceWatcherSpec struct {
 	// deployments, daemonsets, statefulsets, replicasets. When true, defaults to:
 	// pods, deployments, daemonsets, statefulsets, replicasets, jobs, cronjobs, services, ingresses, namespaces
 	ResourceTypes []string `json:"resourceTypes,omitempty"`
+
+	// LabelSelector restricts the watched resources to those whose labels match the selector.
+	// Changes to resources that do not match are ignored. If not specified, all resources are watched.
+	// Independently of the selector, resources annotated with mondoo.com/watch: "false" are never
+	// watched.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// ResolveOwners makes the watcher follow the controller ownerReferences of a changed resource and
+	// scan its top-level owner instead, e.g. the Deployment instead of its ReplicaSet and Pods.
+	// This avoids scanning dozens of near-identical objects during a rollout.
+	// +optional
+	ResolveOwners bool `json:"resolveOwners,omitempty"`
+
+	// IncludeStandalonePods controls whether Pods without a controlling owner are still scanned
+	// when ResolveOwners is enabled. Has no effect if ResolveOwners is disabled.
+	// +optional
+	IncludeStandalonePods bool `json:"includeStandalonePods,omitempty"`
+
+	// InitialScan makes the watcher scan all watched resources once it has started, instead of only
+	// scanning resources that change afterwards. Workloads are queued first, and the resources are
+	// scanned in batches that respect MinimumScanInterval.
+	// +optional
+	InitialScan bool `json:"initialScan,omitempty"`
+
+	// ResyncInterval periodically rescans all watched resources, the same way as InitialScan does.
+	// Resync scans are disabled if not specified.
+	// +optional
+	ResyncInterval metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// ReplaceScheduledScan removes the scheduled Kubernetes resources scan CronJob of the operator's
+	// cluster and relies on the resource watcher instead. Requires ResyncInterval and implies InitialScan.
+	// Only applies to kubernetesResources.resourceWatcher, external clusters keep their scheduled scans.
+	// +optional
+	ReplaceScheduledScan bool `json:"replaceScheduledScan,omitempty"`
+
+	// PurgeDeletedAssets deletes the asset of a watched resource in Mondoo Platform when the resource is
+	// deleted, instead of leaving it to the garbage collection after the next scheduled scan.
+	// +optional
+	PurgeDeletedAssets bool `json:"purgeDeletedAssets,omitempty"`
 }
 
 // ExternalCluster defines configuration for scanning a remote K8s cluster
@@ -198,6 +449,16 @@ type ExternalCluster struct {
 	// +optional
 	Schedule string `json:"schedule,omitempty"`
 
+	// SpaceID overrides the space the assets of this cluster are sent to.
+	// If not specified, uses kubernetesResources.spaceId or spec.spaceId.
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// MondooCredsSecretRef overrides the Mondoo credentials used for this cluster.
+	// If not specified, uses kubernetesResources.mondooCredsSecretRef or spec.mondooCredsSecretRef.
+	// +optional
+	MondooCredsSecretRef *corev1.LocalObjectReference `json:"mondooCredsSecretRef,omitempty"`
+
 	// Filtering allows namespace filtering specific to this external cluster.
 	// If omitted, the external cluster inherits the global filtering from MondooAuditConfigSpec.Filtering.
 	// Set an empty filtering object to scan all namespaces for this external cluster even when global filtering is configured.
@@ -214,12 +475,19 @@ type ExternalCluster struct {
 	// Set an empty object to scan all images even when a global filter is configured.
 	// Only applies when ContainerImageScanning is true.
 	// +optional
+	// +kubebuilder:validation:XValidation:rule="!has(self.namespaceSelector)",message="namespaceSelector only applies to namespace filtering"
 	Repositories *FilteringSpec `json:"repositories,omitempty"`
 
 	// PrivateRegistriesPullSecretRef references a Secret containing registry credentials
 	// for pulling/scanning private images in this remote cluster.
 	// +optional
 	PrivateRegistriesPullSecretRef *corev1.LocalObjectReference `json:"privateRegistriesPullSecretRef,omitempty"`
+
+	// ResourceWatcher configures a resource watcher for this external cluster. The watcher
+	// authenticates to the remote cluster the same way the scheduled scans do and triggers
+	// scans when resources in the remote cluster change.
+	// +optional
+	ResourceWatcher *ResourceWatcherSpec `json:"resourceWatcher,omitempty"`
 }
 
 // ServiceAccountAuth defines authentication using a Kubernetes service account token
@@ -463,6 +731,14 @@ type Nodes struct {
 	// Env allows setting extra environment variables for the node scanner. If the operator sets already an env
 	// variable with the same name, the value specified here will override it.
 	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// SpaceID overrides spec.spaceId for the node scans.
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// MondooCredsSecretRef overrides spec.mondooCredsSecretRef for the node scans.
+	// +optional
+	MondooCredsSecretRef *corev1.LocalObjectReference `json:"mondooCredsSecretRef,omitempty"`
 }
 
 type Containers struct {
@@ -486,6 +762,7 @@ type Containers struct {
 	// "docker.io/library/*"). When Include is set, only images matching the patterns
 	// are scanned. When Exclude is set, matching images are skipped.
 	// +optional
+	// +kubebuilder:validation:XValidation:rule="!has(self.namespaceSelector)",message="namespaceSelector only applies to namespace filtering"
 	Repositories FilteringSpec `json:"repositories,omitempty"`
 
 	// WorkloadIdentity configures Workload Identity Federation for authenticating to cloud
@@ -502,6 +779,35 @@ type Containers struct {
 	// from the cnspec scan, skipping the expensive image pull.
 	// +optional
 	ScanCache *ScanCacheConfig `json:"scanCache,omitempty"`
+
+	// EventDriven configures targeted scans of container images as soon as they start running
+	// in the cluster, in addition to the scheduled scan.
+	// +optional
+	EventDriven EventDrivenImageScanning `json:"eventDriven,omitempty"`
+
+	// SpaceID overrides spec.spaceId for the container image scans.
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// MondooCredsSecretRef overrides spec.mondooCredsSecretRef for the container image scans.
+	// +optional
+	MondooCredsSecretRef *corev1.LocalObjectReference `json:"mondooCredsSecretRef,omitempty"`
+}
+
+// EventDrivenImageScanning configures targeted scans of newly deployed container images.
+type EventDrivenImageScanning struct {
+	// Enable starts a scan Job for running container images whose digest is not running in
+	// the cluster yet, instead of waiting for the next scheduled scan. Images that are already
+	// running when the operator starts are left to the scheduled scan, including images rolled
+	// out while the operator was down or restarting.
+	Enable bool `json:"enable,omitempty"`
+
+	// BatchInterval is the minimum time between two targeted scan Jobs. New images discovered
+	// in the meantime are batched into the next Job.
+	// Default is 2 minutes.
+	// +kubebuilder:default="2m"
+	// +optional
+	BatchInterval metav1.Duration `json:"batchInterval,omitempty"`
 }
 
 // ScanCacheConfig configures server-side score refresh for container image scans.
@@ -583,10 +889,15 @@ type MondooAuditConfigStatus struct {
 	CnspecImageDigest string `json:"cnspecImageDigest,omitempty"`
 
 	// LastK8sResourceGarbageCollectionTime tracks the last time the operator performed
-	// garbage collection of stale K8s resource scan assets.
+	// garbage collection of stale K8s resource scan assets of the operator's cluster.
 	// +optional
 	LastK8sResourceGarbageCollectionTime *metav1.Time `json:"lastK8sResourceGarbageCollectionTime,omitempty"`
 
+	// ExternalClusters tracks the garbage collection of the assets of each external cluster. Clusters that were
+	// removed from spec.kubernetesResources.externalClusters stay listed until their assets were purged.
+	// +optional
+	ExternalClusters []ExternalClusterStatus `json:"externalClusters,omitempty"`
+
 	// LastNodeScanGarbageCollectionTime tracks the last time the operator performed
 	// garbage collection of stale node scan assets.
 	// +optional
@@ -602,6 +913,100 @@ type MondooAuditConfigStatus struct {
 	// Only set when ConsoleIntegration is enabled.
 	// +optional
 	ScanningPaused bool `json:"scanningPaused,omitempty"`
+
+	// ScanOptOut counts the resources of the operator's cluster that opted out of scanning with the
+	// mondoo.com/scan: "false" annotation.
+	// +optional
+	ScanOptOut *ScanOptOutStatus `json:"scanOptOut,omitempty"`
+
+	// SpaceRouting lists the spaces spec.spaceRouting routes namespaces to.
+	// +optional
+	SpaceRouting []SpaceRoutingStatus `json:"spaceRouting,omitempty"`
+
+	// GarbageCollection shows the last garbage collection of each scan type and Mondoo scope.
+	// +optional
+	GarbageCollection []GarbageCollectionStatus `json:"garbageCollection,omitempty"`
+
+	// CredentialsCheck shows the last validation of the credentials of spec.mondooCredsSecretRef.
+	// +optional
+	CredentialsCheck *CredentialsCheckStatus `json:"credentialsCheck,omitempty"`
+}
+
+// ExternalClusterStatus tracks the assets of an external cluster.
+type ExternalClusterStatus struct {
+	// Name is the name of the external cluster.
+	Name string `json:"name"`
+	// SpaceID is the space the assets of the cluster are sent to, if it is not the space of the service account.
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+	// MondooCredsSecretRef is the Secret with the credentials the scans of the cluster use. It is kept to
+	// purge the assets of the cluster after it was removed.
+	// +optional
+	MondooCredsSecretRef string `json:"mondooCredsSecretRef,omitempty"`
+	// LastGarbageCollectionTime is the last time the operator performed garbage collection of the stale
+	// assets of the cluster, or tried to purge the assets of a removed cluster.
+	// +optional
+	LastGarbageCollectionTime *metav1.Time `json:"lastGarbageCollectionTime,omitempty"`
+	// LegacyAssetsCollected is set once the assets the cluster sent with the managed-by label of the operator's
+	// cluster, before external clusters had their own label, were garbage collected.
+	// +optional
+	LegacyAssetsCollected bool `json:"legacyAssetsCollected,omitempty"`
+}
+
+// GarbageCollectionStatus shows the last garbage collection of the assets of a scan type in a Mondoo space
+// or organization.
+type GarbageCollectionStatus struct {
+	// Scan is the scan type whose assets were collected: k8s-resources, containers, nodes, or
+	// cluster-<name> for an external cluster.
+	Scan string `json:"scan"`
+	// ScopeMrn is the MRN of the space or organization the assets were collected in.
+	ScopeMrn string `json:"scopeMrn"`
+	// Time is when the garbage collection ran.
+	Time metav1.Time `json:"time"`
+	// Result is Purged if the stale assets were deleted, DryRun if they were only listed and
+	// NeedsConfirmation if more of them matched than spec.gc.confirmThreshold allows.
+	Result GarbageCollectionResult `json:"result"`
+	// MatchingAssets is the number of stale assets. It is only set if they were listed before purging.
+	// +optional
+	MatchingAssets *int64 `json:"matchingAssets,omitempty"`
+	// Assets lists the names of up to 20 of the stale assets.
+	// +optional
+	Assets []string `json:"assets,omitempty"`
+}
+
+// GarbageCollectionResult is the outcome of a garbage collection.
+type GarbageCollectionResult string
+
+const (
+	GarbageCollectionPurged            GarbageCollectionResult = "Purged"
+	GarbageCollectionDryRun            GarbageCollectionResult = "DryRun"
+	GarbageCollectionNeedsConfirmation GarbageCollectionResult = "NeedsConfirmation"
+)
+
+// CredentialsCheckStatus shows the last validation of the Mondoo service account.
+type CredentialsCheckStatus struct {
+	// LastCheckTime is the last time the credentials were validated against the Mondoo API.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+	// SecretResourceVersion is the resourceVersion of the validated credentials Secret.
+	// +optional
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+}
+
+// SpaceRoutingStatus shows how many namespaces are routed to a space.
+type SpaceRoutingStatus struct {
+	// SpaceID is the ID of the Mondoo space.
+	SpaceID string `json:"spaceId"`
+	// Namespaces is the number of namespaces whose assets are routed to the space.
+	Namespaces int32 `json:"namespaces"`
+}
+
+// ScanOptOutStatus counts the resources that are skipped because they opted out of scanning.
+type ScanOptOutStatus struct {
+	// Namespaces is the number of namespaces that opted out. All resources in them are skipped.
+	Namespaces int32 `json:"namespaces"`
+	// Workloads is the number of Deployments, DaemonSets, StatefulSets, CronJobs and standalone
+	// Jobs and Pods that opted out, outside of the namespaces that opted out.
+	Workloads int32 `json:"workloads"`
 }
 
 type MondooAuditConfigCondition struct {
@@ -646,6 +1051,13 @@ const (
 	MondooIntegrationDegraded MondooAuditConfigConditionType = "IntegrationDegraded"
 	// ScanningPausedCondition indicates that scanning has been paused from the Mondoo console
 	ScanningPausedCondition MondooAuditConfigConditionType = "ScanningPaused"
+	// ReferencedSecretsDegraded indicates that a Secret referenced by the MondooAuditConfig is missing or malformed
+	ReferencedSecretsDegraded MondooAuditConfigConditionType = "ReferencedSecretsDegraded"
+	// CredentialsInvalid indicates that the Mondoo API rejected the credentials of spec.mondooCredsSecretRef
+	CredentialsInvalid MondooAuditConfigConditionType = "CredentialsInvalid"
+	// AssetsPurgeFailed indicates that the assets of a deleted MondooAuditConfig with deletionPolicy Purge
+	// couldn't be deleted yet
+	AssetsPurgeFailed MondooAuditConfigConditionType = "AssetsPurgeFailed"
 )
 
 //+kubebuilder:object:root=true
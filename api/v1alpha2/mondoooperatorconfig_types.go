@@ -28,6 +28,25 @@ type MondooOperatorConfigSpec struct {
 	NoProxy *string `json:"noProxy,omitempty"`
 	// ContainerProxy specifies a proxy to use for container images.
 	ContainerProxy *string `json:"containerProxy,omitempty"`
+	// HttpProxySecretRef selects a Secret key with the URL of the HTTP proxy. Use it instead of HttpProxy
+	// for URLs that contain credentials. The Secret is read from the namespace of each MondooAuditConfig.
+	// +optional
+	HttpProxySecretRef *corev1.SecretKeySelector `json:"httpProxySecretRef,omitempty"`
+	// HttpsProxySecretRef selects a Secret key with the URL of the HTTPS proxy. Use it instead of HttpsProxy
+	// for URLs that contain credentials. The Secret is read from the namespace of each MondooAuditConfig.
+	// +optional
+	HttpsProxySecretRef *corev1.SecretKeySelector `json:"httpsProxySecretRef,omitempty"`
+	// ContainerProxySecretRef selects a Secret key with the URL of the container image proxy. Use it instead
+	// of ContainerProxy for URLs that contain credentials. The Secret is read from the namespace of each
+	// MondooAuditConfig.
+	// +optional
+	ContainerProxySecretRef *corev1.SecretKeySelector `json:"containerProxySecretRef,omitempty"`
+	// ProxyCredentialsSecretRef references a Secret of type kubernetes.io/basic-auth with the keys "username"
+	// and "password". They are added to the URLs of HttpProxy, HttpsProxy and ContainerProxy at runtime, so
+	// they don't show up in pod specs, inventories or logs. The values must be URL-encoded. The Secret is read
+	// from the namespace of each MondooAuditConfig.
+	// +optional
+	ProxyCredentialsSecretRef *corev1.LocalObjectReference `json:"proxyCredentialsSecretRef,omitempty"`
 	// ImagePullSecrets specifies the name of the Secret to use for pulling images for all Mondoo components.
 	// The secret must be of type kubernetes.io/dockerconfigjson.
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
@@ -51,6 +70,17 @@ type MondooOperatorConfigSpec struct {
 	// without proxy (e.g., internal mirror) but other components need proxy for external access.
 	// Default: false (proxy settings are applied to all components)
 	SkipProxyForCnspec bool `json:"skipProxyForCnspec,omitempty"`
+	// CABundleSecretRef references a Secret with additional PEM encoded CA certificates in the key "ca.crt".
+	// They are trusted in addition to the system CAs by the operator's calls to the Mondoo API and by all
+	// scan workloads, e.g. for TLS-inspecting proxies or a private Mondoo deployment. The Secret is read from
+	// the namespace of each MondooAuditConfig.
+	// +optional
+	CABundleSecretRef *corev1.LocalObjectReference `json:"caBundleSecretRef,omitempty"`
+	// ClientCertificateSecretRef references a Secret of type kubernetes.io/tls whose certificate is presented
+	// to the Mondoo API and proxies that require mutual TLS. The Secret is read from the namespace of each
+	// MondooAuditConfig and is mounted into all scan workloads.
+	// +optional
+	ClientCertificateSecretRef *corev1.LocalObjectReference `json:"clientCertificateSecretRef,omitempty"`
 }
 
 type Metrics struct {
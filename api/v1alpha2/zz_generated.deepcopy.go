@@ -28,6 +28,21 @@ func (in *AKSWorkloadIdentity) DeepCopy() *AKSWorkloadIdentity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSICredsSource) DeepCopyInto(out *CSICredsSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSICredsSource.
+func (in *CSICredsSource) DeepCopy() *CSICredsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CSICredsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsoleIntegration) DeepCopyInto(out *ConsoleIntegration) {
 	*out = *in
@@ -70,6 +85,12 @@ func (in *Containers) DeepCopyInto(out *Containers) {
 		*out = new(ScanCacheConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	out.EventDriven = in.EventDriven
+	if in.MondooCredsSecretRef != nil {
+		in, out := &in.MondooCredsSecretRef, &out.MondooCredsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Containers.
@@ -82,6 +103,42 @@ func (in *Containers) DeepCopy() *Containers {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsCheck) DeepCopyInto(out *CredentialsCheck) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsCheck.
+func (in *CredentialsCheck) DeepCopy() *CredentialsCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsCheckStatus) DeepCopyInto(out *CredentialsCheckStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsCheckStatus.
+func (in *CredentialsCheckStatus) DeepCopy() *CredentialsCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeprecatedAdmission) DeepCopyInto(out *DeprecatedAdmission) {
 	*out = *in
@@ -142,6 +199,22 @@ func (in *EKSWorkloadIdentity) DeepCopy() *EKSWorkloadIdentity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventDrivenImageScanning) DeepCopyInto(out *EventDrivenImageScanning) {
+	*out = *in
+	out.BatchInterval = in.BatchInterval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventDrivenImageScanning.
+func (in *EventDrivenImageScanning) DeepCopy() *EventDrivenImageScanning {
+	if in == nil {
+		return nil
+	}
+	out := new(EventDrivenImageScanning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalCluster) DeepCopyInto(out *ExternalCluster) {
 	*out = *in
@@ -170,6 +243,11 @@ func (in *ExternalCluster) DeepCopyInto(out *ExternalCluster) {
 		*out = new(VaultAuthConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MondooCredsSecretRef != nil {
+		in, out := &in.MondooCredsSecretRef, &out.MondooCredsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.Filtering != nil {
 		in, out := &in.Filtering, &out.Filtering
 		*out = new(Filtering)
@@ -185,6 +263,11 @@ func (in *ExternalCluster) DeepCopyInto(out *ExternalCluster) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.ResourceWatcher != nil {
+		in, out := &in.ResourceWatcher, &out.ResourceWatcher
+		*out = new(ResourceWatcherSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalCluster.
@@ -197,6 +280,25 @@ func (in *ExternalCluster) DeepCopy() *ExternalCluster {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalClusterStatus) DeepCopyInto(out *ExternalClusterStatus) {
+	*out = *in
+	if in.LastGarbageCollectionTime != nil {
+		in, out := &in.LastGarbageCollectionTime, &out.LastGarbageCollectionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalClusterStatus.
+func (in *ExternalClusterStatus) DeepCopy() *ExternalClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Filtering) DeepCopyInto(out *Filtering) {
 	*out = *in
@@ -226,6 +328,11 @@ func (in *FilteringSpec) DeepCopyInto(out *FilteringSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilteringSpec.
@@ -253,6 +360,47 @@ func (in *GKEWorkloadIdentity) DeepCopy() *GKEWorkloadIdentity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollection) DeepCopyInto(out *GarbageCollection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollection.
+func (in *GarbageCollection) DeepCopy() *GarbageCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollectionStatus) DeepCopyInto(out *GarbageCollectionStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.MatchingAssets != nil {
+		in, out := &in.MatchingAssets, &out.MatchingAssets
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Assets != nil {
+		in, out := &in.Assets, &out.Assets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollectionStatus.
+func (in *GarbageCollectionStatus) DeepCopy() *GarbageCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Image) DeepCopyInto(out *Image) {
 	*out = *in
@@ -277,6 +425,11 @@ func (in *KubernetesResources) DeepCopyInto(out *KubernetesResources) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(KubernetesResourcesDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ExternalClusters != nil {
 		in, out := &in.ExternalClusters, &out.ExternalClusters
 		*out = make([]ExternalCluster, len(*in))
@@ -284,6 +437,11 @@ func (in *KubernetesResources) DeepCopyInto(out *KubernetesResources) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MondooCredsSecretRef != nil {
+		in, out := &in.MondooCredsSecretRef, &out.MondooCredsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesResources.
@@ -296,6 +454,31 @@ func (in *KubernetesResources) DeepCopy() *KubernetesResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesResourcesDiscovery) DeepCopyInto(out *KubernetesResourcesDiscovery) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesResourcesDiscovery.
+func (in *KubernetesResourcesDiscovery) DeepCopy() *KubernetesResourcesDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesResourcesDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metrics) DeepCopyInto(out *Metrics) {
 	*out = *in
@@ -403,13 +586,27 @@ func (in *MondooAuditConfigList) DeepCopyObject() runtime.Object {
 func (in *MondooAuditConfigSpec) DeepCopyInto(out *MondooAuditConfigSpec) {
 	*out = *in
 	out.MondooCredsSecretRef = in.MondooCredsSecretRef
+	if in.MondooCredsSource != nil {
+		in, out := &in.MondooCredsSource, &out.MondooCredsSource
+		*out = new(MondooCredsSource)
+		(*in).DeepCopyInto(*out)
+	}
 	out.MondooTokenSecretRef = in.MondooTokenSecretRef
+	in.CredentialsCheck.DeepCopyInto(&out.CredentialsCheck)
+	out.GarbageCollection = in.GarbageCollection
 	in.Scanner.DeepCopyInto(&out.Scanner)
 	in.KubernetesResources.DeepCopyInto(&out.KubernetesResources)
 	in.Nodes.DeepCopyInto(&out.Nodes)
 	out.ConsoleIntegration = in.ConsoleIntegration
 	in.Filtering.DeepCopyInto(&out.Filtering)
 	in.Containers.DeepCopyInto(&out.Containers)
+	if in.SpaceRouting != nil {
+		in, out := &in.SpaceRouting, &out.SpaceRouting
+		*out = make([]SpaceRoutingRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -417,6 +614,13 @@ func (in *MondooAuditConfigSpec) DeepCopyInto(out *MondooAuditConfigSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.AnnotationsFromLabels != nil {
+		in, out := &in.AnnotationsFromLabels, &out.AnnotationsFromLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Admission != nil {
 		in, out := &in.Admission, &out.Admission
 		*out = new(DeprecatedAdmission)
@@ -453,6 +657,13 @@ func (in *MondooAuditConfigStatus) DeepCopyInto(out *MondooAuditConfigStatus) {
 		in, out := &in.LastK8sResourceGarbageCollectionTime, &out.LastK8sResourceGarbageCollectionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ExternalClusters != nil {
+		in, out := &in.ExternalClusters, &out.ExternalClusters
+		*out = make([]ExternalClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.LastNodeScanGarbageCollectionTime != nil {
 		in, out := &in.LastNodeScanGarbageCollectionTime, &out.LastNodeScanGarbageCollectionTime
 		*out = (*in).DeepCopy()
@@ -461,6 +672,28 @@ func (in *MondooAuditConfigStatus) DeepCopyInto(out *MondooAuditConfigStatus) {
 		in, out := &in.LastContainerImageGarbageCollectionTime, &out.LastContainerImageGarbageCollectionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ScanOptOut != nil {
+		in, out := &in.ScanOptOut, &out.ScanOptOut
+		*out = new(ScanOptOutStatus)
+		**out = **in
+	}
+	if in.SpaceRouting != nil {
+		in, out := &in.SpaceRouting, &out.SpaceRouting
+		*out = make([]SpaceRoutingStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = make([]GarbageCollectionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CredentialsCheck != nil {
+		in, out := &in.CredentialsCheck, &out.CredentialsCheck
+		*out = new(CredentialsCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MondooAuditConfigStatus.
@@ -473,6 +706,31 @@ func (in *MondooAuditConfigStatus) DeepCopy() *MondooAuditConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MondooCredsSource) DeepCopyInto(out *MondooCredsSource) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultCredsSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSI != nil {
+		in, out := &in.CSI, &out.CSI
+		*out = new(CSICredsSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MondooCredsSource.
+func (in *MondooCredsSource) DeepCopy() *MondooCredsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MondooCredsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MondooOperatorConfig) DeepCopyInto(out *MondooOperatorConfig) {
 	*out = *in
@@ -573,6 +831,26 @@ func (in *MondooOperatorConfigSpec) DeepCopyInto(out *MondooOperatorConfigSpec)
 		*out = new(string)
 		**out = **in
 	}
+	if in.HttpProxySecretRef != nil {
+		in, out := &in.HttpProxySecretRef, &out.HttpProxySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HttpsProxySecretRef != nil {
+		in, out := &in.HttpsProxySecretRef, &out.HttpsProxySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerProxySecretRef != nil {
+		in, out := &in.ContainerProxySecretRef, &out.ContainerProxySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyCredentialsSecretRef != nil {
+		in, out := &in.ProxyCredentialsSecretRef, &out.ProxyCredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]v1.LocalObjectReference, len(*in))
@@ -590,6 +868,16 @@ func (in *MondooOperatorConfigSpec) DeepCopyInto(out *MondooOperatorConfigSpec)
 			(*out)[key] = val
 		}
 	}
+	if in.CABundleSecretRef != nil {
+		in, out := &in.CABundleSecretRef, &out.CABundleSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ClientCertificateSecretRef != nil {
+		in, out := &in.ClientCertificateSecretRef, &out.ClientCertificateSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MondooOperatorConfigSpec.
@@ -635,6 +923,11 @@ func (in *Nodes) DeepCopyInto(out *Nodes) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MondooCredsSecretRef != nil {
+		in, out := &in.MondooCredsSecretRef, &out.MondooCredsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Nodes.
@@ -657,6 +950,12 @@ func (in *ResourceWatcherSpec) DeepCopyInto(out *ResourceWatcherSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.ResyncInterval = in.ResyncInterval
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceWatcherSpec.
@@ -705,6 +1004,21 @@ func (in *ScanCacheConfig) DeepCopy() *ScanCacheConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanOptOutStatus) DeepCopyInto(out *ScanOptOutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanOptOutStatus.
+func (in *ScanOptOutStatus) DeepCopy() *ScanOptOutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanOptOutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Scanner) DeepCopyInto(out *Scanner) {
 	*out = *in
@@ -756,6 +1070,46 @@ func (in *ServiceAccountAuth) DeepCopy() *ServiceAccountAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpaceRoutingRule) DeepCopyInto(out *SpaceRoutingRule) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceRoutingRule.
+func (in *SpaceRoutingRule) DeepCopy() *SpaceRoutingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SpaceRoutingRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpaceRoutingStatus) DeepCopyInto(out *SpaceRoutingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceRoutingStatus.
+func (in *SpaceRoutingStatus) DeepCopy() *SpaceRoutingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SpaceRoutingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultAuthConfig) DeepCopyInto(out *VaultAuthConfig) {
 	*out = *in
@@ -781,6 +1135,26 @@ func (in *VaultAuthConfig) DeepCopy() *VaultAuthConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultCredsSource) DeepCopyInto(out *VaultCredsSource) {
+	*out = *in
+	if in.CACertSecretRef != nil {
+		in, out := &in.CACertSecretRef, &out.CACertSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultCredsSource.
+func (in *VaultCredsSource) DeepCopy() *VaultCredsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultCredsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadIdentityConfig) DeepCopyInto(out *WorkloadIdentityConfig) {
 	*out = *in
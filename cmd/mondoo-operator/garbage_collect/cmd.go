@@ -4,10 +4,13 @@
 package garbage_collect
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -31,6 +34,9 @@ func init() {
 	filterManagedBy := Cmd.Flags().String("filter-managed-by", "", "Cleanup assets with matching ManagedBy field.")
 	filterOlderThan := Cmd.Flags().String("filter-older-than", "", "Cleanup assets which have not been updated in over the time provided (eg 12m or 48h or anything time.ParseDuration() accepts).")
 	scopeMrnOverride := Cmd.Flags().String("scope-mrn", "", "Override the scope MRN (space or org) for garbage collection.")
+	dryRun := Cmd.Flags().Bool("dry-run", false, "List the assets matching the filters without deleting them.")
+	confirmThreshold := Cmd.Flags().Int64("confirm-threshold", 0, "Ask for confirmation before deleting more than this number of assets (0 deletes without listing the assets first).")
+	yes := Cmd.Flags().Bool("yes", false, "Confirm deleting more assets than --confirm-threshold without asking.")
 	Cmd.Flags().String("space-mrn", "", "Deprecated: use --scope-mrn instead.")
 	_ = Cmd.Flags().MarkDeprecated("space-mrn", "use --scope-mrn instead")
 	Cmd.RunE = func(cmd *cobra.Command, args []string) error {
@@ -43,6 +49,9 @@ func init() {
 		if *timeout <= 0 {
 			return fmt.Errorf("--timeout must be greater than 0")
 		}
+		if *confirmThreshold < 0 {
+			return fmt.Errorf("--confirm-threshold must not be negative")
+		}
 
 		// Read the service account credentials from the config file
 		configData, err := os.ReadFile(*configPath)
@@ -57,15 +66,17 @@ func init() {
 			return err
 		}
 
-		token, err := mondoo.GenerateTokenFromServiceAccount(*serviceAccount, logger)
+		// The token source renews the token when it expires, since the confirmation can take longer than
+		// a token is valid
+		tokenSource, err := mondoo.NewTokenSource(*serviceAccount)
 		if err != nil {
-			logger.Error(err, "failed to generate token from service account")
+			logger.Error(err, "failed to load the private key of the service account")
 			return err
 		}
 
 		client, err := mondooclient.NewClient(mondooclient.MondooClientOptions{
 			ApiEndpoint: serviceAccount.ApiEndpoint,
-			Token:       token,
+			TokenSource: tokenSource,
 			HttpTimeout: ptr.To(time.Duration(*timeout) * time.Minute),
 		})
 		if err != nil {
@@ -92,11 +103,32 @@ func init() {
 		if scopeMrn == "" {
 			scopeMrn = serviceAccount.SpaceMrn
 		}
-		return GarbageCollectCmd(ctx, client, scopeMrn, *filterPlatformRuntime, *filterOlderThan, *filterManagedBy, logger)
+		opts := GarbageCollectOptions{
+			DryRun:           *dryRun,
+			ConfirmThreshold: *confirmThreshold,
+			Out:              cmd.OutOrStdout(),
+		}
+		if !*yes {
+			opts.Confirm = func(count int64) bool { return confirmFromStdin(opts.Out, count) }
+		}
+		return GarbageCollectCmd(ctx, client, scopeMrn, *filterPlatformRuntime, *filterOlderThan, *filterManagedBy, opts, logger)
 	}
 }
 
-func GarbageCollectCmd(ctx context.Context, client mondooclient.MondooClient, scopeMrn, platformRuntime, olderThan, managedBy string, logger logr.Logger) error {
+// GarbageCollectOptions configures how GarbageCollectCmd previews the assets it deletes.
+type GarbageCollectOptions struct {
+	// DryRun prints the matching assets without deleting them.
+	DryRun bool
+	// ConfirmThreshold is the number of matching assets that are deleted without confirmation. 0 deletes
+	// the assets without listing them first.
+	ConfirmThreshold int64
+	// Confirm asks whether to delete the given number of assets. If nil, they are deleted.
+	Confirm func(count int64) bool
+	// Out is where the matching assets are printed to.
+	Out io.Writer
+}
+
+func GarbageCollectCmd(ctx context.Context, client mondooclient.MondooClient, scopeMrn, platformRuntime, olderThan, managedBy string, opts GarbageCollectOptions, logger logr.Logger) error {
 	req := &mondooclient.GarbageCollectAssetsRequest{
 		ScopeMrn:  scopeMrn,
 		ManagedBy: managedBy,
@@ -125,6 +157,27 @@ func GarbageCollectCmd(ctx context.Context, client mondooclient.MondooClient, sc
 		}
 	}
 
+	if opts.DryRun || opts.ConfirmThreshold > 0 {
+		preview, err := client.PreviewGarbageCollectAssets(ctx, req)
+		if err != nil {
+			logger.Error(err, "error while listing the assets to garbage collect")
+			return err
+		}
+		count := mondoo.GarbageCollectAssetCount(preview)
+		printAssets(opts.Out, preview, count)
+
+		switch {
+		case opts.DryRun:
+			logger.Info("Dry run, not deleting any assets")
+			return nil
+		case count == 0:
+			logger.Info("No assets to garbage collect")
+			return nil
+		case count > opts.ConfirmThreshold && opts.Confirm != nil && !opts.Confirm(count):
+			return fmt.Errorf("garbage collection of %d assets was not confirmed", count)
+		}
+	}
+
 	if err := client.GarbageCollectAssets(ctx, req); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			logger.Error(err, "failed to receive a response before timeout was exceeded")
@@ -138,6 +191,32 @@ func GarbageCollectCmd(ctx context.Context, client mondooclient.MondooClient, sc
 	return nil
 }
 
+func printAssets(out io.Writer, preview *mondooclient.PreviewGarbageCollectAssetsResponse, count int64) {
+	if out == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "%d assets match the garbage collection filters\n", count)
+	for _, asset := range preview.Assets {
+		_, _ = fmt.Fprintf(out, "  %s\t%s\t%s\n", asset.Name, asset.Mrn, asset.LastUpdated)
+	}
+	if listed := int64(len(preview.Assets)); listed < count {
+		_, _ = fmt.Fprintf(out, "  ... and %d more\n", count-listed)
+	}
+}
+
+// confirmFromStdin asks on stdin whether to delete the assets. Without a terminal there is nobody to ask,
+// so the deletion is refused.
+func confirmFromStdin(out io.Writer, count int64) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		_, _ = fmt.Fprintf(out, "Refusing to delete %d assets, more than --confirm-threshold. Pass --yes to confirm.\n", count)
+		return false
+	}
+	_, _ = fmt.Fprintf(out, "Delete %d assets? [y/N] ", count)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func buildOlderThanTimestamp(olderThanString string) (string, error) {
 	duration, err := time.ParseDuration(olderThanString)
 	if err != nil {
@@ -0,0 +1,87 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package garbage_collect
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient/fakeserver"
+)
+
+func testGarbageCollectClient(t *testing.T) (mondooclient.MondooClient, func() int64) {
+	server := fakeserver.FakeServerWithAssets(
+		mondooclient.GarbageCollectAsset{Mrn: "//assets.api.mondoo.app/spaces/test/assets/1", Name: "deployment-a"},
+		mondooclient.GarbageCollectAsset{Mrn: "//assets.api.mondoo.app/spaces/test/assets/2", Name: "deployment-b"},
+	)
+	t.Cleanup(server.Close)
+	client, err := mondooclient.NewClient(mondooclient.MondooClientOptions{ApiEndpoint: server.URL, Token: "token"})
+	require.NoError(t, err)
+
+	remaining := func() int64 {
+		preview, err := client.PreviewGarbageCollectAssets(context.Background(), &mondooclient.GarbageCollectAssetsRequest{})
+		require.NoError(t, err)
+		return preview.TotalCount
+	}
+	return client, remaining
+}
+
+func TestGarbageCollectCmd_DryRun(t *testing.T) {
+	client, remaining := testGarbageCollectClient(t)
+	out := &bytes.Buffer{}
+
+	err := GarbageCollectCmd(context.Background(), client, "//captain.api.mondoo.app/spaces/test", "", "", "mondoo-operator",
+		GarbageCollectOptions{DryRun: true, Out: out}, logr.Discard())
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "2 assets match")
+	assert.Contains(t, out.String(), "deployment-a")
+	assert.Contains(t, out.String(), "deployment-b")
+	assert.Equal(t, int64(2), remaining())
+}
+
+func TestGarbageCollectCmd_ConfirmThreshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		threshold     int64
+		confirm       bool
+		wantErr       bool
+		wantRemaining int64
+	}{
+		{name: "below threshold", threshold: 2, wantRemaining: 0},
+		{name: "confirmed", threshold: 1, confirm: true, wantRemaining: 0},
+		{name: "not confirmed", threshold: 1, wantErr: true, wantRemaining: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, remaining := testGarbageCollectClient(t)
+			asked := false
+			opts := GarbageCollectOptions{
+				ConfirmThreshold: test.threshold,
+				Confirm: func(count int64) bool {
+					asked = true
+					assert.Equal(t, int64(2), count)
+					return test.confirm
+				},
+				Out: &bytes.Buffer{},
+			}
+
+			err := GarbageCollectCmd(context.Background(), client, "//captain.api.mondoo.app/spaces/test", "", "", "mondoo-operator",
+				opts, logr.Discard())
+			if test.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.threshold < 2, asked)
+			assert.Equal(t, test.wantRemaining, remaining())
+		})
+	}
+}
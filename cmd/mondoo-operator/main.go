@@ -9,6 +9,7 @@ import (
 	"go.mondoo.com/mondoo-operator/cmd/mondoo-operator/garbage_collect"
 	"go.mondoo.com/mondoo-operator/cmd/mondoo-operator/operator"
 	resourcewatcher "go.mondoo.com/mondoo-operator/cmd/mondoo-operator/resource_watcher"
+	vaultcredentials "go.mondoo.com/mondoo-operator/cmd/mondoo-operator/vault_credentials"
 	"go.mondoo.com/mondoo-operator/cmd/mondoo-operator/version"
 )
 
@@ -19,7 +20,7 @@ var rootCmd = &cobra.Command{
 }
 
 func main() {
-	rootCmd.AddCommand(operator.Cmd, version.Cmd, garbage_collect.Cmd, resourcewatcher.Cmd, cleanup.Cmd)
+	rootCmd.AddCommand(operator.Cmd, version.Cmd, garbage_collect.Cmd, resourcewatcher.Cmd, cleanup.Cmd, vaultcredentials.Cmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
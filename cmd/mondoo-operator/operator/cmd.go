@@ -34,6 +34,7 @@ import (
 
 	k8sv1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/controllers"
+	"go.mondoo.com/mondoo-operator/controllers/container_image"
 	"go.mondoo.com/mondoo-operator/controllers/integration"
 	"go.mondoo.com/mondoo-operator/controllers/metrics"
 	"go.mondoo.com/mondoo-operator/controllers/status"
@@ -140,10 +141,18 @@ func init() {
 			ContainerImageResolver: containerImageResolver,
 			StatusReporter:         status.NewStatusReporter(mgr.GetClient(), controllers.MondooClientBuilder, v, containerImageResolver),
 			RunningOnOpenShift:     isOpenShift,
+			Recorder:               mgr.GetEventRecorder("mondoo-operator"),
 		}).SetupWithManager(mgr, mondooOperatorConfigExists); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "MondooAuditConfig")
 			return err
 		}
+		if err = (&container_image.NewImagesReconciler{
+			Client:                 mgr.GetClient(),
+			ContainerImageResolver: containerImageResolver,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ContainerImageNewImages")
+			return err
+		}
 		if mondooOperatorConfigExists {
 			if err = (&controllers.MondooOperatorConfigReconciler{
 				Client: mgr.GetClient(),
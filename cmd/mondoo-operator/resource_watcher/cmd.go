@@ -5,19 +5,25 @@ package resource_watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"go.mondoo.com/mondoo-operator/controllers/resource_watcher"
@@ -27,6 +33,9 @@ import (
 
 var scheme = runtime.NewScheme()
 
+// credentialsPollInterval is how often the credentials files are checked for changes.
+const credentialsPollInterval = 30 * time.Second
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 }
@@ -53,11 +62,27 @@ func init() {
 	minimumScanInterval := Cmd.Flags().Duration("minimum-scan-interval", 2*time.Minute, "Minimum time between scans (rate limit).")
 	watchAllResources := Cmd.Flags().Bool("watch-all-resources", false, "Watch all resource types including ephemeral ones (Pods, Jobs). Default is to only watch high-priority resources (Deployments, DaemonSets, StatefulSets, ReplicaSets).")
 	resourceTypes := Cmd.Flags().StringSlice("resource-types", nil, "Resource types to watch (comma-separated). Overrides --watch-all-resources if specified.")
+	labelSelector := Cmd.Flags().String("label-selector", "", "Only watch resources whose labels match this selector (e.g., \"app=web,tier!=ci\"). Empty means all resources.")
+	resolveOwners := Cmd.Flags().Bool("resolve-owners", false, "Scan the top-level controller of a changed resource (e.g. the Deployment of a Pod) instead of the resource itself.")
+	includeStandalonePods := Cmd.Flags().Bool("include-standalone-pods", false, "Still scan Pods without a controller when --resolve-owners is set.")
+	initialScan := Cmd.Flags().Bool("initial-scan", false, "Scan all watched resources once the informers have synced, instead of only scanning changes.")
+	resyncInterval := Cmd.Flags().Duration("resync-interval", 0, "How often to rescan all watched resources. Set to 0 to disable resyncs.")
+	syncBatchSize := Cmd.Flags().Int("sync-batch-size", 100, "Maximum number of resources an initial scan or resync queues at once. The next batch is queued once the previous one was flushed.")
+	purgeDeletedAssets := Cmd.Flags().Bool("purge-deleted-assets", false, "Delete the assets of deleted resources in Mondoo Platform instead of waiting for garbage collection. Requires --cluster-uid.")
 	apiProxy := Cmd.Flags().String("api-proxy", "", "HTTP proxy to use for API requests.")
+	clientCertificateDir := Cmd.Flags().String("client-certificate-dir", "", "Directory with the client certificate (tls.crt and tls.key) to present to the Mondoo API.")
 	timeout := Cmd.Flags().Duration("timeout", 25*time.Minute, "Timeout for scan operations.")
 	annotations := Cmd.Flags().StringToString("annotation", nil, "Annotations to add to scanned assets (can specify multiple, e.g., --annotation env=prod --annotation team=platform).")
+	annotationsFromLabels := Cmd.Flags().StringToString("annotation-from-label", nil, "Annotations to add to scanned assets with values taken from labels, as metadata.labels['<label>'] for a label of the resource or namespace.labels['<label>'] for a label of its namespace (e.g., --annotation-from-label team=metadata.labels['app.kubernetes.io/team']).")
 	clusterUID := Cmd.Flags().String("cluster-uid", "", "The unique identifier of the cluster for asset labeling.")
 	integrationMRN := Cmd.Flags().String("integration-mrn", "", "The integration MRN for asset labeling.")
+	clusterName := Cmd.Flags().String("cluster-name", "", "The name of the external cluster that is watched. The cluster is selected with the KUBECONFIG environment variable.")
+	credentialsFiles := Cmd.Flags().StringSlice("credentials-files", nil, "Files containing the credentials for the watched cluster (comma-separated). The watcher exits when one of them changes, so it is restarted with the new credentials.")
+	metricsAddr := Cmd.Flags().String("metrics-bind-address", ":8080", "The address the metrics endpoint binds to. Set to \"0\" to disable serving metrics.")
+	probeAddr := Cmd.Flags().String("health-probe-bind-address", ":8081", "The address the /healthz and /readyz endpoints bind to. Set to \"0\" to disable serving probes.")
+	maxConsecutiveScanFailures := Cmd.Flags().Int("max-consecutive-scan-failures", 5, "Number of consecutive failed scans after which the watcher reports itself unhealthy. Set to 0 to disable.")
+	stallTimeout := Cmd.Flags().Duration("stall-timeout", 0, "How long resources may wait in the debounce queue before the watcher reports itself unhealthy. Defaults to debounce interval + minimum scan interval + scan timeout + 5m.")
+	terminationMessagePath := Cmd.Flags().String("termination-message-path", "/dev/termination-log", "File to write the failing health check to when the watcher shuts down unhealthy.")
 
 	Cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		log.SetLogger(logger.NewLogger())
@@ -113,21 +138,45 @@ func init() {
 			}
 		}
 
+		// Parse label selector
+		var selector labels.Selector
+		if *labelSelector != "" {
+			var err error
+			selector, err = labels.Parse(*labelSelector)
+			if err != nil {
+				return fmt.Errorf("invalid label selector: %w", err)
+			}
+		}
+
 		// Validate annotations
 		if err := annot.Validate(*annotations); err != nil {
 			return fmt.Errorf("invalid annotations: %w", err)
 		}
+		labelRules, err := annot.ParseLabelRules(*annotationsFromLabels)
+		if err != nil {
+			return fmt.Errorf("invalid annotations from labels: %w", err)
+		}
 
 		logger.Info("Starting resource watcher",
 			"config", *configPath,
+			"clusterName", *clusterName,
 			"namespaces", namespacesList,
 			"namespacesExclude", namespacesExcludeList,
+			"labelSelector", *labelSelector,
+			"resolveOwners", *resolveOwners,
+			"purgeDeletedAssets", *purgeDeletedAssets,
+			"initialScan", *initialScan,
+			"resyncInterval", *resyncInterval,
 			"debounceInterval", *debounceInterval,
 			"minimumScanInterval", *minimumScanInterval,
 			"watchAllResources", *watchAllResources,
 			"resourceTypes", resourceTypesList,
 			"timeout", *timeout,
-			"annotations", *annotations)
+			"annotations", *annotations,
+			"annotationsFromLabels", *annotationsFromLabels,
+			"metricsBindAddress", *metricsAddr,
+			"healthProbeBindAddress", *probeAddr,
+			"maxConsecutiveScanFailures", *maxConsecutiveScanFailures)
 
 		// Create context with signal handling
 		ctx, cancel := context.WithCancel(context.Background())
@@ -148,20 +197,8 @@ func init() {
 			return fmt.Errorf("failed to get Kubernetes config: %w", err)
 		}
 
-		// Create cache
-		cacheOpts := cache.Options{
-			Scheme: scheme,
-		}
-
-		// If specific namespaces are provided, configure cache to only watch those
-		if len(namespacesList) > 0 {
-			byNamespace := make(map[string]cache.Config)
-			for _, ns := range namespacesList {
-				byNamespace[ns] = cache.Config{}
-			}
-			cacheOpts.DefaultNamespaces = byNamespace
-		}
-
+		// Create cache scoped to the watched namespaces
+		cacheOpts := resource_watcher.CacheOptions(scheme, namespacesList, namespacesExcludeList)
 		c, err := cache.New(restConfig, cacheOpts)
 		if err != nil {
 			return fmt.Errorf("failed to create cache: %w", err)
@@ -173,25 +210,99 @@ func init() {
 			APIProxy:          *apiProxy,
 			Timeout:           *timeout,
 			Annotations:       *annotations,
+			LabelRules:        labelRules,
+			Reader:            c,
 			Namespaces:        namespacesList,
 			NamespacesExclude: namespacesExcludeList,
 			ClusterUID:        *clusterUID,
 			IntegrationMRN:    *integrationMRN,
+			ClusterName:       *clusterName,
 		})
 
 		// Create debouncer with rate limiting
 		debouncer := resource_watcher.NewDebouncer(*debounceInterval, *minimumScanInterval, scanner.ScanResourcesFunc())
+		if err := resource_watcher.RegisterQueueDepthMetric(debouncer); err != nil {
+			return fmt.Errorf("failed to register queue depth metric: %w", err)
+		}
+
+		// Deleted resources are batched like changes, but purged without waiting for the scan rate limit
+		var purger *resource_watcher.Purger
+		if *purgeDeletedAssets {
+			if *clusterUID == "" {
+				logger.Info("No cluster UID provided, deleted resources are left to garbage collection")
+			} else {
+				// The platform IDs are based on the UIDs of the namespaces, which are read without a cache
+				kubeClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+				if err != nil {
+					return fmt.Errorf("failed to create Kubernetes client: %w", err)
+				}
+				purger = resource_watcher.NewPurger(resource_watcher.PurgerConfig{
+					ConfigPath:           *configPath,
+					APIProxy:             *apiProxy,
+					ClientCertificateDir: *clientCertificateDir,
+					ClusterUID:           *clusterUID,
+					KubeClient:           kubeClient,
+					ClusterName:          *clusterName,
+					Interval:             *debounceInterval,
+				})
+			}
+		}
 
 		// Create watcher
 		watcher := resource_watcher.NewResourceWatcher(c, debouncer, resource_watcher.WatcherConfig{
-			Namespaces:        namespacesList,
-			NamespacesExclude: namespacesExcludeList,
-			ResourceTypes:     resourceTypesList,
-			WatchAllResources: *watchAllResources,
+			Namespaces:            namespacesList,
+			NamespacesExclude:     namespacesExcludeList,
+			ResourceTypes:         resourceTypesList,
+			WatchAllResources:     *watchAllResources,
+			LabelSelector:         selector,
+			ResolveOwners:         *resolveOwners,
+			IncludeStandalonePods: *includeStandalonePods,
+			Purger:                purger,
+			InitialScan:           *initialScan,
+			ResyncInterval:        *resyncInterval,
+			SyncBatchSize:         *syncBatchSize,
 		})
 
+		// Liveness fails after repeated scan failures or when the debounce queue stops draining
+		if *stallTimeout == 0 {
+			*stallTimeout = *debounceInterval + *minimumScanInterval + *timeout + 5*time.Minute
+		}
+		health := resource_watcher.NewHealthChecker(watcher, debouncer, *maxConsecutiveScanFailures, *stallTimeout)
+		defer func() {
+			// Surface the failing check in the Pod's termination message so the operator can report it
+			if err := health.Healthy(); err != nil {
+				if writeErr := os.WriteFile(*terminationMessagePath, []byte(err.Error()), 0o644); writeErr != nil { //nolint:gosec
+					logger.Error(writeErr, "Failed to write termination message")
+				}
+			}
+		}()
+
 		// Start components
-		errChan := make(chan error, 3)
+		errChan := make(chan error, 5)
+
+		// Start metrics server
+		if *metricsAddr != "0" && *metricsAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", resource_watcher.MetricsHandler())
+			serveHTTP(ctx, logger, "metrics", *metricsAddr, mux, errChan)
+		}
+
+		// Start health probe server
+		if *probeAddr != "0" && *probeAddr != "" {
+			healthzHandler := &healthz.Handler{Checks: map[string]healthz.Checker{
+				"scans":     health.ScansHealthy,
+				"debouncer": health.DebouncerHealthy,
+			}}
+			readyzHandler := &healthz.Handler{Checks: map[string]healthz.Checker{
+				"informers": health.InformersSynced,
+			}}
+			mux := http.NewServeMux()
+			mux.Handle("/healthz", http.StripPrefix("/healthz", healthzHandler))
+			mux.Handle("/healthz/", http.StripPrefix("/healthz", healthzHandler))
+			mux.Handle("/readyz", http.StripPrefix("/readyz", readyzHandler))
+			mux.Handle("/readyz/", http.StripPrefix("/readyz", readyzHandler))
+			serveHTTP(ctx, logger, "health probe", *probeAddr, mux, errChan)
+		}
 
 		// Start cache
 		go func() {
@@ -215,6 +326,24 @@ func init() {
 			}
 		}()
 
+		// Start purger
+		if purger != nil {
+			go func() {
+				if err := purger.Start(ctx); err != nil {
+					errChan <- fmt.Errorf("purger failed: %w", err)
+				}
+			}()
+		}
+
+		// Restart when the credentials for the watched cluster are rotated
+		if len(*credentialsFiles) > 0 {
+			go func() {
+				if err := resource_watcher.WatchCredentialsFiles(ctx, *credentialsFiles, credentialsPollInterval); err != nil {
+					errChan <- err
+				}
+			}()
+		}
+
 		// Start watcher
 		go func() {
 			if err := watcher.Start(ctx); err != nil {
@@ -236,3 +365,27 @@ func init() {
 		return nil
 	}
 }
+
+// serveHTTP serves handler on addr until ctx is cancelled. Errors other than the server being
+// closed are sent to errChan.
+func serveHTTP(ctx context.Context, logger logr.Logger, name, addr string, handler http.Handler, errChan chan<- error) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("Starting "+name+" server", "address", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- fmt.Errorf("%s server failed: %w", name, err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Failed to shut down "+name+" server")
+		}
+	}()
+}
@@ -0,0 +1,84 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault_credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+	"go.mondoo.com/mondoo-operator/pkg/utils/logger"
+)
+
+// Cmd is the vault-credentials subcommand
+var Cmd = &cobra.Command{
+	Use:   "vault-credentials",
+	Short: "Writes the Mondoo service account stored in Vault to a file",
+	Long: `This command logs in to Vault with the Kubernetes auth method, reads the Mondoo service account
+from a KV v2 secret and writes it to a file.
+
+It runs as an init container of the scan pods when the MondooAuditConfig reads its credentials from Vault.`,
+}
+
+func init() {
+	vaultAddr := Cmd.Flags().String("vault-addr", "", "The address of the Vault server (required)")
+	authPath := Cmd.Flags().String("auth-path", "auth/kubernetes", "The path of the Kubernetes auth method")
+	authRole := Cmd.Flags().String("auth-role", "", "The Vault role to log in with (required)")
+	kvMount := Cmd.Flags().String("kv-mount", "secret", "The mount path of the KV v2 secrets engine")
+	path := Cmd.Flags().String("path", "", "The path of the secret holding the service account (required)")
+	key := Cmd.Flags().String("key", "config", "The key of the secret holding the service account")
+	tokenFile := Cmd.Flags().String("token-file", k8s.DefaultServiceAccountTokenPath, "The service account token used to log in to Vault")
+	caCert := Cmd.Flags().String("ca-cert", "", "The CA certificate used to verify Vault's certificate")
+	output := Cmd.Flags().String("output", "", "The file the service account is written to (required)")
+	timeout := Cmd.Flags().Duration("timeout", time.Minute, "Timeout for reading the service account from Vault")
+
+	Cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		log.SetLogger(logger.NewLogger())
+		lgr := log.Log.WithName("vault-credentials")
+
+		if *vaultAddr == "" || *authRole == "" || *path == "" || *output == "" {
+			return fmt.Errorf("--vault-addr, --auth-role, --path and --output are required")
+		}
+
+		saToken, err := os.ReadFile(*tokenFile) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		var vaultCACert []byte
+		if *caCert != "" {
+			if vaultCACert, err = os.ReadFile(*caCert); err != nil { //nolint:gosec
+				return fmt.Errorf("failed to read Vault CA cert: %w", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		source := v1alpha2.VaultCredsSource{
+			VaultAddr: *vaultAddr,
+			AuthPath:  *authPath,
+			AuthRole:  *authRole,
+			KVMount:   *kvMount,
+			Path:      *path,
+			Key:       *key,
+		}
+		data, version, err := k8s.ReadVaultCredentials(ctx, source, string(saToken), vaultCACert)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(*output, data[constants.MondooCredsSecretServiceAccountKey], 0o600); err != nil {
+			return fmt.Errorf("failed to write the service account: %w", err)
+		}
+		lgr.Info("Wrote Mondoo service account", "path", *kvMount+"/"+*path, "version", version, "output", *output)
+		return nil
+	}
+}
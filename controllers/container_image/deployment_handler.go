@@ -5,6 +5,7 @@ package container_image
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -71,7 +73,23 @@ func (n *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error)
 		return ctrl.Result{}, err
 	}
 
-	if err := n.syncCronJob(ctx, clusterUid); err != nil {
+	filtering, err := k8s.ResolveNamespaceFiltering(ctx, n.KubeClient, n.Mondoo.Spec.Filtering)
+	if errors.Is(err, k8s.ErrNoMatchingNamespaces) {
+		// Nothing to scan until a matching namespace is created
+		logger.Info("No namespaces match the namespace filtering, removing container image scanning")
+		return ctrl.Result{}, n.down(ctx)
+	} else if err != nil {
+		logger.Error(err, "Failed to resolve the namespace filtering")
+		return ctrl.Result{}, err
+	}
+
+	routing, err := k8s.ResolveSpaceRouting(ctx, n.KubeClient, *n.Mondoo, filtering)
+	if err != nil {
+		logger.Error(err, "Failed to resolve the space routing")
+		return ctrl.Result{}, err
+	}
+
+	if err := n.syncCronJob(ctx, clusterUid, routing); err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -80,7 +98,7 @@ func (n *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error)
 	return ctrl.Result{}, nil
 }
 
-func (n *DeploymentHandler) syncCronJob(ctx context.Context, clusterUid string) error {
+func (n *DeploymentHandler) syncCronJob(ctx context.Context, clusterUid string, routing k8s.SpaceRouting) error {
 	mondooClientImage, err := n.ContainerImageResolver.CnspecImage(
 		n.Mondoo.Spec.Scanner.Image.Name, n.Mondoo.Spec.Scanner.Image.Tag, n.Mondoo.Spec.Scanner.Image.Digest, n.MondooOperatorConfig.Spec.SkipContainerResolution)
 	if err != nil {
@@ -88,14 +106,16 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context, clusterUid string)
 		return err
 	}
 
-	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, n.KubeClient, *n.Mondoo)
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, n.ContainerImageResolver, *n.Mondoo, n.MondooOperatorConfig.Spec.SkipContainerResolution)
 	if err != nil {
-		logger.Error(err,
-			"failed to retrieve integration-mrn for MondooAuditConfig", "namespace", n.Mondoo.Namespace, "name", n.Mondoo.Name)
+		logger.Error(err, "Failed to resolve mondoo-operator container image")
 		return err
 	}
 
-	if err := n.syncConfigMap(ctx, clusterUid); err != nil {
+	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, n.KubeClient, *n.Mondoo)
+	if err != nil {
+		logger.Error(err,
+			"failed to retrieve integration-mrn for MondooAuditConfig", "namespace", n.Mondoo.Namespace, "name", n.Mondoo.Name)
 		return err
 	}
 
@@ -106,22 +126,41 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context, clusterUid string)
 		return err
 	}
 
-	desired := CronJob(mondooClientImage, integrationMrn, clusterUid, privateRegistrySecretName, n.Mondoo, *n.MondooOperatorConfig)
-	obj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
-	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
-		k8s.UpdateCronJobFields(obj, desired)
-		return nil
-	})
-	if err != nil {
-		return err
+	// Container images only run in namespaces, so there is nothing to scan in the default space if all
+	// namespaces are routed to other spaces
+	if routing.DefaultEmpty {
+		if err := n.downDefaultSpace(ctx); err != nil {
+			return err
+		}
+	} else {
+		m := *n.Mondoo.DeepCopy()
+		m.Spec.Filtering = routing.Default
+		if err := n.syncConfigMap(ctx, clusterUid, m, ConfigMapName(n.Mondoo.Name), nil); err != nil {
+			return err
+		}
+		desired := CronJob(mondooClientImage, integrationMrn, clusterUid, privateRegistrySecretName, n.Mondoo, *n.MondooOperatorConfig)
+		k8s.AddMondooCredsSourceToSpec(&desired.Spec.JobTemplate.Spec.Template.Spec, *n.Mondoo, k8s.ContainersScan, credsInitImage)
+		if err := n.syncCronJobObject(ctx, desired); err != nil {
+			return err
+		}
 	}
 
-	// When a CronJob is updated, remove completed Jobs so they don't linger with stale config
-	if op == controllerutil.OperationResultUpdated {
-		if err := k8s.DeleteCompletedJobs(ctx, n.KubeClient, n.Mondoo.Namespace, CronJobLabels(*n.Mondoo), logger); err != nil {
-			logger.Error(err, "Failed to clean up completed Jobs after CronJob update")
+	// Every space of spec.spaceRouting gets its own inventory and CronJob
+	activeSpaces := map[string]bool{}
+	for _, route := range routing.Routes {
+		m := k8s.SpaceAuditConfig(*n.Mondoo, route)
+		ls := SpaceCronJobLabels(*n.Mondoo, route.SpaceID)
+		if err := n.syncConfigMap(ctx, clusterUid, m, SpaceConfigMapName(n.Mondoo.Name, route.SpaceID), ls); err != nil {
+			return err
+		}
+		desired := SpaceCronJob(mondooClientImage, integrationMrn, clusterUid, privateRegistrySecretName, n.Mondoo, route, *n.MondooOperatorConfig)
+		if err := n.syncCronJobObject(ctx, desired); err != nil {
 			return err
 		}
+		activeSpaces[route.SpaceID] = true
+	}
+	if err := n.cleanupSpaceCronJobs(ctx, activeSpaces); err != nil {
+		return err
 	}
 
 	cronJobs, err := n.getCronJobsForAuditConfig(ctx)
@@ -153,7 +192,30 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context, clusterUid string)
 	return nil
 }
 
-func (n *DeploymentHandler) syncConfigMap(ctx context.Context, clusterUid string) error {
+// syncCronJobObject creates or updates a container scan CronJob. Completed Jobs of an updated CronJob are
+// removed so they don't linger with stale config.
+func (n *DeploymentHandler) syncCronJobObject(ctx context.Context, desired *batchv1.CronJob) error {
+	obj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
+		k8s.UpdateCronJobFields(obj, desired)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if op == controllerutil.OperationResultUpdated {
+		if err := k8s.DeleteCompletedJobs(ctx, n.KubeClient, n.Mondoo.Namespace, desired.Spec.JobTemplate.Labels, logger); err != nil {
+			logger.Error(err, "Failed to clean up completed Jobs after CronJob update")
+			return err
+		}
+	}
+	return nil
+}
+
+// syncConfigMap syncs the inventory ConfigMap of a container scan CronJob. m is the MondooAuditConfig the
+// inventory is rendered from, with the namespace names the filtering and space routing resolved to.
+func (n *DeploymentHandler) syncConfigMap(ctx context.Context, clusterUid string, m v1alpha2.MondooAuditConfig, name string, ls map[string]string) error {
 	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, n.KubeClient, *n.Mondoo)
 	if err != nil {
 		logger.Error(err, "failed to retrieve IntegrationMRN")
@@ -174,11 +236,13 @@ func (n *DeploymentHandler) syncConfigMap(ctx context.Context, clusterUid string
 		}
 	}
 
-	desired, err := ConfigMap(integrationMrn, clusterUid, *n.Mondoo, *n.MondooOperatorConfig, platformIdsExclude, scanTime)
+	desired, err := ConfigMap(integrationMrn, clusterUid, m, *n.MondooOperatorConfig, platformIdsExclude, scanTime)
 	if err != nil {
 		logger.Error(err, "failed to generate desired ConfigMap with inventory")
 		return err
 	}
+	desired.Name = name
+	desired.Labels = ls
 
 	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 	if _, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
@@ -252,9 +316,22 @@ func (n *DeploymentHandler) performGarbageCollection(ctx context.Context, manage
 		},
 	}
 
-	if err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger); err != nil {
+	result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, k8s.ContainersScan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+	if err != nil {
 		return err
 	}
+	results := []*v1alpha2.GarbageCollectionStatus{result}
+
+	// Images of namespaces routed to other spaces are collected in these spaces
+	for _, spaceID := range k8s.RoutedSpaceIDs(*n.Mondoo) {
+		spaceConfig := k8s.SpaceAuditConfig(*n.Mondoo, k8s.SpaceRoute{SpaceID: spaceID})
+		result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, &spaceConfig, k8s.ContainersScan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, k8s.ContainersScan, results)
 
 	logger.Info("Successfully performed garbage collection of container image assets")
 	return nil
@@ -268,6 +345,10 @@ func (n *DeploymentHandler) down(ctx context.Context) error {
 		return err
 	}
 
+	if err := n.cleanupSpaceCronJobs(ctx, nil); err != nil {
+		return err
+	}
+
 	// Clean up WIF ServiceAccount if it exists
 	if err := n.cleanupWIFServiceAccount(ctx); err != nil {
 		return err
@@ -279,6 +360,55 @@ func (n *DeploymentHandler) down(ctx context.Context) error {
 	return nil
 }
 
+// downDefaultSpace deletes the container scan CronJob and ConfigMap of the default space.
+func (n *DeploymentHandler) downDefaultSpace(ctx context.Context) error {
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: CronJobName(n.Mondoo.Name), Namespace: n.Mondoo.Namespace}}
+	if err := k8s.DeleteIfExists(ctx, n.KubeClient, cronJob); err != nil {
+		logger.Error(err, "failed to clean up container scan CronJob", "namespace", cronJob.Namespace, "name", cronJob.Name)
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName(n.Mondoo.Name), Namespace: n.Mondoo.Namespace}}
+	if err := k8s.DeleteIfExists(ctx, n.KubeClient, configMap); err != nil {
+		logger.Error(err, "failed to clean up container scan ConfigMap", "namespace", configMap.Namespace, "name", configMap.Name)
+		return err
+	}
+	return nil
+}
+
+// cleanupSpaceCronJobs deletes the CronJobs and ConfigMaps of routed spaces that are not in activeSpaces.
+func (n *DeploymentHandler) cleanupSpaceCronJobs(ctx context.Context, activeSpaces map[string]bool) error {
+	cronJobs := &batchv1.CronJobList{}
+	hasSpace, err := labels.NewRequirement(k8s.SpaceIDLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	listOpts := &client.ListOptions{
+		Namespace:     n.Mondoo.Namespace,
+		LabelSelector: labels.SelectorFromSet(CronJobLabels(*n.Mondoo)).Add(*hasSpace),
+	}
+	if err := n.KubeClient.List(ctx, cronJobs, listOpts); err != nil {
+		logger.Error(err, "Failed to list space CronJobs", "namespace", n.Mondoo.Namespace)
+		return err
+	}
+
+	for i := range cronJobs.Items {
+		spaceID := cronJobs.Items[i].Labels[k8s.SpaceIDLabel]
+		if activeSpaces[spaceID] {
+			continue
+		}
+		logger.Info("Deleting container scan CronJob of a space that is no longer routed", "name", cronJobs.Items[i].Name, "spaceId", spaceID)
+		if err := k8s.DeleteIfExists(ctx, n.KubeClient, &cronJobs.Items[i]); err != nil {
+			return err
+		}
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: SpaceConfigMapName(n.Mondoo.Name, spaceID), Namespace: n.Mondoo.Namespace}}
+		if err := k8s.DeleteIfExists(ctx, n.KubeClient, configMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // syncWIFServiceAccount creates or updates a ServiceAccount with cloud-specific annotations for container registry WIF
 func (n *DeploymentHandler) syncWIFServiceAccount(ctx context.Context) error {
 	desired := WIFServiceAccount(n.Mondoo)
@@ -323,6 +453,10 @@ func (n *DeploymentHandler) cleanupStaleCronJobs(ctx context.Context) error {
 
 	expectedName := CronJobName(n.Mondoo.Name)
 	for i := range cronJobs.Items {
+		// cleanupSpaceCronJobs handles the CronJobs of routed spaces
+		if _, ok := cronJobs.Items[i].Labels[k8s.SpaceIDLabel]; ok {
+			continue
+		}
 		if cronJobs.Items[i].Name != expectedName {
 			logger.Info("Deleting stale container scan CronJob", "name", cronJobs.Items[i].Name)
 			if err := k8s.DeleteIfExists(ctx, n.KubeClient, &cronJobs.Items[i]); err != nil {
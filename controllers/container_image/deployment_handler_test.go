@@ -20,6 +20,7 @@ import (
 
 	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 	fakeMondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo/fake"
 	"go.mondoo.com/mondoo-operator/pkg/utils/test"
@@ -311,6 +312,46 @@ func (s *DeploymentHandlerSuite) TestReconcile_Update() {
 	s.Equal(expected.Spec, created.Spec)
 }
 
+func (s *DeploymentHandlerSuite) TestReconcile_SpaceRouting() {
+	s.fakeClientBuilder = s.fakeClientBuilder.WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-prod"}})
+	s.auditConfig.Spec.SpaceRouting = []mondoov1alpha2.SpaceRoutingRule{{SpaceID: "team-a", Namespaces: []string{"team-a-*"}}}
+	d := s.createDeploymentHandler()
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	image, err := s.containerImageResolver.CnspecImage(
+		s.auditConfig.Spec.Scanner.Image.Name, s.auditConfig.Spec.Scanner.Image.Tag, s.auditConfig.Spec.Scanner.Image.Digest, false)
+	s.NoError(err)
+	route := k8s.SpaceRoute{SpaceID: "team-a", Namespaces: []string{"team-a-prod"}}
+	expected := SpaceCronJob(image, "", test.KubeSystemNamespaceUid, "", &s.auditConfig, route, mondoov1alpha2.MondooOperatorConfig{})
+
+	created := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: SpaceCronJobName(s.auditConfig.Name, "team-a"), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(created), created))
+	s.Equal(expected.Spec, created.Spec)
+	s.Equal("team-a", created.Labels[k8s.SpaceIDLabel])
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: SpaceConfigMapName(s.auditConfig.Name, "team-a"), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(configMap), configMap))
+	s.Contains(configMap.Data["inventory"], "namespaces: team-a-prod")
+
+	defaultConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName(s.auditConfig.Name), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(defaultConfigMap), defaultConfigMap))
+	s.Contains(defaultConfigMap.Data["inventory"], "namespaces-exclude: team-a-prod")
+
+	// Disabling the scan removes the CronJobs of all spaces
+	d.Mondoo.Spec.Containers.Enable = false
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	cronJobs := &batchv1.CronJobList{}
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Empty(cronJobs.Items)
+}
+
 func (s *DeploymentHandlerSuite) TestReconcile_K8sContainerImageScanningStatus() {
 	d := s.createDeploymentHandler()
 	mondooAuditConfig := &s.auditConfig
@@ -0,0 +1,476 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package container_image
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var newImagesLogger = ctrl.Log.WithName("k8s-new-images-scanning")
+
+const defaultNewImagesBatchInterval = 2 * time.Minute
+
+// runningImage is a container image reference pinned by digest and the namespace of a Pod that runs it.
+type runningImage struct {
+	ref       string
+	namespace string
+}
+
+// imageTracker remembers the image digests that were already running or scanned for a MondooAuditConfig.
+type imageTracker struct {
+	seen     map[string]struct{}
+	lastScan time.Time
+}
+
+// NewImagesReconciler starts targeted container image scans for images that start running in the
+// cluster, so they don't have to wait for the next scheduled container scan.
+type NewImagesReconciler struct {
+	client.Client
+	ContainerImageResolver mondoo.ContainerImageResolver
+
+	// pods holds the Pods of each MondooAuditConfig. It is set up by SetupWithManager; without it the Pods
+	// are listed through the Client.
+	pods *podCaches
+
+	mu       sync.Mutex
+	trackers map[types.NamespacedName]*imageTracker
+}
+
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=create
+
+// Reconcile compares the images running in the cluster with the ones seen before and starts a scan
+// Job for the new ones. Scans are rate limited by the configured batch interval.
+func (r *NewImagesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	m := &v1alpha2.MondooAuditConfig{}
+	if err := r.Get(ctx, req.NamespacedName, m); err != nil {
+		if errors.IsNotFound(err) {
+			r.forget(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !m.DeletionTimestamp.IsZero() || !newImageScanningEnabled(m) {
+		r.forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	images, err := r.runningImages(ctx, m)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracker, ok := r.trackers[req.NamespacedName]
+	if !ok {
+		// Images that are already running are covered by the scheduled scan. This includes images rolled
+		// out while the operator was not running.
+		tracker = &imageTracker{seen: make(map[string]struct{}, len(images))}
+		for digest := range images {
+			tracker.seen[digest] = struct{}{}
+		}
+		if r.trackers == nil {
+			r.trackers = make(map[types.NamespacedName]*imageTracker)
+		}
+		r.trackers[req.NamespacedName] = tracker
+		newImagesLogger.Info("Started tracking running container images", "mondooAuditConfig", req.NamespacedName, "images", len(images))
+		return ctrl.Result{}, nil
+	}
+
+	// Only remember the images that still run, so the set doesn't grow with every image that ever ran.
+	// An image that starts running again later is scanned again.
+	seen := make(map[string]struct{}, len(images))
+	newImages := make(map[string]runningImage)
+	for digest, image := range images {
+		if _, ok := tracker.seen[digest]; ok {
+			seen[digest] = struct{}{}
+		} else {
+			newImages[digest] = image
+		}
+	}
+	tracker.seen = seen
+	if len(newImages) == 0 || m.Status.ScanningPaused {
+		return ctrl.Result{}, nil
+	}
+
+	if wait := newImagesBatchInterval(m) - time.Since(tracker.lastScan); wait > 0 {
+		newImagesLogger.V(1).Info("Waiting to batch new container images", "mondooAuditConfig", req.NamespacedName, "images", len(newImages), "wait", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	if err := r.startScan(ctx, m, newImages); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for digest := range newImages {
+		tracker.seen[digest] = struct{}{}
+	}
+	tracker.lastScan = time.Now()
+	return ctrl.Result{}, nil
+}
+
+// startScan creates the Jobs and their inventory ConfigMaps that scan the given images. Images of
+// namespaces that spec.spaceRouting routes to other spaces are scanned by a Job per space.
+func (r *NewImagesReconciler) startScan(ctx context.Context, m *v1alpha2.MondooAuditConfig, newImages map[string]runningImage) error {
+	cfg := &v1alpha2.MondooOperatorConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: v1alpha2.MondooOperatorConfigName}, cfg); err != nil {
+		if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			newImagesLogger.Error(err, "Failed to check for MondooOperatorConfig")
+			return err
+		}
+		cfg = &v1alpha2.MondooOperatorConfig{}
+	}
+
+	imageResolver := r.ContainerImageResolver
+	if len(cfg.Spec.RegistryMirrors) > 0 {
+		imageResolver = imageResolver.WithRegistryMirrors(cfg.Spec.RegistryMirrors)
+	} else if cfg.Spec.ImageRegistry != nil && *cfg.Spec.ImageRegistry != "" {
+		imageResolver = imageResolver.WithImageRegistry(*cfg.Spec.ImageRegistry)
+	}
+	if len(cfg.Spec.ImagePullSecrets) > 0 {
+		imageResolver = imageResolver.WithImagePullSecrets(cfg.Spec.ImagePullSecrets)
+	}
+
+	cnspecImage, err := imageResolver.CnspecImage(
+		m.Spec.Scanner.Image.Name, m.Spec.Scanner.Image.Tag, m.Spec.Scanner.Image.Digest, cfg.Spec.SkipContainerResolution)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to resolve mondoo-client container image")
+		return err
+	}
+
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, imageResolver, *m, cfg.Spec.SkipContainerResolution)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to resolve mondoo-operator container image")
+		return err
+	}
+
+	clusterUid, err := k8s.GetClusterUID(ctx, r.Client, newImagesLogger)
+	if err != nil {
+		return err
+	}
+
+	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, r.Client, *m)
+	if err != nil {
+		newImagesLogger.Error(err, "failed to retrieve integration-mrn for MondooAuditConfig", "namespace", m.Namespace, "name", m.Name)
+		return err
+	}
+
+	// Static registry credentials are merged into one Secret by the scheduled scan's DeploymentHandler
+	privateRegistrySecretName, err := k8s.ReconcilePrivateRegistriesSecret(ctx, r.Client, m)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to reconcile private registry secrets")
+		return err
+	}
+
+	routing, err := k8s.ResolveSpaceRouting(ctx, r.Client, *m, m.Spec.Filtering)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to resolve the space routing")
+		return err
+	}
+	routes := make(map[string]k8s.SpaceRoute, len(routing.Routes))
+	spaceOf := make(map[string]string)
+	for _, route := range routing.Routes {
+		routes[route.SpaceID] = route
+		for _, ns := range route.Namespaces {
+			spaceOf[ns] = route.SpaceID
+		}
+	}
+
+	// Images of the default space are grouped under an empty space ID
+	bySpace := make(map[string]map[string]runningImage)
+	for digest, image := range newImages {
+		spaceID := spaceOf[image.namespace]
+		if bySpace[spaceID] == nil {
+			bySpace[spaceID] = make(map[string]runningImage)
+		}
+		bySpace[spaceID][digest] = image
+	}
+
+	spaceIDs := make([]string, 0, len(bySpace))
+	for spaceID := range bySpace {
+		spaceIDs = append(spaceIDs, spaceID)
+	}
+	sort.Strings(spaceIDs)
+	for _, spaceID := range spaceIDs {
+		scan := m
+		if spaceID != "" {
+			routed := k8s.SpaceAuditConfig(*m, routes[spaceID])
+			scan = &routed
+		}
+		if err := r.startBatch(ctx, m, scan, *cfg, cnspecImage, credsInitImage, integrationMrn, clusterUid, privateRegistrySecretName, bySpace[spaceID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startBatch creates a Job and its inventory ConfigMap that scan a batch of images with the credentials of
+// scan. The Job is owned by m.
+func (r *NewImagesReconciler) startBatch(
+	ctx context.Context,
+	m, scan *v1alpha2.MondooAuditConfig,
+	cfg v1alpha2.MondooOperatorConfig,
+	cnspecImage, credsInitImage, integrationMrn, clusterUid, privateRegistrySecretName string,
+	newImages map[string]runningImage,
+) error {
+	digests := make([]string, 0, len(newImages))
+	for digest := range newImages {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	images := make([]string, 0, len(digests))
+	for _, digest := range digests {
+		images = append(images, newImages[digest].ref)
+	}
+
+	// Name the Job after the batch so a retried reconcile doesn't start a second scan
+	batchID := fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(digests, ","))))[:8]
+	name := NewImagesJobName(m.Name, batchID)
+
+	job := NewImagesJob(cnspecImage, integrationMrn, clusterUid, privateRegistrySecretName, name, scan, cfg)
+	k8s.AddMondooCredsSourceToSpec(&job.Spec.Template.Spec, *scan, k8s.ContainersScan, credsInitImage)
+	if err := controllerutil.SetControllerReference(m, job, r.Scheme()); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			newImagesLogger.Error(err, "Failed to create Job for new container images", "namespace", job.Namespace, "name", job.Name)
+			return err
+		}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return err
+		}
+	}
+
+	// The ConfigMap is owned by the Job so it is garbage collected together with it
+	configMap, err := NewImagesConfigMap(integrationMrn, clusterUid, name, *scan, cfg, images)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to generate inventory for new container images")
+		return err
+	}
+	if err := controllerutil.SetControllerReference(job, configMap, r.Scheme()); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, configMap); err != nil && !errors.IsAlreadyExists(err) {
+		newImagesLogger.Error(err, "Failed to create inventory ConfigMap for new container images", "namespace", configMap.Namespace, "name", configMap.Name)
+		return err
+	}
+
+	newImagesLogger.Info("Started scan of new container images", "job", name, "images", images)
+	return nil
+}
+
+// runningImages returns the images of all Pods the MondooAuditConfig scans, keyed by digest. Only images
+// that are pinned by a registry digest are returned, as other images cannot be pulled for scanning.
+func (r *NewImagesReconciler) runningImages(ctx context.Context, m *v1alpha2.MondooAuditConfig) (map[string]runningImage, error) {
+	var reader client.Reader = r.Client
+	if r.pods != nil {
+		var err error
+		if reader, err = r.pods.reader(ctx, m); err != nil {
+			newImagesLogger.Error(err, "Failed to get the Pod cache", "mondooAuditConfig", client.ObjectKeyFromObject(m))
+			return nil, err
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods); err != nil {
+		newImagesLogger.Error(err, "Failed to list Pods")
+		return nil, err
+	}
+
+	images := make(map[string]runningImage)
+	for _, pod := range pods.Items {
+		if k8s.ScanOptedOut(pod.Annotations) || !r.inNamespaceScope(ctx, m, pod.Namespace) {
+			continue
+		}
+		for _, ref := range podImageRefs(&pod) {
+			if !inRepositoryScope(m, ref) {
+				continue
+			}
+			_, digest, _ := strings.Cut(ref, "@")
+			images[digest] = runningImage{ref: ref, namespace: pod.Namespace}
+		}
+	}
+	return images, nil
+}
+
+// podImageRefs returns the digest references (repository@sha256:...) of the images the Pod runs.
+func podImageRefs(pod *corev1.Pod) []string {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	var refs []string
+	for _, s := range statuses {
+		// The container runtime may prefix the image ID, e.g. docker-pullable://nginx@sha256:...
+		ref := s.ImageID
+		if i := strings.Index(ref, "://"); i >= 0 {
+			ref = ref[i+3:]
+		}
+		// Images that were not pulled from a registry only have a local ID (sha256:...)
+		repo, digest, found := strings.Cut(ref, "@")
+		if !found || repo == "" || !strings.HasPrefix(digest, "sha256:") {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// inNamespaceScope applies spec.filtering.namespaces and the scan opt-out annotation of the Namespace to a namespace.
+func (r *NewImagesReconciler) inNamespaceScope(ctx context.Context, m *v1alpha2.MondooAuditConfig, namespace string) bool {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil && !errors.IsNotFound(err) {
+		newImagesLogger.Error(err, "Failed to get Namespace", "namespace", namespace)
+		return false
+	}
+	if k8s.ScanOptedOut(ns.Annotations) {
+		return false
+	}
+	inScope, err := k8s.NamespaceMatchesFiltering(m.Spec.Filtering.Namespaces, namespace, ns.Labels)
+	if err != nil {
+		newImagesLogger.Error(err, "Failed to apply the namespace filtering", "namespace", namespace)
+		return false
+	}
+	return inScope
+}
+
+// inRepositoryScope applies spec.containers.repositories to the repository of an image reference.
+func inRepositoryScope(m *v1alpha2.MondooAuditConfig, ref string) bool {
+	repo, _, _ := strings.Cut(ref, "@")
+	matches := func(patterns []string) bool {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, repo); ok || p == repo {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(m.Spec.Containers.Repositories.Include) > 0 {
+		return matches(m.Spec.Containers.Repositories.Include)
+	}
+	return !matches(m.Spec.Containers.Repositories.Exclude)
+}
+
+func newImageScanningEnabled(m *v1alpha2.MondooAuditConfig) bool {
+	return m.Spec.Containers.Enable && m.Spec.Containers.EventDriven.Enable
+}
+
+func newImagesBatchInterval(m *v1alpha2.MondooAuditConfig) time.Duration {
+	if d := m.Spec.Containers.EventDriven.BatchInterval.Duration; d > 0 {
+		return d
+	}
+	return defaultNewImagesBatchInterval
+}
+
+func (r *NewImagesReconciler) forget(key types.NamespacedName) {
+	if r.pods != nil {
+		r.pods.forget(key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.trackers, key)
+}
+
+// hasNewImages reports whether the Pod runs an image that has not been seen for the MondooAuditConfig yet.
+func (r *NewImagesReconciler) hasNewImages(key types.NamespacedName, pod *corev1.Pod) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tracker, ok := r.trackers[key]
+	if !ok {
+		return true
+	}
+	for _, ref := range podImageRefs(pod) {
+		_, digest, _ := strings.Cut(ref, "@")
+		if _, seen := tracker.seen[digest]; !seen {
+			return true
+		}
+	}
+	return false
+}
+
+// podRequestMapper enqueues the MondooAuditConfigs with event-driven image scanning for which the Pod runs an
+// image that has not been seen before.
+func (r *NewImagesReconciler) podRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	pod, ok := o.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	auditConfigs := &v1alpha2.MondooAuditConfigList{}
+	if err := r.List(ctx, auditConfigs); err != nil {
+		newImagesLogger.Error(err, "Failed to list MondooAuditConfigs")
+		return nil
+	}
+
+	if k8s.ScanOptedOut(pod.Annotations) {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, a := range auditConfigs.Items {
+		if !newImageScanningEnabled(&a) || !r.inNamespaceScope(ctx, &a, pod.Namespace) {
+			continue
+		}
+		key := client.ObjectKeyFromObject(&a)
+		if r.hasNewImages(key, pod) {
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager. Pods are not watched through the manager's cache,
+// the controller starts a Pod cache per MondooAuditConfig that is limited to its namespace filtering.
+func (r *NewImagesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.pods = newPodCaches(mgr.GetConfig(), mgr.GetHTTPClient(), mgr.GetScheme(), mgr.GetRESTMapper())
+	if err := mgr.Add(r.pods); err != nil {
+		return err
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("container-image-new-images").
+		For(&v1alpha2.MondooAuditConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.pods.watch = func(podCache cache.Cache) error {
+		return c.Watch(source.Kind(
+			podCache,
+			client.Object(&corev1.Pod{}),
+			handler.EnqueueRequestsFromMapFunc(r.podRequestMapper),
+			k8s.CreateUpdateEventsPredicate{}))
+	}
+	return nil
+}
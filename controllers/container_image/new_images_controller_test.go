@@ -0,0 +1,214 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package container_image
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	fakeMondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo/fake"
+	"go.mondoo.com/mondoo-operator/pkg/utils/test"
+	"go.mondoo.com/mondoo-operator/tests/framework/utils"
+)
+
+const (
+	nginxImageID = "docker-pullable://nginx@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	redisImageID = "redis@sha256:0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+type NewImagesReconcilerSuite struct {
+	suite.Suite
+	ctx    context.Context
+	scheme *runtime.Scheme
+
+	auditConfig mondoov1alpha2.MondooAuditConfig
+	kubeClient  client.Client
+	reconciler  *NewImagesReconciler
+}
+
+func (s *NewImagesReconcilerSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.scheme = clientgoscheme.Scheme
+	s.Require().NoError(mondoov1alpha2.AddToScheme(s.scheme))
+}
+
+func (s *NewImagesReconcilerSuite) BeforeTest(suiteName, testName string) {
+	s.auditConfig = utils.DefaultAuditConfig("mondoo-operator", false, true, false)
+	s.auditConfig.Spec.Containers.EventDriven.Enable = true
+
+	s.kubeClient = fake.NewClientBuilder().
+		WithScheme(s.scheme).
+		WithObjects(test.TestKubeSystemNamespace(), &s.auditConfig, testPod("app", "nginx", nginxImageID)).
+		Build()
+	s.reconciler = &NewImagesReconciler{
+		Client:                 s.kubeClient,
+		ContainerImageResolver: fakeMondoo.NewNoOpContainerImageResolver(),
+	}
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_RunningImagesAreNotScanned() {
+	result := s.reconcile()
+	s.True(result.IsZero())
+
+	s.Empty(s.jobs())
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_NewImageIsScanned() {
+	s.reconcile()
+
+	pod := testPod("app", "redis", redisImageID)
+	s.NoError(s.kubeClient.Create(s.ctx, pod))
+	s.True(s.reconciler.hasNewImages(client.ObjectKeyFromObject(&s.auditConfig), pod))
+
+	result := s.reconcile()
+	s.True(result.IsZero())
+
+	jobs := s.jobs()
+	s.Require().Len(jobs, 1)
+	s.Equal(NewImagesJobLabels(s.auditConfig), jobs[0].Labels)
+	s.Equal(s.auditConfig.Name, jobs[0].OwnerReferences[0].Name)
+
+	configMap := &corev1.ConfigMap{}
+	s.NoError(s.kubeClient.Get(s.ctx, client.ObjectKeyFromObject(&jobs[0]), configMap))
+	s.Contains(configMap.Data["inventory"], "redis@sha256:")
+	s.NotContains(configMap.Data["inventory"], "nginx@sha256:")
+	s.False(s.reconciler.hasNewImages(client.ObjectKeyFromObject(&s.auditConfig), pod))
+
+	// The same images are not scanned again
+	s.reconcile()
+	s.Len(s.jobs(), 1)
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_BatchInterval() {
+	s.reconcile()
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("app", "redis", redisImageID)))
+	s.reconcile()
+	s.Require().Len(s.jobs(), 1)
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("app", "busybox", "busybox@sha256:0000000000000000000000000000000000000000000000000000000000000003")))
+	result := s.reconcile()
+	s.Greater(result.RequeueAfter, time.Duration(0))
+	s.LessOrEqual(result.RequeueAfter, defaultNewImagesBatchInterval)
+	s.Len(s.jobs(), 1, "a second scan must wait for the batch interval")
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_StoppedImagesAreForgotten() {
+	s.reconcile()
+	key := client.ObjectKeyFromObject(&s.auditConfig)
+	s.Contains(s.reconciler.trackers[key].seen, "sha256:0000000000000000000000000000000000000000000000000000000000000001")
+
+	s.NoError(s.kubeClient.Delete(s.ctx, testPod("app", "nginx", nginxImageID)))
+	s.reconcile()
+	s.Empty(s.reconciler.trackers[key].seen)
+
+	// An image that runs again is scanned again
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("app", "nginx-2", nginxImageID)))
+	s.reconcile()
+	s.Len(s.jobs(), 1)
+	s.Len(s.reconciler.trackers[key].seen, 1)
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_ExcludedNamespace() {
+	s.auditConfig.Spec.Filtering.Namespaces.Exclude = []string{"excluded"}
+	s.NoError(s.kubeClient.Update(s.ctx, &s.auditConfig))
+	s.reconcile()
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("excluded", "redis", redisImageID)))
+	s.reconcile()
+	s.Empty(s.jobs())
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_NamespacePatternAndSelector() {
+	s.auditConfig.Spec.Filtering.Namespaces.Include = []string{"team-*"}
+	s.auditConfig.Spec.Filtering.Namespaces.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	s.NoError(s.kubeClient.Update(s.ctx, &s.auditConfig))
+	s.NoError(s.kubeClient.Create(s.ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"env": "prod"}}}))
+	s.NoError(s.kubeClient.Create(s.ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"env": "dev"}}}))
+	s.reconcile()
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("team-b", "redis", redisImageID)))
+	s.reconcile()
+	s.Empty(s.jobs())
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("team-a", "redis", redisImageID)))
+	s.reconcile()
+	s.Len(s.jobs(), 1)
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_ScanOptOut() {
+	optOut := map[string]string{constants.MondooScanAnnotation: "false"}
+	s.NoError(s.kubeClient.Create(s.ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vendor", Annotations: optOut}}))
+	s.reconcile()
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("vendor", "redis", redisImageID)))
+	pod := testPod("app", "busybox", "busybox@sha256:0000000000000000000000000000000000000000000000000000000000000003")
+	pod.Annotations = optOut
+	s.NoError(s.kubeClient.Create(s.ctx, pod))
+	s.Empty(s.reconciler.podRequestMapper(s.ctx, pod))
+
+	s.reconcile()
+	s.Empty(s.jobs())
+}
+
+func (s *NewImagesReconcilerSuite) TestReconcile_Disabled() {
+	s.reconcile()
+	s.auditConfig.Spec.Containers.EventDriven.Enable = false
+	s.NoError(s.kubeClient.Update(s.ctx, &s.auditConfig))
+
+	s.NoError(s.kubeClient.Create(s.ctx, testPod("app", "redis", redisImageID)))
+	s.reconcile()
+	s.Empty(s.jobs())
+	s.Empty(s.reconciler.trackers)
+}
+
+func (s *NewImagesReconcilerSuite) TestPodImageRefs() {
+	pod := testPod("app", "nginx", nginxImageID)
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "init", ImageID: "sha256:0000000000000000000000000000000000000000000000000000000000000004"},
+	}
+
+	s.Equal([]string{"nginx@sha256:0000000000000000000000000000000000000000000000000000000000000001"}, podImageRefs(pod))
+}
+
+func (s *NewImagesReconcilerSuite) reconcile() ctrl.Result {
+	result, err := s.reconciler.Reconcile(s.ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&s.auditConfig)})
+	s.Require().NoError(err)
+	return result
+}
+
+func (s *NewImagesReconcilerSuite) jobs() []batchv1.Job {
+	jobs := &batchv1.JobList{}
+	s.Require().NoError(s.kubeClient.List(s.ctx, jobs))
+	return jobs.Items
+}
+
+func testPod(namespace, name, imageID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: name, Image: name}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: name, ImageID: imageID}},
+		},
+	}
+}
+
+func TestNewImagesReconcilerSuite(t *testing.T) {
+	suite.Run(t, new(NewImagesReconcilerSuite))
+}
@@ -0,0 +1,224 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package container_image
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const podCacheSyncTimeout = 2 * time.Minute
+
+// podCaches keeps a Pod cache per MondooAuditConfig with event-driven image scanning. Each cache only lists and
+// watches the namespaces the MondooAuditConfig filters in, and stores the Pods stripped to the fields the
+// NewImagesReconciler reads. It is started by the manager, which stops all caches when it shuts down.
+type podCaches struct {
+	config     *rest.Config
+	httpClient *http.Client
+	scheme     *runtime.Scheme
+	mapper     meta.RESTMapper
+
+	// watch registers the Pod events of a cache with the controller.
+	watch func(cache.Cache) error
+
+	started chan struct{}
+	ctx     context.Context
+
+	mu     sync.Mutex
+	caches map[types.NamespacedName]*podCache
+}
+
+type podCache struct {
+	cache.Cache
+	scope  string
+	cancel context.CancelFunc
+}
+
+func newPodCaches(config *rest.Config, httpClient *http.Client, scheme *runtime.Scheme, mapper meta.RESTMapper) *podCaches {
+	return &podCaches{
+		config:     config,
+		httpClient: httpClient,
+		scheme:     scheme,
+		mapper:     mapper,
+		started:    make(chan struct{}),
+		caches:     make(map[types.NamespacedName]*podCache),
+	}
+}
+
+// Start implements manager.Runnable. Pod caches are only started while the manager runs.
+func (p *podCaches) Start(ctx context.Context) error {
+	p.ctx = ctx
+	close(p.started)
+	<-ctx.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.caches {
+		p.stopLocked(key)
+	}
+	return nil
+}
+
+// reader returns the Pod cache of the MondooAuditConfig. A new cache is started when the MondooAuditConfig
+// has none yet or when its namespace filtering changed.
+func (p *podCaches) reader(ctx context.Context, m *v1alpha2.MondooAuditConfig) (client.Reader, error) {
+	opts, scope := podCacheOptions(m)
+	key := client.ObjectKeyFromObject(m)
+
+	select {
+	case <-p.started:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.caches[key]; ok && c.scope == scope {
+		return c, nil
+	}
+	p.stopLocked(key)
+
+	opts.HTTPClient = p.httpClient
+	opts.Scheme = p.scheme
+	opts.Mapper = p.mapper
+	c, err := cache.New(p.config, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pod cache: %w", err)
+	}
+
+	cacheCtx, cancel := context.WithCancel(p.ctx)
+	go func() {
+		if err := c.Start(cacheCtx); err != nil {
+			newImagesLogger.Error(err, "Pod cache stopped", "mondooAuditConfig", key)
+		}
+	}()
+
+	// GetInformer blocks until the Pods are listed
+	syncCtx, cancelSync := context.WithTimeout(ctx, podCacheSyncTimeout)
+	defer cancelSync()
+	if _, err := c.GetInformer(syncCtx, &corev1.Pod{}); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to sync Pod cache: %w", err)
+	}
+	if err := p.watch(c); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to watch Pod cache: %w", err)
+	}
+
+	p.caches[key] = &podCache{Cache: c, scope: scope, cancel: cancel}
+	newImagesLogger.Info("Started Pod cache", "mondooAuditConfig", key, "scope", scope)
+	return c, nil
+}
+
+// forget stops the Pod cache of a MondooAuditConfig.
+func (p *podCaches) forget(key types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopLocked(key)
+}
+
+func (p *podCaches) stopLocked(key types.NamespacedName) {
+	if c, ok := p.caches[key]; ok {
+		c.cancel()
+		delete(p.caches, key)
+	}
+}
+
+// podCacheOptions returns the cache options of the Pod cache of a MondooAuditConfig and a description of the
+// namespaces it holds. Included namespace names are listed and watched one by one, and excluded namespace
+// names are dropped by the API server. Glob patterns and the namespace selector can match namespaces created
+// later, so they are applied when the Pods are read instead.
+func podCacheOptions(m *v1alpha2.MondooAuditConfig) (cache.Options, string) {
+	opts := cache.Options{DefaultTransform: stripPod}
+	spec := m.Spec.Filtering.Namespaces
+
+	if len(spec.Include) > 0 {
+		if slices.ContainsFunc(spec.Include, isNamespacePattern) {
+			return opts, "all namespaces"
+		}
+		byNamespace := make(map[string]cache.Config, len(spec.Include))
+		for _, ns := range spec.Include {
+			byNamespace[ns] = cache.Config{}
+		}
+		opts.DefaultNamespaces = byNamespace
+		return opts, "namespaces " + strings.Join(slices.Sorted(maps.Keys(byNamespace)), ",")
+	}
+
+	var excluded []string
+	for _, ns := range spec.Exclude {
+		if !isNamespacePattern(ns) {
+			excluded = append(excluded, ns)
+		}
+	}
+	if len(excluded) == 0 {
+		return opts, "all namespaces"
+	}
+	slices.Sort(excluded)
+	excluded = slices.Compact(excluded)
+	selectors := make([]fields.Selector, 0, len(excluded))
+	for _, ns := range excluded {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", ns))
+	}
+	opts.DefaultFieldSelector = fields.AndSelectors(selectors...)
+	return opts, "all namespaces except " + strings.Join(excluded, ",")
+}
+
+// stripPod keeps only the fields of a Pod the NewImagesReconciler reads: its name, the scan opt-out annotation
+// and the image IDs of its containers.
+func stripPod(in any) (any, error) {
+	pod, ok := in.(*corev1.Pod)
+	if !ok {
+		return in, nil
+	}
+
+	stripped := &corev1.Pod{
+		TypeMeta: pod.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              pod.Name,
+			Namespace:         pod.Namespace,
+			UID:               pod.UID,
+			ResourceVersion:   pod.ResourceVersion,
+			DeletionTimestamp: pod.DeletionTimestamp,
+		},
+	}
+	if value, ok := pod.Annotations[constants.MondooScanAnnotation]; ok {
+		stripped.Annotations = map[string]string{constants.MondooScanAnnotation: value}
+	}
+	stripped.Status.InitContainerStatuses = strippedContainerStatuses(pod.Status.InitContainerStatuses)
+	stripped.Status.ContainerStatuses = strippedContainerStatuses(pod.Status.ContainerStatuses)
+	return stripped, nil
+}
+
+func strippedContainerStatuses(statuses []corev1.ContainerStatus) []corev1.ContainerStatus {
+	if len(statuses) == 0 {
+		return nil
+	}
+	stripped := make([]corev1.ContainerStatus, 0, len(statuses))
+	for _, s := range statuses {
+		stripped = append(stripped, corev1.ContainerStatus{Name: s.Name, ImageID: s.ImageID})
+	}
+	return stripped
+}
+
+func isNamespacePattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
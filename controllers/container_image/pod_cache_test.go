@@ -0,0 +1,94 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package container_image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func TestPodCacheOptions_IncludedNamespaces(t *testing.T) {
+	m := &v1alpha2.MondooAuditConfig{}
+	m.Spec.Filtering.Namespaces.Include = []string{"app2", "app1"}
+	m.Spec.Filtering.Namespaces.Exclude = []string{"kube-system"}
+
+	opts, scope := podCacheOptions(m)
+	assert.Len(t, opts.DefaultNamespaces, 2)
+	assert.Contains(t, opts.DefaultNamespaces, "app1")
+	assert.Contains(t, opts.DefaultNamespaces, "app2")
+	assert.Nil(t, opts.DefaultFieldSelector)
+	assert.NotNil(t, opts.DefaultTransform)
+	assert.Equal(t, "namespaces app1,app2", scope)
+}
+
+func TestPodCacheOptions_IncludedPattern(t *testing.T) {
+	m := &v1alpha2.MondooAuditConfig{}
+	m.Spec.Filtering.Namespaces.Include = []string{"app1", "team-*"}
+
+	// Namespaces matching the pattern can be created later
+	opts, scope := podCacheOptions(m)
+	assert.Empty(t, opts.DefaultNamespaces)
+	assert.Nil(t, opts.DefaultFieldSelector)
+	assert.Equal(t, "all namespaces", scope)
+}
+
+func TestPodCacheOptions_ExcludedNamespaces(t *testing.T) {
+	m := &v1alpha2.MondooAuditConfig{}
+	m.Spec.Filtering.Namespaces.Exclude = []string{"kube-system", "team-*", "default"}
+
+	opts, scope := podCacheOptions(m)
+	assert.Empty(t, opts.DefaultNamespaces)
+	require.NotNil(t, opts.DefaultFieldSelector)
+	assert.True(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "app"}))
+	assert.False(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "kube-system"}))
+	assert.False(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "default"}))
+	assert.Equal(t, "all namespaces except default,kube-system", scope)
+}
+
+func TestPodCacheOptions_AllNamespaces(t *testing.T) {
+	opts, scope := podCacheOptions(&v1alpha2.MondooAuditConfig{})
+	assert.Empty(t, opts.DefaultNamespaces)
+	assert.Nil(t, opts.DefaultFieldSelector)
+	assert.Equal(t, "all namespaces", scope)
+}
+
+func TestStripPod(t *testing.T) {
+	pod := testPod("app", "nginx", nginxImageID)
+	pod.Labels = map[string]string{"app": "nginx"}
+	pod.Annotations = map[string]string{
+		constants.MondooScanAnnotation:                     "false",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+	}
+	pod.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	pod.Spec.NodeName = "node-1"
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: "init", ImageID: redisImageID, RestartCount: 1}}
+
+	out, err := stripPod(pod)
+	require.NoError(t, err)
+	stripped := out.(*corev1.Pod)
+
+	assert.Equal(t, "app", stripped.Namespace)
+	assert.Equal(t, "nginx", stripped.Name)
+	assert.Equal(t, map[string]string{constants.MondooScanAnnotation: "false"}, stripped.Annotations)
+	assert.Empty(t, stripped.Labels)
+	assert.Empty(t, stripped.ManagedFields)
+	assert.Equal(t, corev1.PodSpec{}, stripped.Spec)
+	assert.Equal(t, []corev1.ContainerStatus{{Name: "init", ImageID: redisImageID}}, stripped.Status.InitContainerStatuses)
+	assert.Equal(t, []corev1.ContainerStatus{{Name: "nginx", ImageID: nginxImageID}}, stripped.Status.ContainerStatuses)
+	assert.Equal(t, podImageRefs(pod), podImageRefs(stripped))
+
+	// Other objects are left as they are
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	out, err = stripPod(ns)
+	require.NoError(t, err)
+	assert.Same(t, ns, out)
+}
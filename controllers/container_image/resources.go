@@ -43,20 +43,7 @@ const (
 func CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName string, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) *batchv1.CronJob {
 	ls := CronJobLabels(*m)
 
-	cmd := []string{
-		"cnspec", "scan", "k8s",
-		"--config", "/etc/opt/mondoo/config/mondoo.yml",
-		"--inventory-file", "/etc/opt/mondoo/config/inventory.yml",
-		"--report-type", "none",
-	}
-
-	// Only add proxy settings if SkipProxyForCnspec is false
-	// cnspec-based components may not properly handle NO_PROXY for internal domains
-	if !cfg.Spec.SkipProxyForCnspec {
-		if apiProxy := k8s.APIProxyURL(cfg); apiProxy != nil {
-			cmd = append(cmd, "--api-proxy", *apiProxy)
-		}
-	}
+	cmd := scanCommand(cfg, "k8s")
 
 	containerResources := k8s.ResourcesRequirementsWithDefaults(m.Spec.Containers.Resources, k8s.DefaultContainerScanningResources)
 	gcLimit := gomemlimit.CalculateGoMemLimit(containerResources)
@@ -70,6 +57,11 @@ func CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName string
 	// Add proxy environment variables from MondooOperatorConfig only if SkipProxyForCnspec is false
 	if !cfg.Spec.SkipProxyForCnspec {
 		envVars = append(envVars, k8s.ProxyEnvVars(cfg)...)
+		// A container proxy with credentials can't be set in the inventory, so images are pulled through
+		// the HTTP/HTTPS proxy instead. Mondoo API calls keep using --api-proxy.
+		if containerProxyEnvVars := k8s.ContainerProxyEnvVars(cfg); len(containerProxyEnvVars) > 0 {
+			envVars = k8s.MergeEnv(append(k8s.ProxyCredentialsEnvVars(cfg), envVars...), containerProxyEnvVars)
+		}
 	}
 
 	envVars = k8s.MergeEnv(envVars, m.Spec.Containers.Env)
@@ -157,7 +149,7 @@ func CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName string
 												},
 												{
 													Secret: &corev1.SecretProjection{
-														LocalObjectReference: k8s.ConfigSecretRef(*m),
+														LocalObjectReference: k8s.ConfigSecretRef(*m, k8s.ContainersScan),
 														Items: []corev1.KeyToPath{{
 															Key:  "config",
 															Path: "mondoo.yml",
@@ -237,9 +229,31 @@ func CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName string
 		)
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&cronjob.Spec.JobTemplate.Spec.Template.Spec, cfg)
+
 	return cronjob
 }
 
+// scanCommand returns the cnspec command that scans the inventory mounted into the scan container.
+func scanCommand(cfg v1alpha2.MondooOperatorConfig, target ...string) []string {
+	cmd := append([]string{"cnspec", "scan"}, target...)
+	cmd = append(cmd,
+		"--config", "/etc/opt/mondoo/config/mondoo.yml",
+		"--inventory-file", "/etc/opt/mondoo/config/inventory.yml",
+		"--report-type", "none",
+	)
+
+	// Only add proxy settings if SkipProxyForCnspec is false
+	// cnspec-based components may not properly handle NO_PROXY for internal domains
+	if !cfg.Spec.SkipProxyForCnspec {
+		if apiProxy := k8s.APIProxyURL(cfg); apiProxy != nil {
+			cmd = append(cmd, "--api-proxy", *apiProxy)
+		}
+	}
+	return cmd
+}
+
 func CronJobLabels(m v1alpha2.MondooAuditConfig) map[string]string {
 	return map[string]string{
 		"app":       "mondoo-container-scan",
@@ -248,6 +262,13 @@ func CronJobLabels(m v1alpha2.MondooAuditConfig) map[string]string {
 	}
 }
 
+// SpaceCronJobLabels returns the labels of the CronJob scanning the images of the namespaces routed to a space.
+func SpaceCronJobLabels(m v1alpha2.MondooAuditConfig, spaceID string) map[string]string {
+	ls := CronJobLabels(m)
+	ls[k8s.SpaceIDLabel] = spaceID
+	return ls
+}
+
 // TODO: remove in next version
 func OldCronJobName(prefix string) string {
 	return fmt.Sprintf("%s%s", prefix, OldCronJobNameSuffix)
@@ -257,6 +278,38 @@ func CronJobName(prefix string) string {
 	return k8s.CronJobName("container-scan", prefix)
 }
 
+func SpaceCronJobName(prefix, spaceID string) string {
+	return k8s.CronJobNameWithCluster("container-scan", prefix, "space-"+spaceID)
+}
+
+// SpaceCronJob creates the CronJob scanning the images of the namespaces spec.spaceRouting routes to a space.
+// It only differs from the CronJob of the default space in its name, labels, inventory and credentials.
+func SpaceCronJob(image, integrationMrn, clusterUid, privateRegistrySecretName string, m *v1alpha2.MondooAuditConfig, route k8s.SpaceRoute, cfg v1alpha2.MondooOperatorConfig) *batchv1.CronJob {
+	routed := k8s.SpaceAuditConfig(*m, route)
+	cronjob := CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName, &routed, cfg)
+
+	ls := SpaceCronJobLabels(*m, route.SpaceID)
+	cronjob.Name = SpaceCronJobName(m.Name, route.SpaceID)
+	cronjob.Labels = ls
+	cronjob.Spec.JobTemplate.Labels = ls
+	// Keep pod template labels added for WIF
+	podLabels := make(map[string]string, len(cronjob.Spec.JobTemplate.Spec.Template.Labels)+1)
+	maps.Copy(podLabels, cronjob.Spec.JobTemplate.Spec.Template.Labels)
+	maps.Copy(podLabels, ls)
+	cronjob.Spec.JobTemplate.Spec.Template.Labels = podLabels
+	for _, v := range cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes {
+		if v.Name != "config" {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if source.ConfigMap != nil {
+				source.ConfigMap.Name = SpaceConfigMapName(m.Name, route.SpaceID)
+			}
+		}
+	}
+	return cronjob
+}
+
 func ConfigMap(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, platformIdsExclude []string, scanTime *time.Time) (*corev1.ConfigMap, error) {
 	inv, err := Inventory(integrationMRN, clusterUID, m, cfg, platformIdsExclude, scanTime)
 	if err != nil {
@@ -276,6 +329,10 @@ func ConfigMapName(prefix string) string {
 	return fmt.Sprintf("%s%s", prefix, InventoryConfigMapBase)
 }
 
+func SpaceConfigMapName(prefix, spaceID string) string {
+	return fmt.Sprintf("%s%s-space-%s", prefix, InventoryConfigMapBase, spaceID)
+}
+
 func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, platformIdsExclude []string, scanTime *time.Time) (string, error) {
 	inv := &inventory.Inventory{
 		Metadata: &inventory.ObjectMeta{
@@ -313,9 +370,9 @@ func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig,
 		inv.Metadata.Annotations["platformids-exclude"] = strings.Join(platformIdsExclude, ",")
 	}
 
-	if cfg.Spec.ContainerProxy != nil {
+	if containerProxy := k8s.ContainerProxyURL(cfg); containerProxy != nil {
 		for i := range inv.Spec.Assets {
-			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *cfg.Spec.ContainerProxy
+			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *containerProxy
 		}
 	}
 
@@ -428,3 +485,116 @@ func containerImageOptions(m v1alpha2.MondooAuditConfig) map[string]string {
 	}
 	return opts
 }
+
+// NewImagesJobLabels returns the labels of the Jobs that scan newly deployed container images.
+func NewImagesJobLabels(m v1alpha2.MondooAuditConfig) map[string]string {
+	return map[string]string{
+		"app":       "mondoo-container-scan",
+		"scan":      "new-images",
+		"mondoo_cr": m.Name,
+	}
+}
+
+// NewImagesJobName returns the name of the Job that scans a batch of newly deployed container images.
+func NewImagesJobName(prefix, batchID string) string {
+	return fmt.Sprintf("%s-%s", k8s.CronJobName("new-images", prefix), batchID)
+}
+
+// NewImagesJob returns a Job that scans only the given container images. It uses the same pod spec as the
+// scheduled container scan CronJob, so it pulls images with the same registry credentials and WIF init container.
+func NewImagesJob(image, integrationMrn, clusterUid, privateRegistrySecretName, name string, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) *batchv1.Job {
+	cronJob := CronJob(image, integrationMrn, clusterUid, privateRegistrySecretName, m, cfg)
+	spec := *cronJob.Spec.JobTemplate.Spec.DeepCopy()
+
+	// The inventory lists the images as assets, so no Kubernetes discovery is needed
+	spec.Template.Spec.Containers[0].Command = scanCommand(cfg)
+	for _, v := range spec.Template.Spec.Volumes {
+		if v.Name != "config" {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if source.ConfigMap != nil {
+				source.ConfigMap.Name = name
+			}
+		}
+	}
+
+	// Keep pod template labels added for WIF, but mark the pods as belonging to the new images scan
+	ls := NewImagesJobLabels(*m)
+	podLabels := make(map[string]string, len(spec.Template.Labels))
+	maps.Copy(podLabels, spec.Template.Labels)
+	maps.Copy(podLabels, ls)
+	spec.Template.Labels = podLabels
+
+	// Finished Jobs are only kept around for troubleshooting
+	spec.TTLSecondsAfterFinished = ptr.To(int32(3600))
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: m.Namespace,
+			Labels:    ls,
+		},
+		Spec: spec,
+	}
+}
+
+// NewImagesConfigMap returns the ConfigMap holding the inventory for a NewImagesJob.
+func NewImagesConfigMap(integrationMRN, clusterUID, name string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, images []string) (*corev1.ConfigMap, error) {
+	inv, err := NewImagesInventory(integrationMRN, clusterUID, m, cfg, images)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: m.Namespace,
+			Name:      name,
+			Labels:    NewImagesJobLabels(m),
+		},
+		Data: map[string]string{"inventory": inv},
+	}, nil
+}
+
+// NewImagesInventory returns an inventory with one registry image asset per image. images must be image
+// references pinned by digest. The assets carry the same labels and annotations as the assets discovered
+// by the scheduled scan.
+func NewImagesInventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, images []string) (string, error) {
+	inv := &inventory.Inventory{
+		Metadata: &inventory.ObjectMeta{
+			Name: "mondoo-k8s-new-images-inventory",
+		},
+		Spec: &inventory.InventorySpec{},
+	}
+
+	for _, image := range images {
+		asset := &inventory.Asset{
+			Connections: []*inventory.Config{
+				{
+					Type:    "registry-image",
+					Host:    image,
+					Options: map[string]string{},
+				},
+			},
+			Labels:    containerImageLabels(&m, nil),
+			ManagedBy: mondoo.ManagedByContainersLabel(clusterUID),
+		}
+		if integrationMRN != "" {
+			asset.Labels[constants.MondooAssetsIntegrationLabel] = integrationMRN
+		}
+		if containerProxy := k8s.ContainerProxyURL(cfg); containerProxy != nil {
+			asset.Connections[0].Options["container-proxy"] = *containerProxy
+		}
+		// Operator annotations go last so they cannot be overwritten by user values.
+		asset.AddAnnotations(m.Spec.Annotations)
+		asset.AddAnnotations(constants.AuditConfigAnnotations(m.Name, m.Namespace))
+		inv.Spec.Assets = append(inv.Spec.Assets, asset)
+	}
+
+	invBytes, err := yaml.Marshal(inv)
+	if err != nil {
+		return "", err
+	}
+
+	return string(invBytes), nil
+}
@@ -115,7 +115,7 @@ func (r *IntegrationReconciler) processMondooAuditConfig(m v1alpha2.MondooAuditC
 		return err
 	}
 
-	serviceAccount, err := k8s.GetServiceAccountFromSecret(*secret)
+	tokenSource, err := mondoo.TokenSourceForSecret(secret)
 	if err != nil {
 		return err
 	}
@@ -127,7 +127,16 @@ func (r *IntegrationReconciler) processMondooAuditConfig(m v1alpha2.MondooAuditC
 		}
 	}
 
-	result, err := mondoo.IntegrationCheckIn(r.ctx, integrationMrn, r.configHashes[integrationMrn], *serviceAccount, r.MondooClientBuilder, config.Spec.HttpProxy, config.Spec.HttpsProxy, config.Spec.NoProxy, logger)
+	tlsConfig, err := mondoo.APITLSConfig(r.ctx, r.Client, m.Namespace, config)
+	if err != nil {
+		return err
+	}
+	proxies, err := k8s.ResolveProxyURLs(r.ctx, r.Client, m.Namespace, config)
+	if err != nil {
+		return err
+	}
+
+	result, err := mondoo.IntegrationCheckIn(r.ctx, integrationMrn, r.configHashes[integrationMrn], tokenSource, r.MondooClientBuilder, proxies.HttpProxy, proxies.HttpsProxy, proxies.NoProxy, tlsConfig, logger)
 	if err != nil {
 		logger.Error(err, "failed to CheckIn() for integration", "integrationMRN", string(integrationMrn))
 		return err
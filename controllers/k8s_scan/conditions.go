@@ -53,3 +53,12 @@ func updateWorkloadsConditions(config *v1alpha2.MondooAuditConfig, degradedStatu
 	config.Status.Conditions = mondoo.SetMondooAuditCondition(
 		config.Status.Conditions, v1alpha2.K8sResourcesScanningDegraded, status, reason, msg, updateCheck, affectedPods, memoryLimit)
 }
+
+// updateWorkloadsConditionsInvalidConfig marks Kubernetes resources scanning as degraded because of a
+// configuration error that has to be fixed in the MondooAuditConfig.
+func updateWorkloadsConditionsInvalidConfig(config *v1alpha2.MondooAuditConfig, err error) {
+	config.Status.Conditions = mondoo.SetMondooAuditCondition(
+		config.Status.Conditions, v1alpha2.K8sResourcesScanningDegraded, corev1.ConditionTrue,
+		"KubernetesResourcesScanningInvalidConfig", "Kubernetes Resources Scanning is misconfigured: "+err.Error(),
+		mondoo.UpdateConditionIfReasonOrMessageChange, []string{}, "")
+}
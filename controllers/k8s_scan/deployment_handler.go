@@ -5,8 +5,10 @@ package k8s_scan
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -14,11 +16,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/annotations"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
@@ -31,8 +35,8 @@ const (
 	WorkloadDeploymentNameTemplate          = `%s-workload`
 )
 
-// validateExternalClusterAuth validates that exactly one authentication method is specified
-func validateExternalClusterAuth(cluster v1alpha2.ExternalCluster) error {
+// ValidateExternalClusterAuth validates that exactly one authentication method is specified
+func ValidateExternalClusterAuth(cluster v1alpha2.ExternalCluster) error {
 	authMethods := 0
 	if cluster.KubeconfigSecretRef != nil {
 		authMethods++
@@ -126,7 +130,20 @@ func validateExternalClusterAuth(cluster v1alpha2.ExternalCluster) error {
 	return nil
 }
 
-const defaultSATokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+// ValidateExternalClusterFiltering validates that the namespace filtering of an external cluster only uses
+// namespace names. Patterns and selectors are resolved against the operator's cluster, which cannot be done
+// for external clusters.
+func ValidateExternalClusterFiltering(cluster v1alpha2.ExternalCluster, m v1alpha2.MondooAuditConfig) error {
+	if k8s.NamespaceFilteringNeedsResolution(ExternalClusterFiltering(cluster, m).Namespaces) {
+		return fmt.Errorf("externalCluster %q: namespace patterns and namespaceSelector are not supported for external clusters, set filtering with namespace names for this cluster", cluster.Name)
+	}
+	return nil
+}
+
+const defaultSATokenPath = k8s.DefaultServiceAccountTokenPath
+
+// removedClusterPurgeInterval is how often the purge of the assets of a removed external cluster is retried.
+const removedClusterPurgeInterval = 10 * time.Minute
 
 type DeploymentHandler struct {
 	KubeClient             client.Client
@@ -148,15 +165,42 @@ func (n *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error)
 
 	hasExternalClusters := len(n.Mondoo.Spec.KubernetesResources.ExternalClusters) > 0
 
-	if !n.Mondoo.Spec.KubernetesResources.Enable {
+	if n.Mondoo.Spec.KubernetesResources.Enable || hasExternalClusters {
+		if err := ValidateDiscovery(*n.Mondoo); err != nil {
+			// Retrying won't help until the spec is fixed, so report it and keep the current scans
+			logger.Info("Invalid Kubernetes resources discovery configuration", "error", err.Error())
+			updateWorkloadsConditionsInvalidConfig(n.Mondoo, err)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if !n.Mondoo.Spec.KubernetesResources.Enable || ScheduledScanReplaced(*n.Mondoo) {
 		// Clean up local cluster resources only
 		if err := n.downLocalCluster(ctx); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else {
-		// Sync local cluster CronJob
-		if err := n.syncCronJob(ctx); err != nil {
+		filtering, err := k8s.ResolveNamespaceFiltering(ctx, n.KubeClient, n.Mondoo.Spec.Filtering)
+		switch {
+		case errors.Is(err, k8s.ErrNoMatchingNamespaces):
+			// Nothing to scan until a matching namespace is created
+			logger.Info("No namespaces match the namespace filtering, removing local cluster scanning")
+			if err := n.downLocalCluster(ctx); err != nil {
+				return ctrl.Result{}, err
+			}
+		case err != nil:
+			logger.Error(err, "Failed to resolve the namespace filtering")
 			return ctrl.Result{}, err
+		default:
+			routing, err := k8s.ResolveSpaceRouting(ctx, n.KubeClient, *n.Mondoo, filtering)
+			if err != nil {
+				logger.Error(err, "Failed to resolve the space routing")
+				return ctrl.Result{}, err
+			}
+			// Sync local cluster CronJobs
+			if err := n.syncCronJob(ctx, routing); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 	}
 
@@ -173,7 +217,7 @@ func (n *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error)
 	}
 
 	// Perform garbage collection of stale K8s resource scan assets if a new successful scan has completed
-	if n.Mondoo.Spec.KubernetesResources.Enable || hasExternalClusters {
+	if n.Mondoo.Spec.KubernetesResources.Enable || hasExternalClusters || len(n.Mondoo.Status.ExternalClusters) > 0 {
 		clusterUid, err := k8s.GetClusterUID(ctx, n.KubeClient, logger)
 		if err != nil {
 			logger.Error(err, "Failed to get cluster's UID for garbage collection")
@@ -196,10 +240,16 @@ func (n *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error)
 		}
 	}
 
+	// Retry the purge of removed external clusters whose assets are still in Mondoo Platform
+	if len(mondoo.RemovedExternalClusters(*n.Mondoo)) > 0 &&
+		(result.RequeueAfter == 0 || removedClusterPurgeInterval < result.RequeueAfter) {
+		result.RequeueAfter = removedClusterPurgeInterval
+	}
+
 	return result, nil
 }
 
-func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
+func (n *DeploymentHandler) syncCronJob(ctx context.Context, routing k8s.SpaceRouting) error {
 	cnspecImage, err := n.ContainerImageResolver.CnspecImage(
 		n.Mondoo.Spec.Scanner.Image.Name, n.Mondoo.Spec.Scanner.Image.Tag, n.Mondoo.Spec.Scanner.Image.Digest, n.MondooOperatorConfig.Spec.SkipContainerResolution)
 	if err != nil {
@@ -207,6 +257,12 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
 		return err
 	}
 
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, n.ContainerImageResolver, *n.Mondoo, n.MondooOperatorConfig.Spec.SkipContainerResolution)
+	if err != nil {
+		logger.Error(err, "Failed to resolve mondoo-operator container image")
+		return err
+	}
+
 	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, n.KubeClient, *n.Mondoo)
 	if err != nil {
 		logger.Error(err,
@@ -220,26 +276,37 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
 		return err
 	}
 
-	if err := n.syncConfigMap(ctx, integrationMrn, clusterUid); err != nil {
-		return err
-	}
-
-	desired := CronJob(cnspecImage, n.Mondoo, *n.MondooOperatorConfig)
-	obj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
-	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
-		k8s.UpdateCronJobFields(obj, desired)
-		return nil
-	})
-	if err != nil {
+	if scan, ok := DefaultSpaceScanConfig(*n.Mondoo, routing); ok {
+		if err := n.syncConfigMap(ctx, scan, ConfigMapName(n.Mondoo.Name), nil, integrationMrn, clusterUid); err != nil {
+			return err
+		}
+		desired := CronJob(cnspecImage, n.Mondoo, *n.MondooOperatorConfig)
+		k8s.AddMondooCredsSourceToSpec(&desired.Spec.JobTemplate.Spec.Template.Spec, *n.Mondoo, k8s.KubernetesResourcesScan, credsInitImage)
+		if err := n.syncCronJobObject(ctx, desired); err != nil {
+			return err
+		}
+	} else if err := n.downDefaultSpace(ctx); err != nil {
 		return err
 	}
 
-	// When a CronJob is updated, remove completed Jobs so they don't linger with stale config
-	if op == controllerutil.OperationResultUpdated {
-		if err := k8s.DeleteCompletedJobs(ctx, n.KubeClient, n.Mondoo.Namespace, CronJobLabels(*n.Mondoo), logger); err != nil {
-			logger.Error(err, "Failed to clean up completed Jobs after CronJob update")
+	// Every space of spec.spaceRouting gets its own inventory and CronJob
+	activeSpaces := map[string]bool{}
+	for _, route := range routing.Routes {
+		scan, ok := SpaceScanConfig(*n.Mondoo, route)
+		if !ok {
+			continue
+		}
+		ls := SpaceCronJobLabels(*n.Mondoo, route.SpaceID)
+		if err := n.syncConfigMap(ctx, scan, SpaceConfigMapName(n.Mondoo.Name, route.SpaceID), ls, integrationMrn, clusterUid); err != nil {
+			return err
+		}
+		if err := n.syncCronJobObject(ctx, SpaceCronJob(cnspecImage, n.Mondoo, route, *n.MondooOperatorConfig)); err != nil {
 			return err
 		}
+		activeSpaces[route.SpaceID] = true
+	}
+	if err := n.cleanupSpaceCronJobs(ctx, activeSpaces); err != nil {
+		return err
 	}
 
 	cronJobs, err := n.getCronJobsForAuditConfig(ctx)
@@ -265,12 +332,45 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
 	return n.cleanupWorkloadDeployment(ctx)
 }
 
-func (n *DeploymentHandler) syncConfigMap(ctx context.Context, integrationMrn, clusterUid string) error {
-	desired, err := ConfigMap(integrationMrn, clusterUid, *n.Mondoo, *n.MondooOperatorConfig)
+// syncCronJobObject creates or updates a local cluster CronJob. Completed Jobs of an updated CronJob are
+// removed so they don't linger with stale config.
+func (n *DeploymentHandler) syncCronJobObject(ctx context.Context, desired *batchv1.CronJob) error {
+	obj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
+		k8s.UpdateCronJobFields(obj, desired)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if op == controllerutil.OperationResultUpdated {
+		if err := k8s.DeleteCompletedJobs(ctx, n.KubeClient, n.Mondoo.Namespace, desired.Spec.JobTemplate.Labels, logger); err != nil {
+			logger.Error(err, "Failed to clean up completed Jobs after CronJob update")
+			return err
+		}
+	}
+	return nil
+}
+
+// syncConfigMap syncs the inventory ConfigMap of a local cluster CronJob. m is the MondooAuditConfig the
+// inventory is rendered from, with the namespace names the filtering and space routing resolved to.
+func (n *DeploymentHandler) syncConfigMap(
+	ctx context.Context, m v1alpha2.MondooAuditConfig, name string, ls map[string]string, integrationMrn, clusterUid string,
+) error {
+	namespaceAnnotations, err := n.namespaceAnnotations(ctx, m.Spec.Filtering)
+	if err != nil {
+		logger.Error(err, "failed to derive annotations from namespace labels")
+		return err
+	}
+
+	desired, err := ConfigMap(integrationMrn, clusterUid, m, *n.MondooOperatorConfig, namespaceAnnotations)
 	if err != nil {
 		logger.Error(err, "failed to generate desired ConfigMap with inventory")
 		return err
 	}
+	desired.Name = name
+	desired.Labels = ls
 
 	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 	if _, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
@@ -284,6 +384,37 @@ func (n *DeploymentHandler) syncConfigMap(ctx context.Context, integrationMrn, c
 	return nil
 }
 
+// namespaceAnnotations returns the annotations spec.annotationsFromLabels derives from the labels of the
+// namespaces selected by the filtering. Namespaces without derived annotations are left out.
+func (n *DeploymentHandler) namespaceAnnotations(ctx context.Context, filtering v1alpha2.Filtering) (map[string]map[string]string, error) {
+	rules, err := annotations.ParseLabelRules(n.Mondoo.Spec.AnnotationsFromLabels)
+	if err != nil {
+		return nil, err
+	}
+	if !annotations.HasSource(rules, annotations.NamespaceLabel) {
+		return nil, nil
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := n.KubeClient.List(ctx, namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	namespaceAnnotations := map[string]map[string]string{}
+	for _, ns := range namespaces.Items {
+		matches, err := k8s.NamespaceMatchesFiltering(filtering.Namespaces, ns.Name, ns.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+		if derived := annotations.FromLabels(rules, nil, ns.Labels); len(derived) > 0 {
+			namespaceAnnotations[ns.Name] = derived
+		}
+	}
+	return namespaceAnnotations, nil
+}
+
 // reconcileExternalClusters reconciles CronJobs for external clusters
 func (n *DeploymentHandler) reconcileExternalClusters(ctx context.Context) error {
 	mondooClientImage, err := n.ContainerImageResolver.CnspecImage(
@@ -293,6 +424,12 @@ func (n *DeploymentHandler) reconcileExternalClusters(ctx context.Context) error
 		return err
 	}
 
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, n.ContainerImageResolver, *n.Mondoo, n.MondooOperatorConfig.Spec.SkipContainerResolution)
+	if err != nil {
+		logger.Error(err, "Failed to resolve mondoo-operator container image")
+		return err
+	}
+
 	integrationMrn, err := k8s.TryGetIntegrationMrnForAuditConfig(ctx, n.KubeClient, *n.Mondoo)
 	if err != nil {
 		logger.Error(err, "failed to retrieve integration-mrn for MondooAuditConfig")
@@ -312,11 +449,16 @@ func (n *DeploymentHandler) reconcileExternalClusters(ctx context.Context) error
 		configuredClusters[cluster.Name] = true
 
 		// Validate authentication configuration
-		if err := validateExternalClusterAuth(cluster); err != nil {
+		if err := ValidateExternalClusterAuth(cluster); err != nil {
 			logger.Error(err, "invalid external cluster authentication configuration", "cluster", cluster.Name)
 			return err
 		}
 
+		if err := ValidateExternalClusterFiltering(cluster, *n.Mondoo); err != nil {
+			logger.Error(err, "invalid external cluster namespace filtering", "cluster", cluster.Name)
+			return err
+		}
+
 		// Sync SA kubeconfig ConfigMap if using ServiceAccountAuth
 		if cluster.ServiceAccountAuth != nil {
 			if err := n.syncExternalClusterSAKubeconfigConfigMap(ctx, cluster); err != nil {
@@ -344,7 +486,7 @@ func (n *DeploymentHandler) reconcileExternalClusters(ctx context.Context) error
 		}
 
 		// Sync CronJob for this external cluster
-		if err := n.syncExternalClusterCronJob(ctx, mondooClientImage, cluster); err != nil {
+		if err := n.syncExternalClusterCronJob(ctx, mondooClientImage, credsInitImage, cluster); err != nil {
 			return err
 		}
 	}
@@ -376,8 +518,9 @@ func (n *DeploymentHandler) syncExternalClusterConfigMap(ctx context.Context, in
 	return nil
 }
 
-func (n *DeploymentHandler) syncExternalClusterCronJob(ctx context.Context, image string, cluster v1alpha2.ExternalCluster) error {
+func (n *DeploymentHandler) syncExternalClusterCronJob(ctx context.Context, image, credsInitImage string, cluster v1alpha2.ExternalCluster) error {
 	desired := ExternalClusterCronJob(image, cluster, n.Mondoo, *n.MondooOperatorConfig)
+	k8s.AddMondooCredsSourceToSpec(&desired.Spec.JobTemplate.Spec.Template.Spec, *n.Mondoo, k8s.ExternalClusterScan(cluster.Name), credsInitImage)
 
 	obj := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, obj, n.Mondoo, logger, func() error {
@@ -472,6 +615,18 @@ func (n *DeploymentHandler) cleanupOrphanedExternalClusterResources(ctx context.
 				return err
 			}
 
+			// Delete config override Secret of the cluster's space (if exists)
+			configSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      k8s.ScanConfigSecretName(n.Mondoo.Name, k8s.ExternalClusterScan(clusterName)),
+					Namespace: n.Mondoo.Namespace,
+				},
+			}
+			if err := k8s.DeleteIfExists(ctx, n.KubeClient, configSecret); err != nil {
+				logger.Error(err, "failed to delete orphaned config override Secret for external cluster", "cluster", clusterName)
+				return err
+			}
+
 			logger.Info("Cleaned up orphaned resources for external cluster", "cluster", clusterName)
 		}
 	}
@@ -494,6 +649,26 @@ func (n *DeploymentHandler) getCronJobsForAuditConfig(ctx context.Context) ([]ba
 
 // downLocalCluster cleans up only the local cluster scanning resources
 func (n *DeploymentHandler) downLocalCluster(ctx context.Context) error {
+	if err := n.downDefaultSpace(ctx); err != nil {
+		return err
+	}
+
+	if err := n.cleanupSpaceCronJobs(ctx, nil); err != nil {
+		return err
+	}
+
+	if err := n.cleanupWorkloadDeployment(ctx); err != nil {
+		return err
+	}
+
+	// Clear local cluster status
+	updateWorkloadsConditions(n.Mondoo, false, &corev1.PodList{})
+
+	return nil
+}
+
+// downDefaultSpace deletes the local cluster CronJob and ConfigMap of the default space.
+func (n *DeploymentHandler) downDefaultSpace(ctx context.Context) error {
 	// Delete main cluster CronJob
 	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: CronJobName(n.Mondoo.Name), Namespace: n.Mondoo.Namespace}}
 	if err := k8s.DeleteIfExists(ctx, n.KubeClient, cronJob); err != nil {
@@ -507,14 +682,37 @@ func (n *DeploymentHandler) downLocalCluster(ctx context.Context) error {
 		logger.Error(err, "failed to clean up Kubernetes resource scanning ConfigMap", "namespace", configMap.Namespace, "name", configMap.Name)
 		return err
 	}
+	return nil
+}
 
-	if err := n.cleanupWorkloadDeployment(ctx); err != nil {
+// cleanupSpaceCronJobs deletes the CronJobs and ConfigMaps of routed spaces that are not in activeSpaces.
+func (n *DeploymentHandler) cleanupSpaceCronJobs(ctx context.Context, activeSpaces map[string]bool) error {
+	cronJobs := &batchv1.CronJobList{}
+	selector := labels.SelectorFromSet(CronJobLabels(*n.Mondoo))
+	hasSpace, err := labels.NewRequirement(k8s.SpaceIDLabel, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	listOpts := &client.ListOptions{Namespace: n.Mondoo.Namespace, LabelSelector: selector.Add(*hasSpace)}
+	if err := n.KubeClient.List(ctx, cronJobs, listOpts); err != nil {
+		logger.Error(err, "Failed to list space CronJobs", "namespace", n.Mondoo.Namespace)
 		return err
 	}
 
-	// Clear local cluster status
-	updateWorkloadsConditions(n.Mondoo, false, &corev1.PodList{})
-
+	for i := range cronJobs.Items {
+		spaceID := cronJobs.Items[i].Labels[k8s.SpaceIDLabel]
+		if activeSpaces[spaceID] {
+			continue
+		}
+		logger.Info("Deleting k8s scan CronJob of a space that is no longer routed", "name", cronJobs.Items[i].Name, "spaceId", spaceID)
+		if err := k8s.DeleteIfExists(ctx, n.KubeClient, &cronJobs.Items[i]); err != nil {
+			return err
+		}
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: SpaceConfigMapName(n.Mondoo.Name, spaceID), Namespace: n.Mondoo.Namespace}}
+		if err := k8s.DeleteIfExists(ctx, n.KubeClient, configMap); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -563,8 +761,9 @@ func (n *DeploymentHandler) syncExternalClusterSAKubeconfigConfigMap(ctx context
 	return nil
 }
 
-// garbageCollectIfNeeded checks whether a new successful K8s scan has completed since the last GC run,
-// and if so, performs garbage collection of stale assets via the Mondoo API.
+// garbageCollectIfNeeded checks, for the operator's cluster and every external cluster, whether a new successful
+// K8s scan has completed since the last GC run, and if so, performs garbage collection of its stale assets via the
+// Mondoo API. The assets of external clusters removed from the spec are purged.
 func (n *DeploymentHandler) garbageCollectIfNeeded(ctx context.Context, clusterUid string) {
 	// List all k8s-scan CronJobs (local + external) for this audit config
 	cronJobs := &batchv1.CronJobList{}
@@ -577,37 +776,90 @@ func (n *DeploymentHandler) garbageCollectIfNeeded(ctx context.Context, clusterU
 		return
 	}
 
-	// Find the latest lastSuccessfulTime across all CronJobs
-	var latestSuccess *metav1.Time
+	// Find the latest lastSuccessfulTime of the local CronJobs and of the CronJobs of every external cluster
+	var localSuccess *metav1.Time
+	externalSuccess := map[string]*metav1.Time{}
 	for i := range cronJobs.Items {
 		t := cronJobs.Items[i].Status.LastSuccessfulTime
-		if t != nil && (latestSuccess == nil || t.After(latestSuccess.Time)) {
-			latestSuccess = t
+		if t == nil {
+			continue
+		}
+		if clusterName, ok := cronJobs.Items[i].Labels["cluster_name"]; ok {
+			if latest := externalSuccess[clusterName]; latest == nil || t.After(latest.Time) {
+				externalSuccess[clusterName] = t
+			}
+		} else if localSuccess == nil || t.After(localSuccess.Time) {
+			localSuccess = t
 		}
 	}
 
-	if latestSuccess == nil {
-		// No successful scans yet
-		return
+	// Skip if we already ran GC for this (or a newer) successful scan
+	if localSuccess != nil && (n.Mondoo.Status.LastK8sResourceGarbageCollectionTime == nil ||
+		localSuccess.After(n.Mondoo.Status.LastK8sResourceGarbageCollectionTime.Time)) {
+		if err := n.performGarbageCollection(ctx, mondoo.ManagedByLabel(clusterUid)); err != nil {
+			logger.Error(err, "Failed to perform garbage collection of K8s resource scan assets")
+		}
+
+		// Always update the timestamp so we don't retry until the next new successful scan.
+		// GC failure is non-critical — stale assets will be cleaned up on the next attempt.
+		now := metav1.Now()
+		n.Mondoo.Status.LastK8sResourceGarbageCollectionTime = &now
 	}
 
-	// Skip if we already ran GC for this (or a newer) successful scan
-	if n.Mondoo.Status.LastK8sResourceGarbageCollectionTime != nil &&
-		!latestSuccess.After(n.Mondoo.Status.LastK8sResourceGarbageCollectionTime.Time) {
-		return
+	for _, cluster := range n.Mondoo.Spec.KubernetesResources.ExternalClusters {
+		// The space and credentials are kept, so the assets can still be purged once the cluster is removed,
+		// even if it is removed before its first successful scan
+		status := n.externalClusterStatus(cluster.Name)
+		spaceID, credsRef := k8s.ScanCredentials(*n.Mondoo, k8s.ExternalClusterScan(cluster.Name))
+		status.SpaceID, status.MondooCredsSecretRef = spaceID, credsRef.Name
+
+		success := externalSuccess[cluster.Name]
+		if success == nil {
+			// No assets of the cluster were sent yet
+			continue
+		}
+		if status.LastGarbageCollectionTime != nil && !success.After(status.LastGarbageCollectionTime.Time) {
+			continue
+		}
+		if err := n.performExternalClusterGarbageCollection(ctx, clusterUid, cluster, status); err != nil {
+			logger.Error(err, "Failed to perform garbage collection of external cluster assets", "cluster", cluster.Name)
+		}
+		now := metav1.Now()
+		status.LastGarbageCollectionTime = &now
 	}
 
-	if err := n.performGarbageCollection(ctx, mondoo.ManagedByLabel(clusterUid)); err != nil {
-		logger.Error(err, "Failed to perform garbage collection of K8s resource scan assets")
+	for _, cluster := range mondoo.RemovedExternalClusters(*n.Mondoo) {
+		// Failed purges are retried, but not on every reconcile
+		if cluster.LastGarbageCollectionTime != nil && time.Since(cluster.LastGarbageCollectionTime.Time) < removedClusterPurgeInterval {
+			continue
+		}
+		purged, err := n.purgeRemovedExternalCluster(ctx, clusterUid, cluster)
+		if err != nil {
+			logger.Error(err, "Failed to purge the assets of the removed external cluster", "cluster", cluster.Name)
+		}
+		if purged {
+			n.Mondoo.Status.ExternalClusters = slices.DeleteFunc(n.Mondoo.Status.ExternalClusters, func(c v1alpha2.ExternalClusterStatus) bool {
+				return c.Name == cluster.Name
+			})
+			continue
+		}
+		now := metav1.Now()
+		n.externalClusterStatus(cluster.Name).LastGarbageCollectionTime = &now
 	}
+}
 
-	// Always update the timestamp so we don't retry until the next new successful scan.
-	// GC failure is non-critical — stale assets will be cleaned up on the next attempt.
-	now := metav1.Now()
-	n.Mondoo.Status.LastK8sResourceGarbageCollectionTime = &now
+// externalClusterStatus returns the status of the external cluster, adding it if it is missing.
+func (n *DeploymentHandler) externalClusterStatus(name string) *v1alpha2.ExternalClusterStatus {
+	for i := range n.Mondoo.Status.ExternalClusters {
+		if n.Mondoo.Status.ExternalClusters[i].Name == name {
+			return &n.Mondoo.Status.ExternalClusters[i]
+		}
+	}
+	n.Mondoo.Status.ExternalClusters = append(n.Mondoo.Status.ExternalClusters, v1alpha2.ExternalClusterStatus{Name: name})
+	return &n.Mondoo.Status.ExternalClusters[len(n.Mondoo.Status.ExternalClusters)-1]
 }
 
-// performGarbageCollection calls the Mondoo API to delete stale K8s resource scan assets.
+// performGarbageCollection calls the Mondoo API to delete stale K8s resource scan assets of the operator's cluster.
 func (n *DeploymentHandler) performGarbageCollection(ctx context.Context, managedBy string) error {
 	req := &mondooclient.GarbageCollectAssetsRequest{
 		ManagedBy:       managedBy,
@@ -619,14 +871,113 @@ func (n *DeploymentHandler) performGarbageCollection(ctx context.Context, manage
 		},
 	}
 
-	if err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger); err != nil {
+	var results []*v1alpha2.GarbageCollectionStatus
+	result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, k8s.KubernetesResourcesScan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+	if err != nil {
 		return err
 	}
+	results = append(results, result)
+
+	// Assets of namespaces routed to other spaces are collected in these spaces
+	for _, spaceID := range k8s.RoutedSpaceIDs(*n.Mondoo) {
+		spaceConfig := k8s.SpaceAuditConfig(*n.Mondoo, k8s.SpaceRoute{SpaceID: spaceID})
+		result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, &spaceConfig, k8s.KubernetesResourcesScan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, k8s.KubernetesResourcesScan, results)
 
 	logger.Info("Successfully performed garbage collection of K8s resource scan assets")
 	return nil
 }
 
+// performExternalClusterGarbageCollection calls the Mondoo API to delete the stale assets of an external cluster.
+// They are older than the cluster's own schedule allows. Until it succeeds once, the stale assets the cluster
+// sent with the managed-by label of the operator's cluster are collected, too.
+func (n *DeploymentHandler) performExternalClusterGarbageCollection(
+	ctx context.Context, clusterUid string, cluster v1alpha2.ExternalCluster, status *v1alpha2.ExternalClusterStatus,
+) error {
+	schedule := cluster.Schedule
+	if schedule == "" {
+		schedule = n.Mondoo.Spec.KubernetesResources.Schedule
+	}
+	dateFilter := &mondooclient.DateFilter{
+		Timestamp:  time.Now().Add(-mondoo.GCOlderThan(schedule)).Format(time.RFC3339),
+		Comparison: mondooclient.Comparison_LESS_THAN,
+		Field:      mondooclient.DateFilterField_FILTER_LAST_UPDATED,
+	}
+	req := &mondooclient.GarbageCollectAssetsRequest{
+		ManagedBy:  mondoo.ManagedByExternalClusterLabel(clusterUid, cluster.Name),
+		DateFilter: dateFilter,
+	}
+
+	scan := k8s.ExternalClusterScan(cluster.Name)
+	result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, scan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+	if err != nil {
+		return err
+	}
+	results := []*v1alpha2.GarbageCollectionStatus{result}
+
+	if !status.LegacyAssetsCollected {
+		legacyReq := legacyExternalClusterRequest(clusterUid, cluster.Name)
+		legacyReq.DateFilter = dateFilter
+		legacyResult, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, scan, n.MondooOperatorConfig, n.MondooClientBuilder, legacyReq, logger)
+		if err != nil {
+			return fmt.Errorf("failed to collect the assets with the managed-by label of the operator's cluster: %w", err)
+		}
+		results = append(results, legacyResult)
+		status.LegacyAssetsCollected = legacyResult == nil || legacyResult.Result == v1alpha2.GarbageCollectionPurged
+	}
+	mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, scan, results)
+
+	logger.Info("Successfully performed garbage collection of external cluster assets", "cluster", cluster.Name)
+	return nil
+}
+
+// purgeRemovedExternalCluster deletes all assets of an external cluster that was removed from the spec, in the
+// space it sent them to. It reports whether the assets were purged; dry-run and confirmation threshold apply.
+func (n *DeploymentHandler) purgeRemovedExternalCluster(ctx context.Context, clusterUid string, cluster v1alpha2.ExternalClusterStatus) (bool, error) {
+	removed := mondoo.RemovedExternalClusterAuditConfig(*n.Mondoo, cluster)
+	req := &mondooclient.GarbageCollectAssetsRequest{ManagedBy: mondoo.ManagedByExternalClusterLabel(clusterUid, cluster.Name)}
+
+	scan := k8s.ExternalClusterScan(cluster.Name)
+	result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, &removed, scan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+	if err != nil {
+		return false, err
+	}
+	results := []*v1alpha2.GarbageCollectionStatus{result}
+	if !cluster.LegacyAssetsCollected {
+		legacyResult, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, &removed, scan, n.MondooOperatorConfig,
+			n.MondooClientBuilder, legacyExternalClusterRequest(clusterUid, cluster.Name), logger)
+		if err != nil {
+			return false, fmt.Errorf("failed to purge the assets with the managed-by label of the operator's cluster: %w", err)
+		}
+		results = append(results, legacyResult)
+	}
+
+	if !slices.ContainsFunc(results, func(r *v1alpha2.GarbageCollectionStatus) bool {
+		return r != nil && r.Result != v1alpha2.GarbageCollectionPurged
+	}) {
+		mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, scan, nil)
+		logger.Info("Purged the assets of the removed external cluster", "cluster", cluster.Name)
+		return true, nil
+	}
+	mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, scan, results)
+	return false, nil
+}
+
+// legacyExternalClusterRequest matches the assets an external cluster sent before external clusters had their own
+// managed-by label: they are managed by the operator's cluster and labeled with the name of the external cluster.
+func legacyExternalClusterRequest(clusterUid, clusterName string) *mondooclient.GarbageCollectAssetsRequest {
+	return &mondooclient.GarbageCollectAssetsRequest{
+		ManagedBy:       mondoo.ManagedByLabel(clusterUid),
+		PlatformRuntime: "k8s-cluster",
+		Labels:          map[string]string{"mondoo.com/cluster-name": clusterName},
+	}
+}
+
 // syncVaultKubeconfigSecret fetches credentials from Vault and writes a kubeconfig Secret.
 func (n *DeploymentHandler) syncVaultKubeconfigSecret(ctx context.Context, cluster v1alpha2.ExternalCluster) error {
 	if n.VaultTokenFetcher == nil {
@@ -747,6 +1098,10 @@ func (n *DeploymentHandler) cleanupStaleCronJobs(ctx context.Context) error {
 		if clusterName, ok := cronJobs.Items[i].Labels["cluster_name"]; ok && !configuredClusters[clusterName] {
 			continue
 		}
+		// cleanupSpaceCronJobs handles the CronJobs of routed spaces
+		if _, ok := cronJobs.Items[i].Labels[k8s.SpaceIDLabel]; ok {
+			continue
+		}
 		logger.Info("Deleting stale k8s scan CronJob", "name", cronJobs.Items[i].Name)
 		if err := k8s.DeleteIfExists(ctx, n.KubeClient, &cronJobs.Items[i]); err != nil {
 			return err
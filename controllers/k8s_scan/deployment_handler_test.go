@@ -16,6 +16,7 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -25,6 +26,7 @@ import (
 	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 	fakeMondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo/fake"
 	"go.mondoo.com/mondoo-operator/pkg/utils/test"
@@ -231,6 +233,181 @@ func (s *DeploymentHandlerSuite) TestReconcile_Disable() {
 	s.Equal(0, len(cronJobs.Items))
 }
 
+func (s *DeploymentHandlerSuite) TestReconcile_ScheduledScanReplacedByResourceWatcher() {
+	d := s.createDeploymentHandler()
+	mondooAuditConfig := &s.auditConfig
+	s.NoError(d.KubeClient.Create(s.ctx, mondooAuditConfig))
+
+	// Reconcile to create all resources
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	// Without a resync interval the CronJob is kept
+	d.Mondoo.Spec.KubernetesResources.ResourceWatcher.Enable = true
+	d.Mondoo.Spec.KubernetesResources.ResourceWatcher.ReplaceScheduledScan = true
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	cronJobs := &batchv1.CronJobList{}
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Equal(1, len(cronJobs.Items))
+
+	d.Mondoo.Spec.KubernetesResources.ResourceWatcher.ResyncInterval = metav1.Duration{Duration: 6 * time.Hour}
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Equal(0, len(cronJobs.Items))
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_NamespacePatterns() {
+	s.fakeClientBuilder = s.fakeClientBuilder.WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-dev"}},
+	)
+	s.auditConfig.Spec.Filtering.Namespaces.Include = []string{"team-*-prod"}
+	d := s.createDeploymentHandler()
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = ConfigMapName(s.auditConfig.Name)
+	configMap.Namespace = s.auditConfig.Namespace
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(configMap), configMap))
+	s.Contains(configMap.Data["inventory"], "namespaces: team-a-prod")
+
+	// Without a matching namespace, nothing is scanned instead of the whole cluster
+	d.Mondoo.Spec.Filtering.Namespaces.Include = []string{"team-b-*"}
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	cronJobs := &batchv1.CronJobList{}
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Empty(cronJobs.Items)
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_ExternalCluster_NamespacePatternsNotSupported() {
+	s.auditConfig.Spec.Filtering.Namespaces.Include = []string{"team-*"}
+	s.auditConfig.Spec.KubernetesResources.ExternalClusters = []mondoov1alpha2.ExternalCluster{{
+		Name:                "production",
+		KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "prod-kubeconfig"},
+	}}
+	d := s.createDeploymentHandler()
+
+	_, err := d.Reconcile(s.ctx)
+	s.ErrorContains(err, "namespace patterns and namespaceSelector are not supported for external clusters")
+
+	// Filtering with names for the external cluster overrides the global filtering
+	s.auditConfig.Spec.KubernetesResources.ExternalClusters[0].Filtering = &mondoov1alpha2.Filtering{}
+	s.NoError(ValidateExternalClusterFiltering(s.auditConfig.Spec.KubernetesResources.ExternalClusters[0], s.auditConfig))
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_AnnotationsFromNamespaceLabels() {
+	s.fakeClientBuilder = s.fakeClientBuilder.WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"cost-center": "cc-42"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	)
+	s.auditConfig.Spec.AnnotationsFromLabels = map[string]string{"cost-center": "namespace.labels['cost-center']"}
+	d := s.createDeploymentHandler()
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = ConfigMapName(s.auditConfig.Name)
+	configMap.Namespace = s.auditConfig.Namespace
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(configMap), configMap))
+	s.Contains(configMap.Data["inventory"], "namespaces-exclude: payments")
+	s.Contains(configMap.Data["inventory"], "namespaces: payments")
+	s.Contains(configMap.Data["inventory"], "cost-center: cc-42")
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_SpaceRouting() {
+	s.fakeClientBuilder = s.fakeClientBuilder.WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	)
+	s.auditConfig.Spec.SpaceRouting = []mondoov1alpha2.SpaceRoutingRule{
+		{SpaceID: "team-a", Namespaces: []string{"team-a-*"}},
+		{SpaceID: "team-b", Namespaces: []string{"team-b-*"}},
+	}
+	d := s.createDeploymentHandler()
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	// The default space keeps the cluster and the namespaces that are not routed
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName(s.auditConfig.Name), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(configMap), configMap))
+	s.Contains(configMap.Data["inventory"], "namespaces-exclude: team-a-prod")
+
+	// Spaces without namespaces get no CronJob
+	cronJobs := &batchv1.CronJobList{}
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Len(cronJobs.Items, 2)
+
+	spaceCronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: SpaceCronJobName(s.auditConfig.Name, "team-a"), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(spaceCronJob), spaceCronJob))
+	s.Equal("team-a", spaceCronJob.Labels[k8s.SpaceIDLabel])
+	sources := spaceCronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes[1].Projected.Sources
+	s.Equal(SpaceConfigMapName(s.auditConfig.Name, "team-a"), sources[0].ConfigMap.Name)
+	s.Equal(k8s.SpaceConfigSecretName(s.auditConfig.Name, "team-a"), sources[1].Secret.Name)
+
+	spaceConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: SpaceConfigMapName(s.auditConfig.Name, "team-a"), Namespace: s.auditConfig.Namespace}}
+	s.NoError(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(spaceConfigMap), spaceConfigMap))
+	s.Contains(spaceConfigMap.Data["inventory"], "namespaces: team-a-prod")
+	s.NotContains(spaceConfigMap.Data["inventory"], "- clusters")
+
+	// Removing the routing removes the CronJob and ConfigMap of the space
+	d.Mondoo.Spec.SpaceRouting = nil
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Len(cronJobs.Items, 1)
+	s.Equal(CronJobName(s.auditConfig.Name), cronJobs.Items[0].Name)
+	s.True(errors.IsNotFound(d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(spaceConfigMap), spaceConfigMap)))
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_InvalidDiscovery() {
+	d := s.createDeploymentHandler()
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	d.Mondoo.Spec.KubernetesResources.Discovery = &mondoov1alpha2.KubernetesResourcesDiscovery{
+		Exclude: []string{"secrets"},
+	}
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+
+	condition := mondoo.FindMondooAuditConditions(d.Mondoo.Status.Conditions, mondoov1alpha2.K8sResourcesScanningDegraded)
+	s.Require().NotNil(condition)
+	s.Equal(corev1.ConditionTrue, condition.Status)
+	s.Equal("KubernetesResourcesScanningInvalidConfig", condition.Reason)
+	s.Contains(condition.Message, "unsupported resource kinds in kubernetesResources.discovery: secrets")
+
+	// The existing scan is kept until the configuration is fixed
+	cronJobs := &batchv1.CronJobList{}
+	s.NoError(d.KubeClient.List(s.ctx, cronJobs))
+	s.Len(cronJobs.Items, 1)
+}
+
 func (s *DeploymentHandlerSuite) TestReconcile_CreateWithCustomSchedule() {
 	d := s.createDeploymentHandler()
 	mondooAuditConfig := &s.auditConfig
@@ -762,10 +939,13 @@ func (s *DeploymentHandlerSuite) TestReconcile_ExternalCluster_Cleanup() {
 	// Remove external cluster from config
 	d.Mondoo.Spec.KubernetesResources.ExternalClusters = nil
 
-	// Second reconcile - should clean up
+	// Second reconcile - should clean up. The cluster was never scanned, but its assets are purged, too, and
+	// the purge is retried as long as it fails.
 	result, err = d.Reconcile(s.ctx)
 	s.NoError(err)
-	s.True(result.IsZero())
+	s.Equal(removedClusterPurgeInterval, result.RequeueAfter)
+	s.Require().Len(d.Mondoo.Status.ExternalClusters, 1)
+	s.Equal("production", d.Mondoo.Status.ExternalClusters[0].Name)
 
 	// Verify CronJob was deleted
 	err = d.KubeClient.Get(s.ctx, client.ObjectKeyFromObject(externalCronJob), externalCronJob)
@@ -1088,7 +1268,7 @@ func TestValidateExternalClusterAuth(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateExternalClusterAuth(tt.cluster)
+			err := ValidateExternalClusterAuth(tt.cluster)
 			if tt.expectError {
 				if err == nil {
 					t.Errorf("expected error containing %q, got nil", tt.errorMsg)
@@ -1730,6 +1910,135 @@ func (s *DeploymentHandlerSuite) TestGarbageCollection_FailureStillUpdatesTimest
 	s.NotNil(d.Mondoo.Status.LastK8sResourceGarbageCollectionTime, "GC timestamp should be set even when GC fails")
 }
 
+func (s *DeploymentHandlerSuite) TestGarbageCollection_ExternalClusters() {
+	var reqs []mondooclient.GarbageCollectAssetsRequest
+	d := s.createDeploymentHandlerWithGCMock(func(ctx context.Context, req *mondooclient.GarbageCollectAssetsRequest) error {
+		reqs = append(reqs, *req)
+		return nil
+	})
+	d.Mondoo.Spec.KubernetesResources.ExternalClusters = []mondoov1alpha2.ExternalCluster{
+		{Name: "prod", SpaceID: "prod", Schedule: "0 0 * * *"},
+		{Name: "staging"},
+		{Name: "never-scanned"},
+	}
+
+	now := metav1.Now()
+	s.createScanCronJob(d, "prod", &now)
+	s.createScanCronJob(d, "staging", &now)
+	s.createScanCronJob(d, "never-scanned", nil)
+
+	d.garbageCollectIfNeeded(s.ctx, "abc")
+
+	// Every cluster is collected in its own space, under its own ManagedBy label and on its own schedule. The
+	// first time, the assets the cluster sent with the ManagedBy label of the operator's cluster are collected, too.
+	s.Require().Len(reqs, 4)
+	s.Equal(k8s.SpaceMrnPrefix+"prod", reqs[0].ScopeMrn)
+	s.Equal("mondoo-operator-external-prod-abc", reqs[0].ManagedBy)
+	s.Empty(reqs[0].PlatformRuntime)
+	prodOlderThan, err := time.Parse(time.RFC3339, reqs[0].DateFilter.Timestamp)
+	s.Require().NoError(err)
+	s.WithinDuration(time.Now().Add(-mondoo.GCOlderThan("0 0 * * *")), prodOlderThan, time.Minute)
+	s.Equal(k8s.SpaceMrnPrefix+"prod", reqs[1].ScopeMrn)
+	s.Equal("mondoo-operator-abc", reqs[1].ManagedBy)
+	s.Equal("k8s-cluster", reqs[1].PlatformRuntime)
+	s.Equal(map[string]string{"mondoo.com/cluster-name": "prod"}, reqs[1].Labels)
+	s.Equal(reqs[0].DateFilter, reqs[1].DateFilter)
+	s.Equal("//captain.api.mondoo.app/spaces/test", reqs[2].ScopeMrn)
+	s.Equal("mondoo-operator-external-staging-abc", reqs[2].ManagedBy)
+	s.Equal(map[string]string{"mondoo.com/cluster-name": "staging"}, reqs[3].Labels)
+
+	// Clusters are listed before their first scan, so their assets are purged if they are removed
+	s.Require().Len(d.Mondoo.Status.ExternalClusters, 3)
+	s.Equal("prod", d.Mondoo.Status.ExternalClusters[0].Name)
+	s.Equal("prod", d.Mondoo.Status.ExternalClusters[0].SpaceID)
+	s.Equal(s.auditConfig.Spec.MondooCredsSecretRef.Name, d.Mondoo.Status.ExternalClusters[0].MondooCredsSecretRef)
+	s.NotNil(d.Mondoo.Status.ExternalClusters[0].LastGarbageCollectionTime)
+	s.True(d.Mondoo.Status.ExternalClusters[0].LegacyAssetsCollected)
+	s.Equal("staging", d.Mondoo.Status.ExternalClusters[1].Name)
+	s.Equal("never-scanned", d.Mondoo.Status.ExternalClusters[2].Name)
+	s.Equal(s.auditConfig.Spec.MondooCredsSecretRef.Name, d.Mondoo.Status.ExternalClusters[2].MondooCredsSecretRef)
+	s.Nil(d.Mondoo.Status.ExternalClusters[2].LastGarbageCollectionTime)
+	s.False(d.Mondoo.Status.ExternalClusters[2].LegacyAssetsCollected)
+	s.Nil(d.Mondoo.Status.LastK8sResourceGarbageCollectionTime, "the local cluster wasn't scanned")
+
+	// Nothing is collected until the next successful scan
+	reqs = nil
+	d.garbageCollectIfNeeded(s.ctx, "abc")
+	s.Empty(reqs)
+
+	// The assets with the ManagedBy label of the operator's cluster are collected only once
+	d.Mondoo.Status.ExternalClusters[0].LastGarbageCollectionTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	d.garbageCollectIfNeeded(s.ctx, "abc")
+	s.Require().Len(reqs, 1)
+	s.Equal("mondoo-operator-external-prod-abc", reqs[0].ManagedBy)
+}
+
+func (s *DeploymentHandlerSuite) TestGarbageCollection_RemovedExternalCluster() {
+	var reqs []mondooclient.GarbageCollectAssetsRequest
+	gcErr := fmt.Errorf("API error")
+	d := s.createDeploymentHandlerWithGCMock(func(ctx context.Context, req *mondooclient.GarbageCollectAssetsRequest) error {
+		reqs = append(reqs, *req)
+		return gcErr
+	})
+	d.Mondoo.Spec.KubernetesResources.Enable = false
+	d.Mondoo.Status.ExternalClusters = []mondoov1alpha2.ExternalClusterStatus{
+		{Name: "prod", SpaceID: "prod", MondooCredsSecretRef: s.auditConfig.Spec.MondooCredsSecretRef.Name},
+	}
+	s.NoError(d.KubeClient.Create(s.ctx, &s.auditConfig))
+
+	// A failed purge is retried later
+	result, err := d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.Equal(removedClusterPurgeInterval, result.RequeueAfter)
+	s.Require().Len(reqs, 1)
+	s.Equal(k8s.SpaceMrnPrefix+"prod", reqs[0].ScopeMrn)
+	s.Equal("mondoo-operator-external-prod-"+s.clusterUID(d), reqs[0].ManagedBy)
+	s.Nil(reqs[0].DateFilter, "all assets of the removed cluster are purged")
+	s.Require().Len(d.Mondoo.Status.ExternalClusters, 1)
+	s.NotNil(d.Mondoo.Status.ExternalClusters[0].LastGarbageCollectionTime)
+
+	// Not before the retry interval passed
+	_, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.Len(reqs, 1)
+
+	// Once the assets are purged, the cluster is dropped from the status
+	gcErr = nil
+	d.Mondoo.Status.ExternalClusters[0].LastGarbageCollectionTime = &metav1.Time{Time: time.Now().Add(-removedClusterPurgeInterval)}
+	result, err = d.Reconcile(s.ctx)
+	s.NoError(err)
+	s.True(result.IsZero())
+	s.Require().Len(reqs, 3)
+	s.Equal("mondoo-operator-external-prod-"+s.clusterUID(d), reqs[1].ManagedBy)
+	// The assets the cluster sent with the ManagedBy label of the operator's cluster are purged, too
+	s.Equal(k8s.SpaceMrnPrefix+"prod", reqs[2].ScopeMrn)
+	s.Equal("mondoo-operator-"+s.clusterUID(d), reqs[2].ManagedBy)
+	s.Equal(map[string]string{"mondoo.com/cluster-name": "prod"}, reqs[2].Labels)
+	s.Nil(reqs[2].DateFilter)
+	s.Empty(d.Mondoo.Status.ExternalClusters)
+}
+
+// createScanCronJob creates the scan CronJob of an external cluster with the given last successful time.
+func (s *DeploymentHandlerSuite) createScanCronJob(d DeploymentHandler, clusterName string, lastSuccessfulTime *metav1.Time) {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ExternalClusterCronJobName(s.auditConfig.Name, clusterName),
+			Namespace: s.auditConfig.Namespace,
+			Labels:    ExternalClusterCronJobLabels(s.auditConfig, clusterName),
+		},
+	}
+	s.Require().NoError(d.KubeClient.Create(s.ctx, cronJob))
+	cronJob.Status.LastSuccessfulTime = lastSuccessfulTime
+	s.Require().NoError(d.KubeClient.Status().Update(s.ctx, cronJob))
+}
+
+// clusterUID returns the UID of the kube-system namespace the handler reads as cluster UID.
+func (s *DeploymentHandlerSuite) clusterUID(d DeploymentHandler) string {
+	uid, err := k8s.GetClusterUID(s.ctx, d.KubeClient, logger)
+	s.Require().NoError(err)
+	return uid
+}
+
 // createDeploymentHandlerWithGCMock creates a DeploymentHandler with a mock MondooClientBuilder
 // that captures calls to GarbageCollectAssets.
 func (s *DeploymentHandlerSuite) createDeploymentHandlerWithGCMock(gcFunc func(context.Context, *mondooclient.GarbageCollectAssetsRequest) error) DeploymentHandler {
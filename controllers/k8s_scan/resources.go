@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	// That's the mod k8s relies on https://github.com/kubernetes/kubernetes/blob/master/go.mod#L63
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/annotations"
 	"go.mondoo.com/mondoo-operator/pkg/constants"
 	"go.mondoo.com/mondoo-operator/pkg/feature_flags"
 	"go.mondoo.com/mondoo-operator/pkg/utils/gomemlimit"
@@ -47,6 +50,83 @@ var K8sDiscoveryTargets = []string{
 	"services",
 }
 
+// DefaultSpaceScanConfig returns the MondooAuditConfig the Kubernetes resources scan of the default space
+// is rendered from, given the resolved spec.spaceRouting. It returns false if nothing is left to scan in the
+// default space.
+func DefaultSpaceScanConfig(m v1alpha2.MondooAuditConfig, routing k8s.SpaceRouting) (v1alpha2.MondooAuditConfig, bool) {
+	scan := *m.DeepCopy()
+	scan.Spec.Filtering = routing.Default
+	if !routing.DefaultEmpty {
+		return scan, true
+	}
+	// All namespaces are routed to other spaces, only the cluster stays in the default space
+	if !slices.Contains(DiscoveryTargets(m), "clusters") {
+		return scan, false
+	}
+	scan.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{Include: []string{"clusters"}}
+	return scan, true
+}
+
+// SpaceScanConfig returns the MondooAuditConfig the Kubernetes resources scan of a routed space is rendered
+// from. The cluster asset stays in the default space. It returns false if no namespaced kind is discovered.
+func SpaceScanConfig(m v1alpha2.MondooAuditConfig, route k8s.SpaceRoute) (v1alpha2.MondooAuditConfig, bool) {
+	scan := k8s.SpaceAuditConfig(m, route)
+	targets := slices.DeleteFunc(DiscoveryTargets(m), func(t string) bool { return t == "clusters" })
+	scan.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{Include: targets}
+	return scan, len(targets) > 0
+}
+
+// DiscoveryTargets returns the discovery targets of the Kubernetes resources scans, applying the include and
+// exclude lists of spec.kubernetesResources.discovery. Unsupported kinds are ignored, see ValidateDiscovery.
+func DiscoveryTargets(m v1alpha2.MondooAuditConfig) []string {
+	d := m.Spec.KubernetesResources.Discovery
+	if d == nil {
+		return slices.Clone(K8sDiscoveryTargets)
+	}
+
+	normalize := func(kinds []string) []string {
+		normalized := make([]string, 0, len(kinds))
+		for _, k := range kinds {
+			normalized = append(normalized, strings.ToLower(strings.TrimSpace(k)))
+		}
+		return normalized
+	}
+	include, exclude := normalize(d.Include), normalize(d.Exclude)
+
+	// Keep the order of K8sDiscoveryTargets, so the inventory does not change with the order of the lists
+	targets := []string{}
+	for _, t := range K8sDiscoveryTargets {
+		if (len(include) == 0 || slices.Contains(include, t)) && !slices.Contains(exclude, t) {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// ValidateDiscovery validates that spec.kubernetesResources.discovery only lists kinds cnspec can discover,
+// and that it does not exclude all of them.
+func ValidateDiscovery(m v1alpha2.MondooAuditConfig) error {
+	d := m.Spec.KubernetesResources.Discovery
+	if d == nil {
+		return nil
+	}
+
+	var unsupported []string
+	for _, k := range slices.Concat(d.Include, d.Exclude) {
+		if !slices.Contains(K8sDiscoveryTargets, strings.ToLower(strings.TrimSpace(k))) {
+			unsupported = append(unsupported, k)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported resource kinds in kubernetesResources.discovery: %s (supported kinds are %s)",
+			strings.Join(unsupported, ", "), strings.Join(K8sDiscoveryTargets, ", "))
+	}
+	if len(DiscoveryTargets(m)) == 0 {
+		return fmt.Errorf("kubernetesResources.discovery excludes all resource kinds")
+	}
+	return nil
+}
+
 const (
 	// GarbageCollectOlderThan is the default duration for garbage collection of stale assets
 	GarbageCollectOlderThan = "2h"
@@ -159,7 +239,7 @@ func CronJob(image string, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOpe
 												},
 												{
 													Secret: &corev1.SecretProjection{
-														LocalObjectReference: k8s.ConfigSecretRef(*m),
+														LocalObjectReference: k8s.ConfigSecretRef(*m, k8s.KubernetesResourcesScan),
 														Items: []corev1.KeyToPath{{
 															Key:  "config",
 															Path: "mondoo.yml",
@@ -193,6 +273,33 @@ func CronJob(image string, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOpe
 		)
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&cronjob.Spec.JobTemplate.Spec.Template.Spec, cfg)
+
+	return cronjob
+}
+
+// SpaceCronJob creates the CronJob scanning the namespaces spec.spaceRouting routes to a space. It only
+// differs from the CronJob of the default space in its name, labels, inventory and credentials.
+func SpaceCronJob(image string, m *v1alpha2.MondooAuditConfig, route k8s.SpaceRoute, cfg v1alpha2.MondooOperatorConfig) *batchv1.CronJob {
+	routed := k8s.SpaceAuditConfig(*m, route)
+	cronjob := CronJob(image, &routed, cfg)
+
+	ls := SpaceCronJobLabels(*m, route.SpaceID)
+	cronjob.Name = SpaceCronJobName(m.Name, route.SpaceID)
+	cronjob.Labels = ls
+	cronjob.Spec.JobTemplate.Labels = ls
+	cronjob.Spec.JobTemplate.Spec.Template.Labels = ls
+	for _, v := range cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes {
+		if v.Projected == nil {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if source.ConfigMap != nil {
+				source.ConfigMap.Name = SpaceConfigMapName(m.Name, route.SpaceID)
+			}
+		}
+	}
 	return cronjob
 }
 
@@ -254,7 +361,7 @@ func ExternalClusterCronJob(image string, cluster v1alpha2.ExternalCluster, m *v
 						},
 						{
 							Secret: &corev1.SecretProjection{
-								LocalObjectReference: k8s.ConfigSecretRef(*m),
+								LocalObjectReference: k8s.ConfigSecretRef(*m, k8s.ExternalClusterScan(cluster.Name)),
 								Items: []corev1.KeyToPath{{
 									Key:  "config",
 									Path: "mondoo.yml",
@@ -273,177 +380,17 @@ func ExternalClusterCronJob(image string, cluster v1alpha2.ExternalCluster, m *v
 			ReadOnly:  true,
 			MountPath: "/etc/opt/mondoo/config",
 		},
-		{
-			Name:      "kubeconfig",
-			ReadOnly:  true,
-			MountPath: "/etc/opt/mondoo/kubeconfig",
-		},
 		{
 			Name:      "temp",
 			MountPath: "/tmp",
 		},
 	}
 
-	var initContainers []corev1.Container
-	serviceAccountName := ""
-	autoMountServiceAccountToken := ptr.To(false)
-
 	// Configure authentication method
-	switch {
-	case cluster.KubeconfigSecretRef != nil:
-		// Kubeconfig auth: mount the kubeconfig secret directly
-		volumes = append(volumes, corev1.Volume{
-			Name: "kubeconfig",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  cluster.KubeconfigSecretRef.Name,
-					DefaultMode: ptr.To(int32(0o440)),
-					Items: []corev1.KeyToPath{{
-						Key:  "kubeconfig",
-						Path: "kubeconfig",
-					}},
-				},
-			},
-		})
-
-	case cluster.ServiceAccountAuth != nil:
-		// SA auth: mount credentials secret + generated kubeconfig ConfigMap
-		volumes = append(
-			volumes,
-			corev1.Volume{
-				Name: "sa-credentials",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName:  cluster.ServiceAccountAuth.CredentialsSecretRef.Name,
-						DefaultMode: ptr.To(int32(0o440)),
-					},
-				},
-			},
-			corev1.Volume{
-				Name: "kubeconfig",
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: ExternalClusterSAKubeconfigName(m.Name, cluster.Name),
-						},
-					},
-				},
-			},
-		)
-		volumeMounts = append(volumeMounts, corev1.VolumeMount{
-			Name:      "sa-credentials",
-			ReadOnly:  true,
-			MountPath: "/etc/opt/mondoo/sa-credentials",
-		})
-
-	case cluster.WorkloadIdentity != nil:
-		// WIF auth: use WIF ServiceAccount, init container to generate kubeconfig
-		serviceAccountName = WIFServiceAccountName(m.Name, cluster.Name)
-		autoMountServiceAccountToken = ptr.To(true)
-
-		// Use emptyDir for kubeconfig since it's generated at runtime
-		volumes = append(volumes, corev1.Volume{
-			Name: "kubeconfig",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{},
-			},
-		})
-
-		// Update kubeconfig volume mount to be writable for init container
-		for i := range volumeMounts {
-			if volumeMounts[i].Name == "kubeconfig" {
-				volumeMounts[i].ReadOnly = false
-			}
-		}
-
-		initContainers = append(initContainers, wifInitContainer(cluster))
-
-		// AKS Workload Identity webhook uses a pod-level objectSelector matching
-		// the label "azure.workload.identity/use: true" to inject federated token
-		// env vars and projected volume. Add it to the pod template labels.
-		if cluster.WorkloadIdentity.Provider == v1alpha2.CloudProviderAKS {
-			ls["azure.workload.identity/use"] = "true"
-		}
-
-	case cluster.SPIFFEAuth != nil:
-		// SPIFFE auth: use sidecar to fetch certificates, generate kubeconfig
-		serviceAccountName = m.Spec.Scanner.ServiceAccountName
-		autoMountServiceAccountToken = ptr.To(true)
-
-		socketPath := cluster.SPIFFEAuth.SocketPath
-		if socketPath == "" {
-			socketPath = "/run/spire/sockets/agent.sock"
-		}
-
-		// Mount SPIRE agent socket from host
-		volumes = append(volumes, corev1.Volume{
-			Name: "spire-agent-socket",
-			VolumeSource: corev1.VolumeSource{
-				HostPath: &corev1.HostPathVolumeSource{
-					Path: filepath.Dir(socketPath),
-					Type: ptr.To(corev1.HostPathDirectory),
-				},
-			},
-		})
-
-		// Mount trust bundle for remote cluster CA
-		volumes = append(volumes, corev1.Volume{
-			Name: "trust-bundle",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  cluster.SPIFFEAuth.TrustBundleSecretRef.Name,
-					DefaultMode: ptr.To(int32(0o440)),
-				},
-			},
-		})
-
-		// EmptyDir for generated certificates
-		volumes = append(volumes, corev1.Volume{
-			Name: "spiffe-certs",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{
-					Medium: corev1.StorageMediumMemory,
-				},
-			},
-		})
-
-		// EmptyDir for generated kubeconfig
-		volumes = append(volumes, corev1.Volume{
-			Name: "kubeconfig",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: &corev1.EmptyDirVolumeSource{},
-			},
-		})
-
-		// Add volume mounts for SPIFFE certs and trust bundle to main container
-		volumeMounts = append(
-			volumeMounts,
-			corev1.VolumeMount{Name: "spiffe-certs", MountPath: "/etc/spiffe-certs", ReadOnly: true},
-			corev1.VolumeMount{Name: "trust-bundle", MountPath: "/etc/trust-bundle", ReadOnly: true},
-		)
-
-		// Update kubeconfig mount to be writable for init container
-		for i := range volumeMounts {
-			if volumeMounts[i].Name == "kubeconfig" {
-				volumeMounts[i].ReadOnly = false
-			}
-		}
-
-		initContainers = append(initContainers, spiffeInitContainer(cluster))
-
-	case cluster.VaultAuth != nil:
-		// Vault auth: operator fetches credentials and writes a kubeconfig Secret
-		volumes = append(volumes, corev1.Volume{
-			Name: "kubeconfig",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  VaultKubeconfigSecretName(m.Name, cluster.Name),
-					DefaultMode: ptr.To(int32(0o440)),
-					Items:       []corev1.KeyToPath{{Key: "kubeconfig", Path: "kubeconfig"}},
-				},
-			},
-		})
-	}
+	auth := ExternalClusterPodAuth(cluster, m)
+	volumes = append(volumes, auth.Volumes...)
+	volumeMounts = append(volumeMounts, auth.VolumeMounts...)
+	maps.Copy(ls, auth.PodLabels)
 
 	cronjob := &batchv1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
@@ -464,9 +411,9 @@ func ExternalClusterCronJob(image string, cluster v1alpha2.ExternalCluster, m *v
 						ObjectMeta: metav1.ObjectMeta{Labels: ls},
 						Spec: corev1.PodSpec{
 							RestartPolicy:                corev1.RestartPolicyNever,
-							AutomountServiceAccountToken: autoMountServiceAccountToken,
-							ServiceAccountName:           serviceAccountName,
-							InitContainers:               initContainers,
+							AutomountServiceAccountToken: auth.AutomountServiceAccountToken,
+							ServiceAccountName:           auth.ServiceAccountName,
+							InitContainers:               auth.InitContainers,
 							Containers: []corev1.Container{
 								{
 									Image:           image,
@@ -597,9 +544,208 @@ func ExternalClusterCronJob(image string, cluster v1alpha2.ExternalCluster, m *v
 		}
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&cronjob.Spec.JobTemplate.Spec.Template.Spec, cfg)
+
 	return cronjob
 }
 
+// ExternalClusterKubeconfigDir is the directory the kubeconfig for an external cluster is mounted to.
+const ExternalClusterKubeconfigDir = "/etc/opt/mondoo/kubeconfig"
+
+// ExternalClusterAuth holds the parts of a pod spec that give its containers access to an external cluster.
+// The kubeconfig for the cluster is available in ExternalClusterKubeconfigDir.
+type ExternalClusterAuth struct {
+	Volumes                      []corev1.Volume
+	VolumeMounts                 []corev1.VolumeMount
+	InitContainers               []corev1.Container
+	ServiceAccountName           string
+	AutomountServiceAccountToken *bool
+	// PodLabels must be added to the pod, e.g. for the AKS Workload Identity webhook.
+	PodLabels map[string]string
+}
+
+// ExternalClusterPodAuth returns the volumes, init containers and service account a pod needs to authenticate
+// against an external cluster. The pod spec must also have a "temp" volume mounted to /tmp.
+func ExternalClusterPodAuth(cluster v1alpha2.ExternalCluster, m *v1alpha2.MondooAuditConfig) ExternalClusterAuth {
+	auth := ExternalClusterAuth{
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "kubeconfig",
+				ReadOnly:  true,
+				MountPath: ExternalClusterKubeconfigDir,
+			},
+		},
+		AutomountServiceAccountToken: ptr.To(false),
+		PodLabels:                    map[string]string{},
+	}
+
+	switch {
+	case cluster.KubeconfigSecretRef != nil:
+		// Kubeconfig auth: mount the kubeconfig secret directly
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "kubeconfig",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  cluster.KubeconfigSecretRef.Name,
+					DefaultMode: ptr.To(int32(0o440)),
+					Items: []corev1.KeyToPath{{
+						Key:  "kubeconfig",
+						Path: "kubeconfig",
+					}},
+				},
+			},
+		})
+
+	case cluster.ServiceAccountAuth != nil:
+		// SA auth: mount credentials secret + generated kubeconfig ConfigMap
+		auth.Volumes = append(
+			auth.Volumes,
+			corev1.Volume{
+				Name: "sa-credentials",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName:  cluster.ServiceAccountAuth.CredentialsSecretRef.Name,
+						DefaultMode: ptr.To(int32(0o440)),
+					},
+				},
+			},
+			corev1.Volume{
+				Name: "kubeconfig",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: ExternalClusterSAKubeconfigName(m.Name, cluster.Name),
+						},
+					},
+				},
+			},
+		)
+		auth.VolumeMounts = append(auth.VolumeMounts, corev1.VolumeMount{
+			Name:      "sa-credentials",
+			ReadOnly:  true,
+			MountPath: "/etc/opt/mondoo/sa-credentials",
+		})
+
+	case cluster.WorkloadIdentity != nil:
+		// WIF auth: use WIF ServiceAccount, init container to generate kubeconfig
+		auth.ServiceAccountName = WIFServiceAccountName(m.Name, cluster.Name)
+		auth.AutomountServiceAccountToken = ptr.To(true)
+
+		// Use emptyDir for kubeconfig since it's generated at runtime
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "kubeconfig",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+
+		// Update kubeconfig volume mount to be writable for init container
+		for i := range auth.VolumeMounts {
+			if auth.VolumeMounts[i].Name == "kubeconfig" {
+				auth.VolumeMounts[i].ReadOnly = false
+			}
+		}
+
+		auth.InitContainers = append(auth.InitContainers, wifInitContainer(cluster))
+
+		// AKS Workload Identity webhook uses a pod-level objectSelector matching
+		// the label "azure.workload.identity/use: true" to inject federated token
+		// env vars and projected volume. Add it to the pod template labels.
+		if cluster.WorkloadIdentity.Provider == v1alpha2.CloudProviderAKS {
+			auth.PodLabels["azure.workload.identity/use"] = "true"
+		}
+
+	case cluster.SPIFFEAuth != nil:
+		// SPIFFE auth: use sidecar to fetch certificates, generate kubeconfig
+		auth.ServiceAccountName = m.Spec.Scanner.ServiceAccountName
+		auth.AutomountServiceAccountToken = ptr.To(true)
+
+		socketPath := cluster.SPIFFEAuth.SocketPath
+		if socketPath == "" {
+			socketPath = "/run/spire/sockets/agent.sock"
+		}
+
+		// Mount SPIRE agent socket from host
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "spire-agent-socket",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: filepath.Dir(socketPath),
+					Type: ptr.To(corev1.HostPathDirectory),
+				},
+			},
+		})
+
+		// Mount trust bundle for remote cluster CA
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "trust-bundle",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  cluster.SPIFFEAuth.TrustBundleSecretRef.Name,
+					DefaultMode: ptr.To(int32(0o440)),
+				},
+			},
+		})
+
+		// EmptyDir for generated certificates
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "spiffe-certs",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				},
+			},
+		})
+
+		// EmptyDir for generated kubeconfig
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "kubeconfig",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+
+		// Add volume mounts for SPIFFE certs and trust bundle to main container
+		auth.VolumeMounts = append(
+			auth.VolumeMounts,
+			corev1.VolumeMount{Name: "spiffe-certs", MountPath: "/etc/spiffe-certs", ReadOnly: true},
+			corev1.VolumeMount{Name: "trust-bundle", MountPath: "/etc/trust-bundle", ReadOnly: true},
+		)
+
+		// Update kubeconfig mount to be writable for init container
+		for i := range auth.VolumeMounts {
+			if auth.VolumeMounts[i].Name == "kubeconfig" {
+				auth.VolumeMounts[i].ReadOnly = false
+			}
+		}
+
+		auth.InitContainers = append(auth.InitContainers, spiffeInitContainer(cluster))
+
+	case cluster.VaultAuth != nil:
+		// Vault auth: operator fetches credentials and writes a kubeconfig Secret
+		auth.Volumes = append(auth.Volumes, corev1.Volume{
+			Name: "kubeconfig",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  VaultKubeconfigSecretName(m.Name, cluster.Name),
+					DefaultMode: ptr.To(int32(0o440)),
+					Items:       []corev1.KeyToPath{{Key: "kubeconfig", Path: "kubeconfig"}},
+				},
+			},
+		})
+	}
+
+	return auth
+}
+
+// ScheduledScanReplaced returns whether the resource watcher replaces the scheduled Kubernetes resources
+// scan of the operator's cluster.
+func ScheduledScanReplaced(m v1alpha2.MondooAuditConfig) bool {
+	rw := m.Spec.KubernetesResources.ResourceWatcher
+	return m.Spec.KubernetesResources.Enable && rw.Enable && rw.ReplaceScheduledScan && rw.ResyncInterval.Duration > 0
+}
+
 func CronJobLabels(m v1alpha2.MondooAuditConfig) map[string]string {
 	return map[string]string{
 		"app":       "mondoo-k8s-scan",
@@ -617,6 +763,12 @@ func ExternalClusterCronJobLabels(m v1alpha2.MondooAuditConfig, clusterName stri
 	}
 }
 
+func SpaceCronJobLabels(m v1alpha2.MondooAuditConfig, spaceID string) map[string]string {
+	ls := CronJobLabels(m)
+	ls[k8s.SpaceIDLabel] = spaceID
+	return ls
+}
+
 func CronJobName(prefix string) string {
 	return k8s.CronJobName("k8s-scan", prefix)
 }
@@ -625,10 +777,18 @@ func ExternalClusterCronJobName(prefix, clusterName string) string {
 	return k8s.CronJobNameWithCluster("k8s-scan", prefix, clusterName)
 }
 
+func SpaceCronJobName(prefix, spaceID string) string {
+	return k8s.CronJobNameWithCluster("k8s-scan", prefix, "space-"+spaceID)
+}
+
 func ConfigMapName(prefix string) string {
 	return fmt.Sprintf("%s%s", prefix, InventoryConfigMapBase)
 }
 
+func SpaceConfigMapName(prefix, spaceID string) string {
+	return fmt.Sprintf("%s%s-space-%s", prefix, InventoryConfigMapBase, spaceID)
+}
+
 func ExternalClusterConfigMapName(prefix, clusterName string) string {
 	return fmt.Sprintf("%s%s-%s", prefix, InventoryConfigMapBase, clusterName)
 }
@@ -883,6 +1043,36 @@ fi
 	}
 }
 
+// ExternalClusterKubeconfigRefreshInterval is how often long-running pods regenerate short-lived
+// kubeconfig credentials for external clusters.
+const ExternalClusterKubeconfigRefreshInterval = 30 * time.Minute
+
+// ExternalClusterKubeconfigRefreshContainer returns a sidecar that periodically regenerates the kubeconfig for
+// an external cluster. It is only needed by long-running pods whose credentials expire, which is the case for
+// WorkloadIdentity and SPIFFEAuth. For all other auth methods nil is returned.
+func ExternalClusterKubeconfigRefreshContainer(cluster v1alpha2.ExternalCluster) *corev1.Container {
+	var container corev1.Container
+	switch {
+	case cluster.WorkloadIdentity != nil:
+		container = wifInitContainer(cluster)
+	case cluster.SPIFFEAuth != nil:
+		container = spiffeInitContainer(cluster)
+	default:
+		return nil
+	}
+
+	// Rerun the init container script in a subshell, so a failed refresh keeps the previous kubeconfig
+	shell, script := container.Command[0], container.Command[2]
+	container.Name = "refresh-kubeconfig"
+	container.Command = []string{shell, "-c", fmt.Sprintf(`while true; do
+sleep %d
+(
+%s
+) || echo "Failed to refresh kubeconfig, retrying in the next interval"
+done`, int(ExternalClusterKubeconfigRefreshInterval.Seconds()), script)}
+	return &container
+}
+
 // spiffeInitContainer creates an init container that fetches SPIFFE certificates
 // and generates a kubeconfig for the remote cluster.
 //
@@ -1011,8 +1201,8 @@ kill $HELPER_PID 2>/dev/null || true
 	}
 }
 
-func ConfigMap(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) (*corev1.ConfigMap, error) {
-	inv, err := Inventory(integrationMRN, clusterUID, m, cfg)
+func ConfigMap(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, namespaceAnnotations map[string]map[string]string) (*corev1.ConfigMap, error) {
+	inv, err := Inventory(integrationMRN, clusterUID, m, cfg, namespaceAnnotations)
 	if err != nil {
 		return nil, err
 	}
@@ -1042,32 +1232,54 @@ func ExternalClusterConfigMap(integrationMRN, operatorClusterUID string, cluster
 	}, nil
 }
 
-func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) (string, error) {
+// Inventory returns the inventory of the Kubernetes resources scan of the operator's cluster. namespaceAnnotations
+// holds the annotations derived from the labels of the scanned namespaces. Each set of namespaces with the
+// same derived annotations is scanned by its own asset, since cnspec applies the annotations of an inventory
+// asset to all the assets it discovers.
+func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig, namespaceAnnotations map[string]map[string]string) (string, error) {
+	targets := DiscoveryTargets(m)
+	namespacedTargets := slices.DeleteFunc(slices.Clone(targets), func(t string) bool { return t == "clusters" })
+	groups := namespaceAnnotationGroups(namespaceAnnotations)
+	if len(namespacedTargets) == 0 {
+		groups = nil
+	}
+
+	// The annotated namespaces are removed from the cluster asset
+	include := slices.Clone(m.Spec.Filtering.Namespaces.Include)
+	exclude := slices.Clone(m.Spec.Filtering.Namespaces.Exclude)
+	for _, g := range groups {
+		if len(include) > 0 {
+			include = slices.DeleteFunc(include, func(ns string) bool { return slices.Contains(g.namespaces, ns) })
+		} else {
+			exclude = append(exclude, g.namespaces...)
+		}
+	}
+	slices.Sort(exclude)
+	exclude = slices.Compact(exclude)
+
+	var assets []*inventory.Asset
+	var derivedAnnotations []map[string]string
+	if len(include) == 0 && len(m.Spec.Filtering.Namespaces.Include) > 0 {
+		// All included namespaces are annotated. An empty include list would scan all namespaces.
+		if slices.Contains(targets, "clusters") {
+			assets = append(assets, inventoryAsset(clusterUID, nil, nil, []string{"clusters"}))
+			derivedAnnotations = append(derivedAnnotations, nil)
+		}
+	} else {
+		assets = append(assets, inventoryAsset(clusterUID, include, exclude, targets))
+		derivedAnnotations = append(derivedAnnotations, nil)
+	}
+	for _, g := range groups {
+		assets = append(assets, inventoryAsset(clusterUID, g.namespaces, nil, namespacedTargets))
+		derivedAnnotations = append(derivedAnnotations, g.annotations)
+	}
+
 	inv := &inventory.Inventory{
 		Metadata: &inventory.ObjectMeta{
 			Name: "mondoo-k8s-resources-inventory",
 		},
 		Spec: &inventory.InventorySpec{
-			Assets: []*inventory.Asset{
-				{
-					Connections: []*inventory.Config{
-						{
-							Type: "k8s",
-							Options: map[string]string{
-								"namespaces":         strings.Join(m.Spec.Filtering.Namespaces.Include, ","),
-								"namespaces-exclude": strings.Join(m.Spec.Filtering.Namespaces.Exclude, ","),
-							},
-							Discover: &inventory.Discovery{
-								Targets: K8sDiscoveryTargets,
-							},
-						},
-					},
-					Labels: map[string]string{
-						"k8s.mondoo.com/kind": "cluster",
-					},
-					ManagedBy: mondoo.ManagedByLabel(clusterUID),
-				},
-			},
+			Assets: assets,
 		},
 	}
 
@@ -1077,9 +1289,9 @@ func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig,
 		}
 	}
 
-	if cfg.Spec.ContainerProxy != nil {
+	if containerProxy := k8s.ContainerProxyURL(cfg); containerProxy != nil {
 		for i := range inv.Spec.Assets {
-			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *cfg.Spec.ContainerProxy
+			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *containerProxy
 		}
 	}
 
@@ -1091,6 +1303,9 @@ func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig,
 		}
 	}
 	for i := range inv.Spec.Assets {
+		if len(derivedAnnotations[i]) > 0 {
+			inv.Spec.Assets[i].AddAnnotations(derivedAnnotations[i])
+		}
 		inv.Spec.Assets[i].AddAnnotations(constants.AuditConfigAnnotations(m.Name, m.Namespace))
 	}
 
@@ -1102,13 +1317,63 @@ func Inventory(integrationMRN, clusterUID string, m v1alpha2.MondooAuditConfig,
 	return string(invBytes), nil
 }
 
+// inventoryAsset returns an asset of the Kubernetes resources scan of the operator's cluster.
+func inventoryAsset(clusterUID string, include, exclude, targets []string) *inventory.Asset {
+	return &inventory.Asset{
+		Connections: []*inventory.Config{
+			{
+				Type: "k8s",
+				Options: map[string]string{
+					"namespaces":         strings.Join(include, ","),
+					"namespaces-exclude": strings.Join(exclude, ","),
+				},
+				Discover: &inventory.Discovery{
+					Targets: targets,
+				},
+			},
+		},
+		Labels: map[string]string{
+			"k8s.mondoo.com/kind": "cluster",
+		},
+		ManagedBy: mondoo.ManagedByLabel(clusterUID),
+	}
+}
+
+type namespaceAnnotationGroup struct {
+	namespaces  []string
+	annotations map[string]string
+}
+
+// namespaceAnnotationGroups groups the namespaces by their derived annotations. Namespaces without derived
+// annotations are left out. The groups are sorted by their first namespace, so the inventory is stable.
+func namespaceAnnotationGroups(namespaceAnnotations map[string]map[string]string) []namespaceAnnotationGroup {
+	byKey := map[string]*namespaceAnnotationGroup{}
+	for _, ns := range slices.Sorted(maps.Keys(namespaceAnnotations)) {
+		derived := namespaceAnnotations[ns]
+		if len(derived) == 0 {
+			continue
+		}
+		key := strings.Join(annotations.AnnotationArgs(derived), "\x00")
+		if g, ok := byKey[key]; ok {
+			g.namespaces = append(g.namespaces, ns)
+			continue
+		}
+		byKey[key] = &namespaceAnnotationGroup{namespaces: []string{ns}, annotations: derived}
+	}
+
+	groups := make([]namespaceAnnotationGroup, 0, len(byKey))
+	for _, g := range byKey {
+		groups = append(groups, *g)
+	}
+	slices.SortFunc(groups, func(a, b namespaceAnnotationGroup) int { return strings.Compare(a.namespaces[0], b.namespaces[0]) })
+	return groups
+}
+
 func ExternalClusterInventory(integrationMRN, operatorClusterUID string, cluster v1alpha2.ExternalCluster, m v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) (string, error) {
-	filtering := externalClusterFiltering(cluster, m)
+	filtering := ExternalClusterFiltering(cluster, m)
 
 	// Determine discovery targets based on whether container image scanning is enabled
-	// Make a copy to avoid mutating the shared slice
-	targets := make([]string, len(K8sDiscoveryTargets))
-	copy(targets, K8sDiscoveryTargets)
+	targets := DiscoveryTargets(m)
 	if cluster.ContainerImageScanning {
 		targets = append(targets, "container-images")
 	}
@@ -1149,7 +1414,7 @@ func ExternalClusterInventory(integrationMRN, operatorClusterUID string, cluster
 						"mondoo.com/cluster-name":  cluster.Name,
 						"mondoo.com/external-scan": "true",
 					},
-					ManagedBy: mondoo.ManagedByLabel(operatorClusterUID),
+					ManagedBy: mondoo.ManagedByExternalClusterLabel(operatorClusterUID, cluster.Name),
 				},
 			},
 		},
@@ -1161,9 +1426,9 @@ func ExternalClusterInventory(integrationMRN, operatorClusterUID string, cluster
 		}
 	}
 
-	if cfg.Spec.ContainerProxy != nil {
+	if containerProxy := k8s.ContainerProxyURL(cfg); containerProxy != nil {
 		for i := range inv.Spec.Assets {
-			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *cfg.Spec.ContainerProxy
+			inv.Spec.Assets[i].Connections[0].Options["container-proxy"] = *containerProxy
 		}
 	}
 
@@ -1188,7 +1453,8 @@ func ExternalClusterInventory(integrationMRN, operatorClusterUID string, cluster
 	return string(invBytes), nil
 }
 
-func externalClusterFiltering(cluster v1alpha2.ExternalCluster, m v1alpha2.MondooAuditConfig) v1alpha2.Filtering {
+// ExternalClusterFiltering returns the namespace filtering for an external cluster, falling back to the global filtering.
+func ExternalClusterFiltering(cluster v1alpha2.ExternalCluster, m v1alpha2.MondooAuditConfig) v1alpha2.Filtering {
 	if cluster.Filtering != nil {
 		return *cluster.Filtering
 	}
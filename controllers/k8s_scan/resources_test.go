@@ -17,6 +17,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
 	"go.mondoo.com/mql/v13/providers-sdk/v1/inventory"
 )
 
@@ -47,7 +48,7 @@ func TestInventory_WithAnnotations(t *testing.T) {
 		},
 	}
 
-	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{})
+	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{}, nil)
 	require.NoError(t, err, "unexpected error generating inventory")
 
 	var inv inventory.Inventory
@@ -85,6 +86,7 @@ func TestExternalClusterInventory_WithAnnotations(t *testing.T) {
 	for _, asset := range inv.Spec.Assets {
 		assert.Equal(t, "staging", asset.Annotations["env"], "asset %s missing env annotation", asset.Name)
 		assert.Equal(t, "security", asset.Annotations["team"], "asset %s missing team annotation", asset.Name)
+		assert.Equal(t, "mondoo-operator-external-remote-cluster-"+testClusterUID, asset.ManagedBy)
 	}
 }
 
@@ -448,7 +450,7 @@ func TestInventory_WithContainerProxy(t *testing.T) {
 		},
 	}
 
-	invStr, err := Inventory("", testClusterUID, auditConfig, cfg)
+	invStr, err := Inventory("", testClusterUID, auditConfig, cfg, nil)
 	require.NoError(t, err)
 
 	var inv inventory.Inventory
@@ -463,7 +465,7 @@ func TestInventory_WithoutContainerProxy(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client"},
 	}
 
-	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{})
+	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{}, nil)
 	require.NoError(t, err)
 
 	var inv inventory.Inventory
@@ -616,6 +618,129 @@ func TestExternalClusterInventory_NoRepositoryFiltersWithoutContainerImageScanni
 	assert.False(t, hasExclude, "should not have images-exclude when container image scanning is disabled")
 }
 
+func TestDiscoveryTargets(t *testing.T) {
+	auditConfig := *testAuditConfig()
+	assert.Equal(t, K8sDiscoveryTargets, DiscoveryTargets(auditConfig))
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"Deployments", "clusters", " pods "},
+	}
+	assert.Equal(t, []string{"clusters", "pods", "deployments"}, DiscoveryTargets(auditConfig))
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Exclude: []string{"jobs", "replicasets"},
+	}
+	targets := DiscoveryTargets(auditConfig)
+	assert.NotContains(t, targets, "jobs")
+	assert.NotContains(t, targets, "replicasets")
+	assert.Len(t, targets, len(K8sDiscoveryTargets)-2)
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"pods", "deployments"},
+		Exclude: []string{"pods"},
+	}
+	assert.Equal(t, []string{"deployments"}, DiscoveryTargets(auditConfig))
+}
+
+func TestValidateDiscovery(t *testing.T) {
+	auditConfig := *testAuditConfig()
+	assert.NoError(t, ValidateDiscovery(auditConfig))
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"Pods", "deployments"},
+	}
+	assert.NoError(t, ValidateDiscovery(auditConfig))
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"pods", "secrets"},
+		Exclude: []string{"configmaps"},
+	}
+	assert.ErrorContains(t, ValidateDiscovery(auditConfig), "unsupported resource kinds in kubernetesResources.discovery: secrets, configmaps")
+
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"pods"},
+		Exclude: []string{"pods"},
+	}
+	assert.ErrorContains(t, ValidateDiscovery(auditConfig), "excludes all resource kinds")
+}
+
+func TestInventory_WithDiscovery(t *testing.T) {
+	auditConfig := *testAuditConfig()
+	auditConfig.Spec.KubernetesResources.Discovery = &v1alpha2.KubernetesResourcesDiscovery{
+		Include: []string{"clusters", "deployments"},
+	}
+
+	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{}, nil)
+	require.NoError(t, err)
+
+	var inv inventory.Inventory
+	require.NoError(t, yaml.Unmarshal([]byte(invStr), &inv))
+	require.NotEmpty(t, inv.Spec.Assets)
+	assert.Equal(t, []string{"clusters", "deployments"}, inv.Spec.Assets[0].Connections[0].Discover.Targets)
+
+	cluster := v1alpha2.ExternalCluster{Name: "remote-cluster", ContainerImageScanning: true}
+	invStr, err = ExternalClusterInventory("", testClusterUID, cluster, auditConfig, v1alpha2.MondooOperatorConfig{})
+	require.NoError(t, err)
+
+	inv = inventory.Inventory{}
+	require.NoError(t, yaml.Unmarshal([]byte(invStr), &inv))
+	require.NotEmpty(t, inv.Spec.Assets)
+	assert.Equal(t, []string{"clusters", "deployments", "container-images"}, inv.Spec.Assets[0].Connections[0].Discover.Targets)
+}
+
+func TestInventory_WithNamespaceAnnotations(t *testing.T) {
+	auditConfig := *testAuditConfig()
+	auditConfig.Spec.Annotations = map[string]string{"env": "prod"}
+	auditConfig.Spec.Filtering.Namespaces.Exclude = []string{"kube-system"}
+	namespaceAnnotations := map[string]map[string]string{
+		"payments":     {"cost-center": "cc-42"},
+		"payments-dev": {"cost-center": "cc-42"},
+		"web":          {"cost-center": "cc-7"},
+	}
+
+	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{}, namespaceAnnotations)
+	require.NoError(t, err)
+
+	var inv inventory.Inventory
+	require.NoError(t, yaml.Unmarshal([]byte(invStr), &inv))
+	require.Len(t, inv.Spec.Assets, 3)
+
+	// The cluster asset scans everything except the annotated namespaces
+	cluster := inv.Spec.Assets[0]
+	assert.Equal(t, "kube-system,payments,payments-dev,web", cluster.Connections[0].Options["namespaces-exclude"])
+	assert.Equal(t, K8sDiscoveryTargets, cluster.Connections[0].Discover.Targets)
+	assert.Equal(t, "prod", cluster.Annotations["env"])
+	assert.NotContains(t, cluster.Annotations, "cost-center")
+
+	payments := inv.Spec.Assets[1]
+	assert.Equal(t, "payments,payments-dev", payments.Connections[0].Options["namespaces"])
+	assert.NotContains(t, payments.Connections[0].Discover.Targets, "clusters")
+	assert.Equal(t, "cc-42", payments.Annotations["cost-center"])
+	assert.Equal(t, "prod", payments.Annotations["env"])
+	assert.Equal(t, "mondoo-client", payments.Annotations[constants.MondooAuditConfigAnnotation])
+
+	web := inv.Spec.Assets[2]
+	assert.Equal(t, "web", web.Connections[0].Options["namespaces"])
+	assert.Equal(t, "cc-7", web.Annotations["cost-center"])
+}
+
+func TestInventory_WithNamespaceAnnotations_AllIncludedAnnotated(t *testing.T) {
+	auditConfig := *testAuditConfig()
+	auditConfig.Spec.Filtering.Namespaces.Include = []string{"payments"}
+	namespaceAnnotations := map[string]map[string]string{"payments": {"cost-center": "cc-42"}}
+
+	invStr, err := Inventory("", testClusterUID, auditConfig, v1alpha2.MondooOperatorConfig{}, namespaceAnnotations)
+	require.NoError(t, err)
+
+	var inv inventory.Inventory
+	require.NoError(t, yaml.Unmarshal([]byte(invStr), &inv))
+	require.Len(t, inv.Spec.Assets, 2)
+
+	// An empty include list would scan all namespaces, so the cluster asset only discovers the cluster
+	assert.Equal(t, []string{"clusters"}, inv.Spec.Assets[0].Connections[0].Discover.Targets)
+	assert.Equal(t, "payments", inv.Spec.Assets[1].Connections[0].Options["namespaces"])
+}
+
 func externalClusterInventoryOptions(t *testing.T, auditConfig v1alpha2.MondooAuditConfig, cluster v1alpha2.ExternalCluster) map[string]string {
 	t.Helper()
 
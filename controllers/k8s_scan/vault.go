@@ -7,13 +7,13 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"strings"
 	"time"
 
 	vault "github.com/hashicorp/vault-client-go"
 	"github.com/hashicorp/vault-client-go/schema"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,44 +25,9 @@ type VaultTokenFetcher func(ctx context.Context, saToken string, config v1alpha2
 
 // DefaultVaultTokenFetcher is the production implementation that uses vault-client-go.
 func DefaultVaultTokenFetcher(ctx context.Context, saToken string, config v1alpha2.VaultAuthConfig, vaultCACert []byte) (string, error) {
-	// Build client options
-	opts := []vault.ClientOption{
-		vault.WithAddress(config.VaultAddr),
-	}
-	if len(vaultCACert) > 0 {
-		opts = append(opts, vault.WithTLS(vault.TLSConfiguration{
-			ServerCertificate: vault.ServerCertificateEntry{
-				FromBytes: vaultCACert,
-			},
-		}))
-	}
-
-	client, err := vault.New(opts...)
+	client, err := k8s.NewVaultClient(ctx, config.VaultAddr, config.AuthPath, config.AuthRole, saToken, vaultCACert)
 	if err != nil {
-		return "", fmt.Errorf("failed to create Vault client: %w", err)
-	}
-
-	// Authenticate to Vault using Kubernetes auth
-	authPath := config.AuthPath
-	if authPath == "" {
-		authPath = "auth/kubernetes"
-	}
-	// The library prepends "auth/" automatically, so strip it if present
-	authMount := strings.TrimPrefix(authPath, "auth/")
-
-	loginResp, err := client.Auth.KubernetesLogin(ctx, schema.KubernetesLoginRequest{
-		Jwt:  saToken,
-		Role: config.AuthRole,
-	}, vault.WithMountPath(authMount))
-	if err != nil {
-		return "", fmt.Errorf("vault Kubernetes auth login failed: %w", err)
-	}
-	if loginResp == nil || loginResp.Auth == nil {
-		return "", fmt.Errorf("vault Kubernetes auth login returned empty response")
-	}
-
-	if err := client.SetToken(loginResp.Auth.ClientToken); err != nil {
-		return "", fmt.Errorf("failed to set Vault token: %w", err)
+		return "", err
 	}
 
 	// Request credentials from Vault's Kubernetes secrets engine
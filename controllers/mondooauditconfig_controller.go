@@ -5,7 +5,9 @@ package controllers
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -21,12 +23,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
@@ -45,6 +51,17 @@ import (
 
 const finalizerString = "k8s.mondoo.com/delete"
 
+const (
+	// assetPurgeTimeout is how long the assets of a deleted MondooAuditConfig with deletionPolicy Purge are
+	// tried to be purged before they are retained
+	assetPurgeTimeout = 5 * time.Minute
+	// assetPurgeRetryInterval is the time between the attempts to purge the assets
+	assetPurgeRetryInterval = 30 * time.Second
+)
+
+// defaultCredentialsCheckInterval is the time between two validations of the Mondoo credentials
+const defaultCredentialsCheckInterval = time.Hour
+
 // refreshCacheEntry holds cached RefreshAssetScores results to avoid
 // redundant API calls during reconcile cascades.
 type refreshCacheEntry struct {
@@ -59,6 +76,7 @@ type MondooAuditConfigReconciler struct {
 	ContainerImageResolver mondoo.ContainerImageResolver
 	StatusReporter         *status.StatusReporter
 	RunningOnOpenShift     bool
+	Recorder               events.EventRecorder
 
 	refreshMu    sync.Mutex
 	refreshCache map[types.NamespacedName]*refreshCacheEntry
@@ -86,9 +104,12 @@ var MondooClientBuilder = mondooclient.NewClient
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=create;update;delete
 // Need to be able to check for the existence of Secrets with tokens, Mondoo service accounts, and private image pull secrets without asking for permission to read all Secrets
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+// Need to be able to watch the referenced Secrets for changes. Only their metadata is cached, see SetupWithManager
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=list;watch
 // Need to be able to manage ServiceAccounts for external cluster workload identity federation
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -163,6 +184,12 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		// Any other Reconcile() loops that need custom cleanup when the MondooAuditConfig is being
 		// deleted should be called here
 
+		if mondooAuditConfig.Spec.DeletionPolicy == v1alpha2.DeletionPolicyPurge && controllerutil.ContainsFinalizer(mondooAuditConfig, finalizerString) {
+			if requeueAfter := r.purgeAssets(ctx, mondooAuditConfig, config, log); requeueAfter > 0 {
+				return ctrl.Result{RequeueAfter: requeueAfter}, nil
+			}
+		}
+
 		r.refreshMu.Lock()
 		delete(r.refreshCache, req.NamespacedName)
 		r.refreshMu.Unlock()
@@ -254,17 +281,21 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	// Validate annotations before using them in inventory or CLI args.
 	// Set a degraded condition so users can see the problem via kubectl describe.
-	if err := annotations.Validate(mondooAuditConfig.Spec.Annotations); err != nil {
+	annotationsErr := annotations.Validate(mondooAuditConfig.Spec.Annotations)
+	if annotationsErr == nil {
+		annotationsErr = annotations.ValidateFromLabels(mondooAuditConfig.Spec.AnnotationsFromLabels)
+	}
+	if annotationsErr != nil {
 		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
 			mondooAuditConfig.Status.Conditions,
 			v1alpha2.MondooOperatorDegraded,
 			corev1.ConditionTrue,
 			"InvalidAnnotations",
-			fmt.Sprintf("Invalid annotations in MondooAuditConfig: %s", err),
+			fmt.Sprintf("Invalid annotations in MondooAuditConfig: %s", annotationsErr),
 			mondoo.UpdateConditionIfReasonOrMessageChange,
 			nil, "",
 		)
-		log.Error(err, "invalid annotations in MondooAuditConfig, skipping reconciliation")
+		log.Error(annotationsErr, "invalid annotations in MondooAuditConfig, skipping reconciliation")
 		return ctrl.Result{}, nil
 	}
 	// Clear any previous annotation validation error
@@ -280,14 +311,121 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		)
 	}
 
+	if err := k8s.ValidateMondooCredsSource(*mondooAuditConfig); err != nil {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionTrue,
+			"InvalidCredsSource",
+			fmt.Sprintf("Invalid credentials source: %s", err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		log.Error(err, "invalid credentials source, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+	if cond := mondoo.FindMondooAuditConditions(mondooAuditConfig.Status.Conditions, v1alpha2.MondooOperatorDegraded); cond != nil && cond.Reason == "InvalidCredsSource" {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionFalse,
+			"CredsSourceValid",
+			"Credentials source is valid",
+			mondoo.UpdateConditionAlways,
+			nil, "",
+		)
+	}
+
+	// The CA bundle and the client certificate for the Mondoo API are mounted into all workloads,
+	// so they have to be valid before any of them is created. The Secrets are not watched, so retry
+	// until they are fixed.
+	if _, err := mondoo.APITLSConfig(ctx, r.Client, mondooAuditConfig.Namespace, config); err != nil {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionTrue,
+			"InvalidAPITLS",
+			fmt.Sprintf("Invalid TLS configuration for the Mondoo API: %s", err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		log.Error(err, "invalid TLS configuration for the Mondoo API, skipping reconciliation")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	if cond := mondoo.FindMondooAuditConditions(mondooAuditConfig.Status.Conditions, v1alpha2.MondooOperatorDegraded); cond != nil && cond.Reason == "InvalidAPITLS" {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionFalse,
+			"APITLSValid",
+			"TLS configuration for the Mondoo API is valid",
+			mondoo.UpdateConditionAlways,
+			nil, "",
+		)
+	}
+
+	// Proxies from Secrets are referenced by all workloads, so the Secrets have to exist before any of them is created
+	if _, err := k8s.ResolveProxyURLs(ctx, r.Client, mondooAuditConfig.Namespace, config); err != nil {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionTrue,
+			"InvalidProxy",
+			fmt.Sprintf("Invalid proxy configuration: %s", err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		log.Error(err, "invalid proxy configuration, skipping reconciliation")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	if cond := mondoo.FindMondooAuditConditions(mondooAuditConfig.Status.Conditions, v1alpha2.MondooOperatorDegraded); cond != nil && cond.Reason == "InvalidProxy" {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionFalse,
+			"ProxyValid",
+			"Proxy configuration is valid",
+			mondoo.UpdateConditionAlways,
+			nil, "",
+		)
+	}
+
 	// If spec.MondooTokenSecretRef != "" and the Secret referenced in spec.MondooCredsSecretRef
 	// does not exist, then attempt to trade the token for a Mondoo service account and save it
 	// in the Secret referenced in .spec.MondooCredsSecretRef
-	if reconcileError = r.exchangeTokenForServiceAccount(ctx, mondooAuditConfig, config, log); reconcileError != nil {
+	credsCreated, reconcileError := r.exchangeTokenForServiceAccount(ctx, mondooAuditConfig, config, log)
+	if reconcileError != nil {
 		log.Error(reconcileError, "errors while checking if Mondoo service account needs creating")
 		return ctrl.Result{}, reconcileError
 	}
 
+	credentialsCheckAfter := r.checkCredentials(ctx, mondooAuditConfig, config, credsCreated, log)
+
+	// Missing or malformed Secrets don't stop the reconciliation, the workloads that use them are
+	// rolled once the Secrets are fixed
+	if err := k8s.ValidateReferencedSecrets(ctx, r.Client, *mondooAuditConfig); err != nil {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.ReferencedSecretsDegraded,
+			corev1.ConditionTrue,
+			"ReferencedSecretsInvalid",
+			fmt.Sprintf("Invalid Secrets referenced by the MondooAuditConfig: %s", err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		log.Error(err, "invalid Secrets referenced by the MondooAuditConfig")
+	} else if cond := mondoo.FindMondooAuditConditions(mondooAuditConfig.Status.Conditions, v1alpha2.ReferencedSecretsDegraded); cond != nil && cond.Status == corev1.ConditionTrue {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.ReferencedSecretsDegraded,
+			corev1.ConditionFalse,
+			"ReferencedSecretsValid",
+			"Referenced Secrets are valid",
+			mondoo.UpdateConditionAlways,
+			nil, "",
+		)
+	}
+
 	// When spaceId is set, create a derived Secret with scope_mrn injected so cnspec
 	// routes assets to the specified space instead of the SA's default space.
 	if reconcileError = k8s.SyncConfigOverrideSecret(ctx, r.Client, mondooAuditConfig); reconcileError != nil {
@@ -295,6 +433,42 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, reconcileError
 	}
 
+	// Scans that override the space or the credentials get their own derived Secret
+	if reconcileError = k8s.SyncScanConfigSecrets(ctx, r.Client, mondooAuditConfig); reconcileError != nil {
+		log.Error(reconcileError, "failed to sync config override secrets of scan types")
+		return ctrl.Result{}, reconcileError
+	}
+
+	// Each space of spec.spaceRouting gets its own derived Secret
+	if err := k8s.ValidateSpaceRouting(*mondooAuditConfig); err != nil {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionTrue,
+			"InvalidSpaceRouting",
+			fmt.Sprintf("Invalid spaceRouting in MondooAuditConfig: %s", err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		log.Error(err, "invalid spaceRouting in MondooAuditConfig, skipping reconciliation")
+		return ctrl.Result{}, nil
+	}
+	if cond := mondoo.FindMondooAuditConditions(mondooAuditConfig.Status.Conditions, v1alpha2.MondooOperatorDegraded); cond != nil && cond.Reason == "InvalidSpaceRouting" {
+		mondooAuditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			mondooAuditConfig.Status.Conditions,
+			v1alpha2.MondooOperatorDegraded,
+			corev1.ConditionFalse,
+			"SpaceRoutingValid",
+			"Space routing is valid",
+			mondoo.UpdateConditionAlways,
+			nil, "",
+		)
+	}
+	if reconcileError = k8s.SyncSpaceConfigSecrets(ctx, r.Client, mondooAuditConfig); reconcileError != nil {
+		log.Error(reconcileError, "failed to sync config secrets for spaceRouting")
+		return ctrl.Result{}, reconcileError
+	}
+
 	nodes := nodes.DeploymentHandler{
 		Mondoo:                 mondooAuditConfig,
 		KubeClient:             r.Client,
@@ -308,6 +482,9 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	// (e.g. image resolution timeout) must not block the others.
 	var firstError error
 	finalResult := ctrl.Result{Requeue: true, RequeueAfter: time.Hour * 24 * 7}
+	if credentialsCheckAfter > 0 && credentialsCheckAfter < finalResult.RequeueAfter {
+		finalResult.RequeueAfter = credentialsCheckAfter
+	}
 	collect := func(result ctrl.Result, err error, msg string) {
 		if err != nil {
 			log.Error(err, msg+", continuing with other scan types")
@@ -354,6 +531,30 @@ func (r *MondooAuditConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	result, err = resourceWatcher.Reconcile(ctx)
 	collect(result, err, "Failed to set up resource watcher")
 
+	if mondooAuditConfig.Spec.KubernetesResources.Enable || mondooAuditConfig.Spec.Containers.Enable {
+		optOut, err := k8s.CountScanOptOuts(ctx, r.Client)
+		if err != nil {
+			log.Error(err, "Failed to count the resources that opted out of scanning")
+		} else {
+			mondooAuditConfig.Status.ScanOptOut = optOut
+		}
+	} else {
+		mondooAuditConfig.Status.ScanOptOut = nil
+	}
+
+	mondooAuditConfig.Status.SpaceRouting = nil
+	if len(mondooAuditConfig.Spec.SpaceRouting) > 0 {
+		routing := k8s.SpaceRouting{}
+		filtering, err := k8s.ResolveNamespaceFiltering(ctx, r.Client, mondooAuditConfig.Spec.Filtering)
+		if err == nil {
+			routing, err = k8s.ResolveSpaceRouting(ctx, r.Client, *mondooAuditConfig, filtering)
+		}
+		if err != nil && !stderrors.Is(err, k8s.ErrNoMatchingNamespaces) {
+			log.Error(err, "Failed to resolve spaceRouting for the status")
+		}
+		mondooAuditConfig.Status.SpaceRouting = k8s.SpaceRoutingStatus(*mondooAuditConfig, routing)
+	}
+
 	mondooAuditConfig.Status.ReconciledByOperatorVersion = version.Version
 
 	if imageResolver != nil {
@@ -397,6 +598,23 @@ func (r *MondooAuditConfigReconciler) nodeEventsRequestMapper(ctx context.Contex
 	return requests
 }
 
+// namespaceEventsRequestMapper Maps namespace events to enqueue all MondooAuditConfigs, so the namespaces their
+// filtering resolves to stay up to date with namespace patterns, selectors and scan opt-outs.
+func (r *MondooAuditConfigReconciler) namespaceEventsRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	auditConfigs := &v1alpha2.MondooAuditConfigList{}
+	if err := r.List(ctx, auditConfigs); err != nil {
+		logger := ctrllog.Log.WithName("namespace-watcher")
+		logger.Error(err, "Failed to list MondooAuditConfigs")
+		return requests
+	}
+
+	for _, a := range auditConfigs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&a)})
+	}
+	return requests
+}
+
 // cronJobPodsRequestMapper watches Pods created by our CronJobs
 // Otherwise we wouldn't be able to report OOM status on the spawned Pods
 func (r *MondooAuditConfigReconciler) cronJobPodsRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
@@ -489,10 +707,11 @@ func isCronJobScanPod(a v1alpha2.MondooAuditConfig, podLabels map[string]string)
 	return false
 }
 
-func (r *MondooAuditConfigReconciler) exchangeTokenForServiceAccount(ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, log logr.Logger) error {
+// exchangeTokenForServiceAccount returns true if it created the Secret of .spec.mondooCredsSecretRef.
+func (r *MondooAuditConfigReconciler) exchangeTokenForServiceAccount(ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, log logr.Logger) (bool, error) {
 	if auditConfig.Spec.MondooCredsSecretRef.Name == "" {
 		log.Info("MondooAuditConfig without .spec.mondooCredsSecretRef defined")
-		return nil
+		return false, nil
 	}
 
 	mondooCredsSecret := &corev1.Secret{
@@ -504,12 +723,12 @@ func (r *MondooAuditConfigReconciler) exchangeTokenForServiceAccount(ctx context
 	mondooCredsExists, err := k8s.CheckIfExists(ctx, r.Client, mondooCredsSecret, mondooCredsSecret)
 	if err != nil {
 		log.Error(err, "failed to check whether Mondoo creds secret exists")
-		return err
+		return false, err
 	}
 
 	if mondooCredsExists {
 		// Nothing to do as we already have creds
-		return nil
+		return false, nil
 	}
 
 	mondooTokenSecret := &corev1.Secret{
@@ -521,30 +740,156 @@ func (r *MondooAuditConfigReconciler) exchangeTokenForServiceAccount(ctx context
 	mondooTokenExists, err := k8s.CheckIfExists(ctx, r.Client, mondooTokenSecret, mondooTokenSecret)
 	if err != nil {
 		log.Error(err, "failed to check whether Mondoo token secret exists")
-		return err
+		return false, err
 	}
 
 	// mondoCredsExists is already false from here down
 	if !mondooTokenExists {
 		log.Info("neither .spec.MondooCredsSecretRef nor .spec.MondooTokenSecretRef exist")
-		return nil
+		return false, nil
 	}
 
 	log.Info("Creating Mondoo service account from token")
+	if err := r.createServiceAccountFromToken(ctx, auditConfig, cfg, mondooTokenSecret, log); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// createServiceAccountFromToken exchanges the token of the token Secret for a Mondoo service account and saves it
+// in the Secret of .spec.mondooCredsSecretRef.
+func (r *MondooAuditConfigReconciler) createServiceAccountFromToken(ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, mondooTokenSecret *corev1.Secret, log logr.Logger) error {
+	tlsConfig, err := mondoo.APITLSConfig(ctx, r.Client, auditConfig.Namespace, cfg)
+	if err != nil {
+		log.Error(err, "failed to load the TLS configuration for the Mondoo API")
+		return err
+	}
+	proxies, err := k8s.ResolveProxyURLs(ctx, r.Client, auditConfig.Namespace, cfg)
+	if err != nil {
+		log.Error(err, "failed to resolve the proxies for the Mondoo API")
+		return err
+	}
+
 	tokenData := string(mondooTokenSecret.Data[constants.MondooTokenSecretKey])
 	return mondoo.CreateServiceAccountFromToken(
 		ctx,
 		r.Client,
 		r.MondooClientBuilder,
 		auditConfig.Spec.ConsoleIntegration.Enable,
-		client.ObjectKeyFromObject(mondooCredsSecret),
+		types.NamespacedName{Namespace: auditConfig.Namespace, Name: auditConfig.Spec.MondooCredsSecretRef.Name},
 		tokenData,
-		cfg.Spec.HttpProxy,
-		cfg.Spec.HttpsProxy,
-		cfg.Spec.NoProxy,
+		proxies.HttpProxy,
+		proxies.HttpsProxy,
+		proxies.NoProxy,
+		tlsConfig,
 		log)
 }
 
+// checkCredentials validates the service account of .spec.mondooCredsSecretRef or .spec.mondooCredsSource against
+// the Mondoo API once per interval and whenever the credentials change. Rejected credentials are reported in the
+// CredentialsInvalid condition and the service account is re-created from the token if
+// .spec.credentialsCheck.reRegister is set. A service account that was just created from the token is considered
+// valid. It returns the time until the next validation, or 0 if the credentials are not validated.
+func (r *MondooAuditConfigReconciler) checkCredentials(ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, justCreated bool, log logr.Logger) time.Duration {
+	check := auditConfig.Spec.CredentialsCheck
+	noCredentials := auditConfig.Spec.MondooCredsSecretRef.Name == "" && auditConfig.Spec.MondooCredsSource == nil
+	if check.Disable || noCredentials || r.MondooClientBuilder == nil {
+		auditConfig.Status.CredentialsCheck = nil
+		return 0
+	}
+	interval := defaultCredentialsCheckInterval
+	if check.Interval != nil && check.Interval.Duration > 0 {
+		interval = check.Interval.Duration
+	}
+
+	credsSecret, err := k8s.MondooCredentialsSecret(ctx, r.Client, *auditConfig)
+	if err != nil {
+		// A missing Secret is reported in the ReferencedSecretsDegraded condition
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get the Mondoo credentials")
+		}
+		return interval
+	}
+
+	if last := auditConfig.Status.CredentialsCheck; last != nil && last.SecretResourceVersion == credsSecret.ResourceVersion {
+		if next := time.Until(last.LastCheckTime.Add(interval)); next > 0 {
+			return next
+		}
+	}
+
+	auditConfig.Status.CredentialsCheck = &v1alpha2.CredentialsCheckStatus{
+		LastCheckTime:         metav1.Now(),
+		SecretResourceVersion: credsSecret.ResourceVersion,
+	}
+	if !justCreated {
+		err = mondoo.CheckServiceAccount(ctx, r.Client, credsSecret, cfg, r.MondooClientBuilder)
+	}
+	switch {
+	case err == nil:
+		if cond := mondoo.FindMondooAuditConditions(auditConfig.Status.Conditions, v1alpha2.CredentialsInvalid); cond != nil && cond.Status == corev1.ConditionTrue {
+			auditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+				auditConfig.Status.Conditions,
+				v1alpha2.CredentialsInvalid,
+				corev1.ConditionFalse,
+				"CredentialsValid",
+				"Mondoo credentials are valid",
+				mondoo.UpdateConditionAlways,
+				nil, "",
+			)
+		}
+	case stderrors.Is(err, mondoo.ErrInvalidCredentials):
+		log.Error(err, "invalid Mondoo credentials", "secret", credsSecret.Name)
+		auditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+			auditConfig.Status.Conditions,
+			v1alpha2.CredentialsInvalid,
+			corev1.ConditionTrue,
+			"CredentialsRejected",
+			fmt.Sprintf("Mondoo credentials in Secret %s are invalid: %s", credsSecret.Name, err),
+			mondoo.UpdateConditionIfReasonOrMessageChange,
+			nil, "",
+		)
+		if check.ReRegister {
+			r.reRegisterServiceAccount(ctx, auditConfig, cfg, log)
+		}
+	default:
+		// The credentials may still be valid, so the condition is kept until the next validation
+		log.Error(err, "failed to validate the Mondoo credentials", "secret", credsSecret.Name)
+	}
+	return interval
+}
+
+// reRegisterServiceAccount re-creates the service account of .spec.mondooCredsSecretRef from the token of
+// .spec.mondooTokenSecretRef and records the outcome as an Event. The changed Secret triggers another validation.
+func (r *MondooAuditConfigReconciler) reRegisterServiceAccount(ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, log logr.Logger) {
+	if auditConfig.Spec.MondooCredsSource != nil {
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeWarning, "ReRegistrationFailed", "ReRegister",
+			"Cannot re-create the Mondoo service account of .spec.mondooCredsSource, update it in the secret store")
+		return
+	}
+	if auditConfig.Spec.MondooTokenSecretRef.Name == "" {
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeWarning, "ReRegistrationFailed", "ReRegister",
+			"Cannot re-create the Mondoo service account without .spec.mondooTokenSecretRef")
+		return
+	}
+
+	mondooTokenSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: auditConfig.Namespace, Name: auditConfig.Spec.MondooTokenSecretRef.Name}, mondooTokenSecret); err != nil {
+		log.Error(err, "failed to get the Mondoo token secret for the re-registration")
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeWarning, "ReRegistrationFailed", "ReRegister",
+			"Failed to get the token Secret %s: %s", auditConfig.Spec.MondooTokenSecretRef.Name, err)
+		return
+	}
+
+	log.Info("Re-creating Mondoo service account from token")
+	if err := r.createServiceAccountFromToken(ctx, auditConfig, cfg, mondooTokenSecret, log); err != nil {
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeWarning, "ReRegistrationFailed", "ReRegister",
+			"Failed to re-create the Mondoo service account from the token Secret %s: %s", mondooTokenSecret.Name, err)
+		return
+	}
+	r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeNormal, "ReRegistered", "ReRegister",
+		"Re-created the Mondoo service account in Secret %s from the token Secret %s", auditConfig.Spec.MondooCredsSecretRef.Name, mondooTokenSecret.Name)
+}
+
 // operatorConfigRequestMapper maps MondooOperatorConfig changes to enqueue all MondooAuditConfigs
 // for reconciliation, so proxy/registry changes take effect without waiting for the next scheduled reconcile.
 func (r *MondooAuditConfigReconciler) operatorConfigRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
@@ -562,6 +907,47 @@ func (r *MondooAuditConfigReconciler) operatorConfigRequestMapper(ctx context.Co
 	return requests
 }
 
+// secretEventsRequestMapper maps Secret changes to enqueue the MondooAuditConfigs in the same namespace that
+// reference the Secret, so rotated credentials are rolled out and the merged private registries Secret is
+// refreshed right away.
+func (r *MondooAuditConfigReconciler) secretEventsRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	logger := ctrllog.Log.WithName("secret-watcher")
+
+	auditConfigs := &v1alpha2.MondooAuditConfigList{}
+	if err := r.List(ctx, auditConfigs, client.InNamespace(o.GetNamespace())); err != nil {
+		logger.Error(err, "Failed to list MondooAuditConfigs")
+		return requests
+	}
+	if len(auditConfigs.Items) == 0 {
+		return requests
+	}
+
+	var config *v1alpha2.MondooOperatorConfig
+	operatorConfig := &v1alpha2.MondooOperatorConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: v1alpha2.MondooOperatorConfigName}, operatorConfig); err == nil {
+		config = operatorConfig
+	}
+
+	for _, a := range auditConfigs.Items {
+		if slices.Contains(k8s.ReferencedSecretNames(a, config), o.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&a)})
+		}
+	}
+	return requests
+}
+
+// secretEventHandler enqueues the MondooAuditConfigs that reference a Secret and drops the cached token
+// source of deleted Secrets.
+type secretEventHandler struct {
+	handler.EventHandler
+}
+
+func (h secretEventHandler) Delete(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	mondoo.ForgetSecretTokenSource(e.Object.GetUID())
+	h.EventHandler.Delete(ctx, e, q)
+}
+
 func refreshCacheTTL(schedule string) time.Duration {
 	p := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 	sched, err := p.Parse(schedule)
@@ -617,6 +1003,11 @@ func (r *MondooAuditConfigReconciler) refreshDigests(m *v1alpha2.MondooAuditConf
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MondooAuditConfigReconciler) SetupWithManager(mgr ctrl.Manager, mondooOperatorConfigCRDExists bool) error {
+	// The resources that opted out of scanning are counted through an index instead of listing all of them
+	if err := k8s.IndexScanOptOuts(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
 	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha2.MondooAuditConfig{}).
 		Owns(&batchv1.CronJob{}).
@@ -628,6 +1019,18 @@ func (r *MondooAuditConfigReconciler) SetupWithManager(mgr ctrl.Manager, mondooO
 		Watches(
 			&corev1.Node{},
 			handler.EnqueueRequestsFromMapFunc(r.nodeEventsRequestMapper),
+			builder.WithPredicates(k8s.IgnoreGenericEventsPredicate{})).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.namespaceEventsRequestMapper),
+			// Only the labels and annotations of namespaces affect the filtering and the scan opt-outs
+			builder.WithPredicates(k8s.IgnoreGenericEventsPredicate{},
+				predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// Only the metadata of Secrets is cached, their content is read from the API server when needed
+		Watches(
+			&corev1.Secret{},
+			secretEventHandler{EventHandler: handler.EnqueueRequestsFromMapFunc(r.secretEventsRequestMapper)},
+			builder.OnlyMetadata,
 			builder.WithPredicates(k8s.IgnoreGenericEventsPredicate{}))
 	if mondooOperatorConfigCRDExists {
 		b = b.Watches(
@@ -748,3 +1151,60 @@ func (r *MondooAuditConfigReconciler) cleanupOrphanedScanAPIResources(ctx contex
 		}
 	}
 }
+
+// purgeAssets deletes the assets of all scans of a deleted MondooAuditConfig with deletionPolicy Purge. It
+// returns when to retry if purging failed and the timeout has not passed yet, or 0 if the MondooAuditConfig can
+// be released. Failed attempts are reported in the AssetsPurgeFailed condition, a timeout in an Event. With
+// spec.gc.dryRun the assets are only listed and retained, which is reported in an Event, too.
+func (r *MondooAuditConfigReconciler) purgeAssets(
+	ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, log logr.Logger,
+) time.Duration {
+	err := r.tryPurgeAssets(ctx, auditConfig, cfg, log)
+	switch {
+	case err == nil && auditConfig.Spec.GarbageCollection.DryRun:
+		log.Info("Listed the assets of the MondooAuditConfig, retaining them because of the dry run")
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeNormal, "AssetPurgeDryRun", "Purge",
+			"Retaining the assets, spec.gc.dryRun is set so they were only listed")
+		return 0
+	case err == nil:
+		log.Info("Purged the assets of the MondooAuditConfig")
+		return 0
+	}
+
+	if time.Since(auditConfig.DeletionTimestamp.Time) >= assetPurgeTimeout {
+		log.Error(err, "Failed to purge the assets of the MondooAuditConfig before the timeout, retaining them")
+		r.Recorder.Eventf(auditConfig, nil, corev1.EventTypeWarning, "AssetPurgeTimedOut", "Purge",
+			"Retaining the assets, purging them didn't succeed within %s: %s", assetPurgeTimeout, err)
+		return 0
+	}
+
+	log.Error(err, "Failed to purge the assets of the MondooAuditConfig, retrying", "retryAfter", assetPurgeRetryInterval)
+	reason := "PurgeFailed"
+	var sharedSpaceErr *mondoo.SharedSpaceError
+	if stderrors.As(err, &sharedSpaceErr) {
+		reason = "SpaceShared"
+	}
+	orig := auditConfig.DeepCopy()
+	auditConfig.Status.Conditions = mondoo.SetMondooAuditCondition(
+		auditConfig.Status.Conditions,
+		v1alpha2.AssetsPurgeFailed,
+		corev1.ConditionTrue,
+		reason,
+		fmt.Sprintf("Failed to purge the assets, retrying until %s: %s",
+			auditConfig.DeletionTimestamp.Add(assetPurgeTimeout).Format(time.RFC3339), err),
+		mondoo.UpdateConditionIfReasonOrMessageChange,
+		nil, "",
+	)
+	_ = mondoo.UpdateMondooAuditStatus(ctx, r.Client, orig, auditConfig, log)
+	return assetPurgeRetryInterval
+}
+
+func (r *MondooAuditConfigReconciler) tryPurgeAssets(
+	ctx context.Context, auditConfig *v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig, log logr.Logger,
+) error {
+	clusterUid, err := k8s.GetClusterUID(ctx, r.Client, log)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster UID: %w", err)
+	}
+	return mondoo.PurgeAssets(ctx, r.Client, auditConfig, cfg, r.MondooClientBuilder, clusterUid, log)
+}
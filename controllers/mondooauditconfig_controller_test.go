@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	scheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -31,6 +33,7 @@ import (
 	"go.mondoo.com/mondoo-operator/controllers/k8s_scan"
 	"go.mondoo.com/mondoo-operator/controllers/nodes"
 	"go.mondoo.com/mondoo-operator/controllers/status"
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 	mockmondoo "go.mondoo.com/mondoo-operator/pkg/client/mondooclient/mock"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
@@ -614,6 +617,234 @@ func testIntegrationTokenSecret() *corev1.Secret {
 	}
 }
 
+func TestSecretEventsRequestMapper(t *testing.T) {
+	referencing := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: testNamespace},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "rotated-creds"},
+		},
+	}
+	other := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: testNamespace},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "other-creds"},
+		},
+	}
+	otherNamespace := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: "other-namespace"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "rotated-creds"},
+		},
+	}
+	reconciler := &MondooAuditConfigReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(referencing, other, otherNamespace).Build(),
+	}
+
+	requests := reconciler.secretEventsRequestMapper(context.Background(), &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "rotated-creds", Namespace: testNamespace},
+	})
+	assert.Equal(t, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(referencing)}}, requests)
+
+	requests = reconciler.secretEventsRequestMapper(context.Background(), &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace},
+	})
+	assert.Empty(t, requests)
+}
+
+func TestCheckCredentials_ReRegistersRejectedServiceAccount(t *testing.T) {
+	utilruntime.Must(v1alpha2.AddToScheme(scheme.Scheme))
+	testTokenData = credentials.MondooToken(t, "")
+	sa := testMondooServiceAccount
+	sa.PrivateKey = credentials.MondooServiceAccount(t)
+	saData, err := json.Marshal(sa) //nolint:gosec
+	require.NoError(t, err)
+
+	auditConfig := testMondooAuditConfig()
+	auditConfig.Spec.CredentialsCheck.ReRegister = true
+	credsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testMondooCredsSecretName, Namespace: testNamespace},
+		Data:       map[string][]byte{"config": saData},
+	}
+	fakeClient := fake.NewClientBuilder().WithObjects(auditConfig, credsSecret, testTokenSecret()).Build()
+
+	mockCtrl := gomock.NewController(t)
+	mClient := mockmondoo.NewMockMondooClient(mockCtrl)
+	mClient.EXPECT().HealthCheck(gomock.Any(), gomock.Any()).Return(&common.HealthCheckResponse{Status: "SERVING"}, nil)
+	mClient.EXPECT().PingPong(gomock.Any(), gomock.Any()).Return(nil, common.ErrUnauthorized)
+	mClient.EXPECT().ExchangeRegistrationToken(gomock.Any(), gomock.Any()).Return(&mondooclient.ExchangeRegistrationTokenOutput{
+		ServiceAccount: testServiceAccountData,
+	}, nil)
+
+	recorder := events.NewFakeRecorder(1)
+	reconciler := &MondooAuditConfigReconciler{
+		Client: fakeClient,
+		MondooClientBuilder: func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) {
+			return mClient, nil
+		},
+		Recorder: recorder,
+	}
+
+	next := reconciler.checkCredentials(context.Background(), auditConfig, nil, false, logr.Discard())
+	assert.Equal(t, defaultCredentialsCheckInterval, next)
+
+	cond := mondoo.FindMondooAuditConditions(auditConfig.Status.Conditions, v1alpha2.CredentialsInvalid)
+	require.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	require.NotNil(t, auditConfig.Status.CredentialsCheck)
+
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(credsSecret), credsSecret))
+	assert.Equal(t, testServiceAccountData, string(credsSecret.Data["config"]))
+	assert.NotEqual(t, credsSecret.ResourceVersion, auditConfig.Status.CredentialsCheck.SecretResourceVersion,
+		"the re-created service account must be validated on the next reconcile")
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Normal ReRegistered")
+
+	// The credentials are not validated again until the interval elapsed
+	credsSecret.Data["config"] = saData
+	require.NoError(t, fakeClient.Update(context.Background(), credsSecret))
+	auditConfig.Status.CredentialsCheck.SecretResourceVersion = credsSecret.ResourceVersion
+	next = reconciler.checkCredentials(context.Background(), auditConfig, nil, false, logr.Discard())
+	assert.Greater(t, next, defaultCredentialsCheckInterval-time.Minute)
+}
+
+func TestPurgeAssets(t *testing.T) {
+	utilruntime.Must(v1alpha2.AddToScheme(scheme.Scheme))
+	sa := *testMondooServiceAccount
+	sa.PrivateKey = credentials.MondooServiceAccount(t)
+	saData, err := json.Marshal(sa) //nolint:gosec
+	require.NoError(t, err)
+	credsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testMondooCredsSecretName, Namespace: testNamespace},
+		Data:       map[string][]byte{"config": saData},
+	}
+	kubeSystem := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "cluster-uid"}}
+
+	tests := []struct {
+		name        string
+		deletedAgo  time.Duration
+		dryRun      bool
+		purgeErr    error
+		otherConfig func(*v1alpha2.MondooAuditConfig)
+		wantRequeue bool
+		wantReason  string
+		wantEvent   string
+	}{
+		{name: "purged"},
+		{name: "failed", deletedAgo: time.Minute, purgeErr: fmt.Errorf("unavailable"), wantRequeue: true, wantReason: "PurgeFailed"},
+		{name: "timed out", deletedAgo: assetPurgeTimeout, purgeErr: fmt.Errorf("unavailable"), wantEvent: "Warning AssetPurgeTimedOut"},
+		{name: "dry run", dryRun: true, wantEvent: "Normal AssetPurgeDryRun"},
+		{
+			name:        "space shared",
+			deletedAgo:  time.Minute,
+			otherConfig: func(*v1alpha2.MondooAuditConfig) {},
+			wantRequeue: true,
+			wantReason:  "SpaceShared",
+		},
+		{
+			name: "other config in another space",
+			otherConfig: func(other *v1alpha2.MondooAuditConfig) {
+				other.Spec.SpaceID = "other-space"
+			},
+		},
+		{
+			name: "other config purged, too",
+			otherConfig: func(other *v1alpha2.MondooAuditConfig) {
+				other.Spec.DeletionPolicy = v1alpha2.DeletionPolicyPurge
+				other.Finalizers = []string{finalizerString}
+				other.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auditConfig := testMondooAuditConfig()
+			auditConfig.Spec.DeletionPolicy = v1alpha2.DeletionPolicyPurge
+			auditConfig.Spec.GarbageCollection.DryRun = test.dryRun
+			auditConfig.Finalizers = []string{finalizerString}
+			auditConfig.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-test.deletedAgo)}
+			auditConfig.Spec.KubernetesResources.ExternalClusters = []v1alpha2.ExternalCluster{{Name: "prod"}}
+			// A removed external cluster whose assets weren't purged yet
+			auditConfig.Status.ExternalClusters = []v1alpha2.ExternalClusterStatus{
+				{Name: "staging", MondooCredsSecretRef: testMondooCredsSecretName},
+			}
+			builder := fake.NewClientBuilder().WithObjects(auditConfig, credsSecret, kubeSystem).WithStatusSubresource(auditConfig)
+			if test.otherConfig != nil {
+				// Another MondooAuditConfig using the same credentials
+				other := testMondooAuditConfig()
+				other.Name = "other"
+				other.UID = "other-uid"
+				test.otherConfig(other)
+				builder = builder.WithObjects(other)
+			}
+			fakeClient := builder.Build()
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(auditConfig), auditConfig))
+
+			mClient := mockmondoo.NewMockMondooClient(gomock.NewController(t))
+			var managedBy []string
+			collect := func(_ context.Context, req *mondooclient.GarbageCollectAssetsRequest) {
+				assert.Nil(t, req.DateFilter)
+				assert.Equal(t, testMondooServiceAccount.SpaceMrn, req.ScopeMrn)
+				managedBy = append(managedBy, req.ManagedBy)
+			}
+			wantManagedBy := []string{
+				mondoo.ManagedByLabel("cluster-uid"),
+				mondoo.ManagedByContainersLabel("cluster-uid"),
+				mondoo.ManagedByNodesLabel("cluster-uid"),
+				mondoo.ManagedByExternalClusterLabel("cluster-uid", "prod"),
+				mondoo.ManagedByExternalClusterLabel("cluster-uid", "staging"),
+			}
+			switch {
+			case test.wantReason == "SpaceShared":
+				wantManagedBy = nil
+			case test.dryRun:
+				mClient.EXPECT().PreviewGarbageCollectAssets(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *mondooclient.GarbageCollectAssetsRequest) (*mondooclient.PreviewGarbageCollectAssetsResponse, error) {
+						collect(ctx, req)
+						return &mondooclient.PreviewGarbageCollectAssetsResponse{TotalCount: 1}, nil
+					}).Times(5)
+			default:
+				mClient.EXPECT().GarbageCollectAssets(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *mondooclient.GarbageCollectAssetsRequest) error {
+						collect(ctx, req)
+						return test.purgeErr
+					}).Times(5)
+			}
+
+			recorder := events.NewFakeRecorder(1)
+			reconciler := &MondooAuditConfigReconciler{
+				Client: fakeClient,
+				MondooClientBuilder: func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) {
+					return mClient, nil
+				},
+				Recorder: recorder,
+			}
+
+			requeueAfter := reconciler.purgeAssets(context.Background(), auditConfig, &v1alpha2.MondooOperatorConfig{}, logr.Discard())
+			assert.Equal(t, wantManagedBy, managedBy)
+			assert.Equal(t, test.wantRequeue, requeueAfter > 0)
+
+			stored := &v1alpha2.MondooAuditConfig{}
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(auditConfig), stored))
+			cond := mondoo.FindMondooAuditConditions(stored.Status.Conditions, v1alpha2.AssetsPurgeFailed)
+			if test.wantReason != "" {
+				require.NotNil(t, cond)
+				assert.Equal(t, corev1.ConditionTrue, cond.Status)
+				assert.Equal(t, test.wantReason, cond.Reason)
+			} else {
+				assert.Nil(t, cond)
+			}
+
+			if test.wantEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else {
+				require.Len(t, recorder.Events, 1)
+				assert.Contains(t, <-recorder.Events, test.wantEvent)
+			}
+		})
+	}
+}
+
 func TestIsCronJobScanPod(t *testing.T) {
 	a := v1alpha2.MondooAuditConfig{
 		ObjectMeta: metav1.ObjectMeta{
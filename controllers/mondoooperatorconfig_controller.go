@@ -14,9 +14,12 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
@@ -31,6 +34,7 @@ type MondooOperatorConfigReconciler struct {
 //+kubebuilder:rbac:groups=k8s.mondoo.com,resources=mondoooperatorconfigs,verbs=get;watch
 //+kubebuilder:rbac:groups=k8s.mondoo.com,resources=mondoooperatorconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=k8s.mondoo.com,resources=mondoooperatorconfigs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=k8s.mondoo.com,resources=mondooauditconfigs,verbs=get;list;watch
 
 // Reconcile will check for a valid MondooOperatorConfig resource (only "mondoo-operator-config" allowed), and
 // set up the mondoo-operator as indicated in the resource.
@@ -59,10 +63,23 @@ func (r *MondooOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, nil
 	}
 
-	if config.Spec.ContainerProxy != nil {
-		urlParsed, err := url.Parse(*config.Spec.ContainerProxy)
+	namespace, err := k8s.GetRunningNamespace()
+	if err != nil {
+		configLog.Error(err, "failed to know which namespace to target")
+		return ctrl.Result{}, err
+	}
+
+	// The operator resolves images itself, so it reads the Secrets of the container proxy from its own namespace
+	proxies, err := k8s.ResolveProxyURLs(ctx, r.Client, namespace, config)
+	if err != nil {
+		configLog.Error(err, "failed to resolve the container proxy")
+		return ctrl.Result{}, err
+	}
+	if proxies.ContainerProxy != nil {
+		urlParsed, err := url.Parse(*proxies.ContainerProxy)
 		if err != nil {
-			return ctrl.Result{}, err
+			// The error contains the URL, which may contain credentials
+			return ctrl.Result{}, fmt.Errorf("containerProxy is not a valid URL")
 		}
 		remote.DefaultTransport = &http.Transport{
 			Proxy: http.ProxyURL(urlParsed),
@@ -80,11 +97,6 @@ func (r *MondooOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl
 		}
 	}
 
-	namespace, err := k8s.GetRunningNamespace()
-	if err != nil {
-		configLog.Error(err, "failed to know which namespace to target")
-		return ctrl.Result{}, err
-	}
 	serviceMonitor := ServiceMonitor{
 		Config:          config,
 		TargetNamespace: namespace,
@@ -104,5 +116,14 @@ func (r *MondooOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl
 func (r *MondooOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&mondoov1alpha2.MondooOperatorConfig{}).
+		Watches(
+			&mondoov1alpha2.MondooAuditConfig{},
+			handler.EnqueueRequestsFromMapFunc(r.auditConfigRequestMapper)).
 		Complete(r)
 }
+
+// auditConfigRequestMapper enqueues the MondooOperatorConfig whenever a MondooAuditConfig changes, so the
+// resource watcher metrics Services and ServiceMonitor follow the resource watchers being enabled or disabled.
+func (r *MondooOperatorConfigReconciler) auditConfigRequestMapper(ctx context.Context, o client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: mondoov1alpha2.MondooOperatorConfigName}}}
+}
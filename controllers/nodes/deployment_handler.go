@@ -76,6 +76,12 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
 		return err
 	}
 
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, n.ContainerImageResolver, *n.Mondoo, n.MondooOperatorConfig.Spec.SkipContainerResolution)
+	if err != nil {
+		logger.Error(err, "Failed to resolve mondoo-operator container image")
+		return err
+	}
+
 	clusterUid, err := k8s.GetClusterUID(ctx, n.KubeClient, logger)
 	if err != nil {
 		logger.Error(err, "Failed to get cluster's UID")
@@ -110,6 +116,7 @@ func (n *DeploymentHandler) syncCronJob(ctx context.Context) error {
 		}
 
 		desired := CronJob(mondooClientImage, node, n.Mondoo, n.IsOpenshift, *n.MondooOperatorConfig)
+		k8s.AddMondooCredsSourceToSpec(&desired.Spec.JobTemplate.Spec.Template.Spec, *n.Mondoo, k8s.NodesScan, credsInitImage)
 		cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 		op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, cronJob, n.Mondoo, logger, func() error {
 			k8s.UpdateCronJobFields(cronJob, desired)
@@ -181,6 +188,12 @@ func (n *DeploymentHandler) syncDaemonSet(ctx context.Context) error {
 		return err
 	}
 
+	credsInitImage, err := mondoo.CredsSourceInitImage(ctx, n.ContainerImageResolver, *n.Mondoo, n.MondooOperatorConfig.Spec.SkipContainerResolution)
+	if err != nil {
+		logger.Error(err, "Failed to resolve mondoo-operator container image")
+		return err
+	}
+
 	clusterUid, err := k8s.GetClusterUID(ctx, n.KubeClient, logger)
 	if err != nil {
 		logger.Error(err, "Failed to get cluster's UID")
@@ -230,6 +243,12 @@ func (n *DeploymentHandler) syncDaemonSet(ctx context.Context) error {
 	}
 
 	desired := DaemonSet(*n.Mondoo, n.IsOpenshift, mondooClientImage, *n.MondooOperatorConfig, slices.Collect(maps.Keys(tolerations)))
+	k8s.AddMondooCredsSourceToSpec(&desired.Spec.Template.Spec, *n.Mondoo, k8s.NodesScan, credsInitImage)
+	// Roll the scanners when the credentials or another Secret they use change
+	if err := k8s.SetSecretsHashAnnotation(ctx, n.KubeClient, desired.Namespace, &desired.Spec.Template); err != nil {
+		logger.Error(err, "Failed to hash the Secrets of the node scanning DaemonSet")
+		return err
+	}
 	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 	op, err := k8s.CreateOrUpdate(ctx, n.KubeClient, ds, n.Mondoo, logger, func() error {
 		k8s.UpdateDaemonSetFields(ds, desired)
@@ -448,9 +467,11 @@ func (n *DeploymentHandler) performGarbageCollection(ctx context.Context, manage
 		},
 	}
 
-	if err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger); err != nil {
+	result, err := mondoo.GarbageCollectAssets(ctx, n.KubeClient, n.Mondoo, k8s.NodesScan, n.MondooOperatorConfig, n.MondooClientBuilder, req, logger)
+	if err != nil {
 		return err
 	}
+	mondoo.SetGarbageCollectionStatus(&n.Mondoo.Status, k8s.NodesScan, []*v1alpha2.GarbageCollectionStatus{result})
 
 	logger.Info("Successfully performed garbage collection of node scan assets")
 	return nil
@@ -19,6 +19,7 @@ import (
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 	fakeMondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo/fake"
 	"go.mondoo.com/mondoo-operator/tests/framework/utils"
@@ -437,6 +438,7 @@ func (s *DeploymentHandlerSuite) TestReconcile_CreateDaemonSets() {
 
 	dsExpected := DaemonSet(s.auditConfig, false, image, v1alpha2.MondooOperatorConfig{},
 		[]corev1.Toleration{{Key: "node-role.kubernetes.io/master", Value: "true", Effect: corev1.TaintEffectNoExecute}})
+	s.NoError(k8s.SetSecretsHashAnnotation(s.ctx, d.KubeClient, dsExpected.Namespace, &dsExpected.Spec.Template))
 	// Make sure the env vars for both are sorted
 	utils.SortEnvVars(dsExpected.Spec.Template.Spec.Containers[0].Env)
 	utils.SortEnvVars(ds.Spec.Template.Spec.Containers[0].Env)
@@ -470,6 +472,7 @@ func (s *DeploymentHandlerSuite) TestReconcile_CreateDaemonSets_Switch() {
 
 	dsExpected := DaemonSet(s.auditConfig, false, image, v1alpha2.MondooOperatorConfig{},
 		[]corev1.Toleration{{Key: "node-role.kubernetes.io/master", Value: "true", Effect: corev1.TaintEffectNoExecute}})
+	s.NoError(k8s.SetSecretsHashAnnotation(s.ctx, d.KubeClient, dsExpected.Namespace, &dsExpected.Spec.Template))
 	s.Equal(dsExpected.Spec, ds.Spec)
 
 	mondooAuditConfig.Spec.Nodes.Style = v1alpha2.NodeScanStyle_CronJob
@@ -519,6 +522,7 @@ func (s *DeploymentHandlerSuite) TestReconcile_UpdateDaemonSets() {
 
 	depExpected := DaemonSet(s.auditConfig, false, image, v1alpha2.MondooOperatorConfig{},
 		[]corev1.Toleration{{Key: "node-role.kubernetes.io/master", Value: "true", Effect: corev1.TaintEffectNoExecute}})
+	s.NoError(k8s.SetSecretsHashAnnotation(s.ctx, d.KubeClient, depExpected.Namespace, &depExpected.Spec.Template))
 	s.Equal(depExpected.Spec, ds.Spec)
 }
 
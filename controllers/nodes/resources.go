@@ -155,7 +155,7 @@ func CronJob(image string, node corev1.Node, m *v1alpha2.MondooAuditConfig, isOp
 												},
 												{
 													Secret: &corev1.SecretProjection{
-														LocalObjectReference: k8s.ConfigSecretRef(*m),
+														LocalObjectReference: k8s.ConfigSecretRef(*m, k8s.NodesScan),
 														Items:                []corev1.KeyToPath{{Key: "config", Path: "mondoo/mondoo.yml"}},
 													},
 												},
@@ -182,6 +182,9 @@ func CronJob(image string, node corev1.Node, m *v1alpha2.MondooAuditConfig, isOp
 			cfg.Spec.ImagePullSecrets...)
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&cj.Spec.JobTemplate.Spec.Template.Spec, cfg)
+
 	return cj
 }
 
@@ -286,7 +289,7 @@ func DaemonSet(m v1alpha2.MondooAuditConfig, isOpenshift bool, image string, cfg
 										},
 										{
 											Secret: &corev1.SecretProjection{
-												LocalObjectReference: k8s.ConfigSecretRef(m),
+												LocalObjectReference: k8s.ConfigSecretRef(m, k8s.NodesScan),
 												Items:                []corev1.KeyToPath{{Key: "config", Path: "mondoo/mondoo.yml"}},
 											},
 										},
@@ -311,6 +314,9 @@ func DaemonSet(m v1alpha2.MondooAuditConfig, isOpenshift bool, image string, cfg
 			cfg.Spec.ImagePullSecrets...)
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&ds.Spec.Template.Spec, cfg)
+
 	return ds
 }
 
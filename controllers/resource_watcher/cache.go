@@ -0,0 +1,87 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// lastAppliedConfigAnnotation holds a full copy of the object as applied by kubectl. The watcher never reads it.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// clusterScopedObjects are the cluster-scoped kinds the watcher can watch. The API server rejects field
+// selectors on metadata.namespace for them.
+var clusterScopedObjects = []client.Object{&corev1.Namespace{}}
+
+// CacheOptions returns the cache options for the resource watcher. The cache only holds objects in the
+// watched namespaces, so memory scales with the watched scope instead of the cluster size.
+func CacheOptions(scheme *runtime.Scheme, namespaces, namespacesExclude []string) cache.Options {
+	opts := cache.Options{
+		Scheme:           scheme,
+		DefaultTransform: stripForCache,
+	}
+
+	if len(namespaces) > 0 {
+		// Only list and watch the included namespaces
+		byNamespace := make(map[string]cache.Config)
+		for _, ns := range namespaces {
+			byNamespace[ns] = cache.Config{}
+		}
+		opts.DefaultNamespaces = byNamespace
+	} else if len(namespacesExclude) > 0 {
+		// Let the API server drop objects in excluded namespaces before they reach the cache
+		selectors := make([]fields.Selector, 0, len(namespacesExclude))
+		for _, ns := range namespacesExclude {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", ns))
+		}
+		opts.DefaultFieldSelector = fields.AndSelectors(selectors...)
+		// Cluster-scoped kinds have no namespace to select on, they are listed and watched unfiltered
+		opts.ByObject = make(map[client.Object]cache.ByObject, len(clusterScopedObjects))
+		for _, obj := range clusterScopedObjects {
+			opts.ByObject[obj] = cache.ByObject{Field: fields.Everything()}
+		}
+	}
+
+	return opts
+}
+
+// stripForCache removes fields the watcher never reads before an object is stored in the cache.
+var stripForCache toolscache.TransformFunc = func(in any) (any, error) {
+	in, err := cache.TransformStripManagedFields()(in)
+	if err != nil {
+		return in, err
+	}
+	if obj, err := meta.Accessor(in); err == nil {
+		if annotations := obj.GetAnnotations(); annotations[lastAppliedConfigAnnotation] != "" {
+			delete(annotations, lastAppliedConfigAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+	}
+	return in, nil
+}
+
+// metadataObjectForResourceType returns an empty metadata-only object for a resource type. Watching these
+// instead of the typed objects keeps specs, statuses and Secret data out of the cache.
+func metadataObjectForResourceType(resourceType string) (client.Object, error) {
+	obj, err := objectForResourceType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := apiutil.GVKForObject(obj, clientgoscheme.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	meta := &metav1.PartialObjectMetadata{}
+	meta.SetGroupVersionKind(gvk)
+	return meta, nil
+}
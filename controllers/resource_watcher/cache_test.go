@@ -0,0 +1,91 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+func TestCacheOptions_IncludedNamespaces(t *testing.T) {
+	opts := CacheOptions(scheme.Scheme, []string{"app1", "app2"}, []string{"kube-system"})
+
+	assert.Len(t, opts.DefaultNamespaces, 2)
+	assert.Contains(t, opts.DefaultNamespaces, "app1")
+	assert.Contains(t, opts.DefaultNamespaces, "app2")
+	// The include list takes precedence, like in shouldWatchNamespace
+	assert.Nil(t, opts.DefaultFieldSelector)
+	assert.Empty(t, opts.ByObject)
+	assert.NotNil(t, opts.DefaultTransform)
+}
+
+func TestCacheOptions_ExcludedNamespaces(t *testing.T) {
+	opts := CacheOptions(scheme.Scheme, nil, []string{"kube-system", "ci"})
+
+	assert.Empty(t, opts.DefaultNamespaces)
+	require.NotNil(t, opts.DefaultFieldSelector)
+	assert.False(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "kube-system"}))
+	assert.False(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "ci"}))
+	assert.True(t, opts.DefaultFieldSelector.Matches(fields.Set{"metadata.namespace": "default"}))
+
+	// Namespaces are cluster-scoped, the API server would reject the metadata.namespace selector for them
+	var namespaceConfig *cache.ByObject
+	for obj, config := range opts.ByObject {
+		if _, ok := obj.(*corev1.Namespace); ok {
+			namespaceConfig = &config
+		}
+	}
+	require.NotNil(t, namespaceConfig)
+	require.NotNil(t, namespaceConfig.Field)
+	assert.True(t, namespaceConfig.Field.Empty())
+	_, filtered := namespaceConfig.Field.RequiresExactMatch("metadata.namespace")
+	assert.False(t, filtered)
+	assert.Len(t, opts.ByObject, len(clusterScopedObjects))
+}
+
+func TestCacheOptions_AllNamespaces(t *testing.T) {
+	opts := CacheOptions(scheme.Scheme, nil, nil)
+
+	assert.Empty(t, opts.DefaultNamespaces)
+	assert.Nil(t, opts.DefaultFieldSelector)
+}
+
+func TestStripForCache(t *testing.T) {
+	obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name:          "web",
+		Namespace:     "default",
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		Annotations: map[string]string{
+			lastAppliedConfigAnnotation: `{"apiVersion":"apps/v1","kind":"Deployment"}`,
+			"mondoo.com/watch":          "false",
+		},
+	}}
+
+	out, err := stripForCache(obj)
+	require.NoError(t, err)
+
+	stripped := out.(*metav1.PartialObjectMetadata)
+	assert.Nil(t, stripped.ManagedFields)
+	assert.Equal(t, map[string]string{"mondoo.com/watch": "false"}, stripped.Annotations)
+}
+
+func TestMetadataObjectForResourceType(t *testing.T) {
+	obj, err := metadataObjectForResourceType("deployments")
+	require.NoError(t, err)
+
+	meta, ok := obj.(*metav1.PartialObjectMetadata)
+	require.True(t, ok)
+	assert.Equal(t, appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.GroupVersionKind())
+
+	_, err = metadataObjectForResourceType("widgets")
+	assert.Error(t, err)
+}
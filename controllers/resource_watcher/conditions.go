@@ -4,13 +4,19 @@
 package resource_watcher
 
 import (
+	"maps"
+	"slices"
+
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 	corev1 "k8s.io/api/core/v1"
 )
 
-const oomMessage = "Resource Watcher is unavailable due to OOM"
+const (
+	oomMessage      = "Resource Watcher is unavailable due to OOM"
+	notReadyMessage = "Resource Watcher is not ready: informers have not synced"
+)
 
 func updateResourceWatcherConditions(config *v1alpha2.MondooAuditConfig, degradedStatus bool, pods *corev1.PodList) {
 	msg := "Resource Watcher is available"
@@ -19,8 +25,9 @@ func updateResourceWatcherConditions(config *v1alpha2.MondooAuditConfig, degrade
 	updateCheck := mondoo.UpdateConditionIfReasonOrMessageChange
 	affectedPods := []string{}
 	memoryLimit := ""
+	enabled := anyWatcherEnabled(config)
 
-	if !config.Spec.KubernetesResources.Enable || !config.Spec.KubernetesResources.ResourceWatcher.Enable {
+	if !enabled {
 		msg = "Resource Watcher is disabled"
 		reason = "ResourceWatcherDisabled"
 		status = corev1.ConditionFalse
@@ -36,21 +43,73 @@ func updateResourceWatcherConditions(config *v1alpha2.MondooAuditConfig, degrade
 		status = corev1.ConditionTrue
 	}
 
-	currentPod := k8s.GetNewestPodFromList(pods.Items)
-	for i, containerStatus := range currentPod.Status.ContainerStatuses {
-		if containerStatus.Name != "mondoo-resource-watcher" {
-			continue
-		}
-		if (containerStatus.LastTerminationState.Terminated != nil && containerStatus.LastTerminationState.Terminated.ExitCode == 137) ||
-			(containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 137) {
-			msg = oomMessage
-			affectedPods = append(affectedPods, currentPod.Name)
-			memoryLimit = currentPod.Spec.Containers[i].Resources.Limits.Memory().String()
-			reason = "ResourceWatcherUnavailable"
-			status = corev1.ConditionTrue
+	// Every watcher Deployment is checked, so an unhealthy watcher is reported even if the newest pod
+	// belongs to a healthy one
+	for _, group := range watcherPodGroups(pods.Items) {
+		currentPod := k8s.GetNewestPodFromList(group)
+		for i, containerStatus := range currentPod.Status.ContainerStatuses {
+			if containerStatus.Name != "mondoo-resource-watcher" {
+				continue
+			}
+			if (containerStatus.LastTerminationState.Terminated != nil && containerStatus.LastTerminationState.Terminated.ExitCode == 137) ||
+				(containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 137) {
+				msg = oomMessage
+				affectedPods = append(affectedPods, currentPod.Name)
+				memoryLimit = currentPod.Spec.Containers[i].Resources.Limits.Memory().String()
+				reason = "ResourceWatcherUnavailable"
+				status = corev1.ConditionTrue
+			} else if enabled && !containerStatus.Ready {
+				// The watcher writes the reason for a failed liveness check into its termination message
+				// before it is restarted, so surface that instead of a generic "unavailable".
+				if last := containerStatus.LastTerminationState.Terminated; last != nil && last.Message != "" {
+					msg = "Resource Watcher failed its health check: " + last.Message
+					affectedPods = append(affectedPods, currentPod.Name)
+				} else if containerStatus.State.Running != nil {
+					msg = notReadyMessage
+				} else {
+					continue
+				}
+				reason = "ResourceWatcherUnhealthy"
+				status = corev1.ConditionTrue
+			}
 		}
 	}
 
 	config.Status.Conditions = mondoo.SetMondooAuditCondition(
 		config.Status.Conditions, v1alpha2.ResourceWatcherDegraded, status, reason, msg, updateCheck, affectedPods, memoryLimit)
 }
+
+// watcherPodGroups groups the pods by the resource watcher Deployment they belong to: the local watcher, the
+// watcher of each routed space and the watcher of each external cluster.
+func watcherPodGroups(pods []corev1.Pod) [][]corev1.Pod {
+	groups := map[string][]corev1.Pod{}
+	for _, pod := range pods {
+		ls := pod.Labels
+		key := ls["app"] + "/" + ls[k8s.SpaceIDLabel] + "/" + ls["cluster_name"]
+		groups[key] = append(groups[key], pod)
+	}
+	keys := slices.Sorted(maps.Keys(groups))
+	result := make([][]corev1.Pod, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+	return result
+}
+
+// localWatcherEnabled returns whether the resource watcher for the operator's own cluster is enabled.
+func localWatcherEnabled(config *v1alpha2.MondooAuditConfig) bool {
+	return config.Spec.KubernetesResources.Enable && config.Spec.KubernetesResources.ResourceWatcher.Enable
+}
+
+// anyWatcherEnabled returns whether the resource watcher is enabled for the local or any external cluster.
+func anyWatcherEnabled(config *v1alpha2.MondooAuditConfig) bool {
+	if localWatcherEnabled(config) {
+		return true
+	}
+	for _, cluster := range config.Spec.KubernetesResources.ExternalClusters {
+		if cluster.ResourceWatcher != nil && cluster.ResourceWatcher.Enable {
+			return true
+		}
+	}
+	return false
+}
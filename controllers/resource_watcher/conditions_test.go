@@ -5,6 +5,7 @@ package resource_watcher
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -111,3 +112,151 @@ func TestConditions_OOM(t *testing.T) {
 	assert.Equal(t, oomMessage, cond.Message)
 	assert.Contains(t, cond.AffectedPods, "test-pod")
 }
+
+func TestConditions_NotReady(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{}
+	config.Spec.KubernetesResources.Enable = true
+	config.Spec.KubernetesResources.ResourceWatcher.Enable = true
+
+	pods := &corev1.PodList{
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					CreationTimestamp: metav1.Now(),
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "mondoo-resource-watcher",
+							Ready: false,
+							State: corev1.ContainerState{
+								Running: &corev1.ContainerStateRunning{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	updateResourceWatcherConditions(config, true, pods)
+
+	cond := mondoo.FindMondooAuditConditions(config.Status.Conditions, v1alpha2.ResourceWatcherDegraded)
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, "ResourceWatcherUnhealthy", cond.Reason)
+	assert.Equal(t, notReadyMessage, cond.Message)
+}
+
+func TestConditions_HealthCheckFailed(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{}
+	config.Spec.KubernetesResources.Enable = true
+	config.Spec.KubernetesResources.ResourceWatcher.Enable = true
+
+	pods := &corev1.PodList{
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					CreationTimestamp: metav1.Now(),
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "mondoo-resource-watcher",
+							Ready: false,
+							LastTerminationState: corev1.ContainerState{
+								Terminated: &corev1.ContainerStateTerminated{
+									ExitCode: 1,
+									Message:  "5 consecutive cnspec scans failed, last error: exit status 1",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	updateResourceWatcherConditions(config, true, pods)
+
+	cond := mondoo.FindMondooAuditConditions(config.Status.Conditions, v1alpha2.ResourceWatcherDegraded)
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, "ResourceWatcherUnhealthy", cond.Reason)
+	assert.Equal(t, "Resource Watcher failed its health check: 5 consecutive cnspec scans failed, last error: exit status 1", cond.Message)
+	assert.Contains(t, cond.AffectedPods, "test-pod")
+}
+
+func TestConditions_ReadyAfterHealthCheckRestart(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{}
+	config.Spec.KubernetesResources.Enable = true
+	config.Spec.KubernetesResources.ResourceWatcher.Enable = true
+
+	pods := &corev1.PodList{
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-pod",
+					CreationTimestamp: metav1.Now(),
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:  "mondoo-resource-watcher",
+							Ready: true,
+							LastTerminationState: corev1.ContainerState{
+								Terminated: &corev1.ContainerStateTerminated{
+									ExitCode: 1,
+									Message:  "debouncer stalled",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	updateResourceWatcherConditions(config, false, pods)
+
+	cond := mondoo.FindMondooAuditConditions(config.Status.Conditions, v1alpha2.ResourceWatcherDegraded)
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, "ResourceWatcherAvailable", cond.Reason)
+}
+
+func TestConditions_UnhealthyExternalClusterWatcher(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{}
+	config.Spec.KubernetesResources.Enable = true
+	config.Spec.KubernetesResources.ResourceWatcher.Enable = true
+
+	watcherPod := func(name string, ls map[string]string, created time.Time, ready bool) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: ls, CreationTimestamp: metav1.NewTime(created)},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "mondoo-resource-watcher",
+					Ready: ready,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			},
+		}
+	}
+	pods := &corev1.PodList{
+		Items: []corev1.Pod{
+			watcherPod("external-pod", ExternalClusterDeploymentLabels(*config, "prod"), time.Now().Add(-time.Hour), false),
+			// The newest pod belongs to the healthy local watcher
+			watcherPod("local-pod", DeploymentLabels(*config), time.Now(), true),
+		},
+	}
+
+	updateResourceWatcherConditions(config, true, pods)
+
+	cond := mondoo.FindMondooAuditConditions(config.Status.Conditions, v1alpha2.ResourceWatcherDegraded)
+	assert.NotNil(t, cond)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, "ResourceWatcherUnhealthy", cond.Reason)
+	assert.Equal(t, notReadyMessage, cond.Message)
+}
@@ -0,0 +1,47 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// WatchCredentialsFiles polls the given files and returns an error once one of them changes, so the
+// watcher can restart with the new credentials. The informers keep the credentials they were started
+// with, which breaks watches against external clusters when short-lived credentials are refreshed.
+// It returns nil when the context is cancelled.
+func WatchCredentialsFiles(ctx context.Context, paths []string, interval time.Duration) error {
+	initial := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		// A missing file is treated as empty, it is a change once the file is created
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read credentials file %s: %w", path, err)
+		}
+		initial[path] = data
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				if err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to read credentials file %s: %w", path, err)
+				}
+				if !bytes.Equal(initial[path], data) {
+					return fmt.Errorf("credentials file %s changed", path)
+				}
+			}
+		}
+	}
+}
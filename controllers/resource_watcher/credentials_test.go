@@ -0,0 +1,46 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchCredentialsFiles_Change(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- WatchCredentialsFiles(context.Background(), []string{path}, 5*time.Millisecond)
+	}()
+
+	// Give the watcher time to read the initial content
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("new"), 0o600))
+
+	select {
+	case err := <-errChan:
+		assert.ErrorContains(t, err, "credentials file "+path+" changed")
+	case <-time.After(time.Second):
+		t.Fatal("change was not detected")
+	}
+}
+
+func TestWatchCredentialsFiles_ContextCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte("unchanged"), 0o600))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, WatchCredentialsFiles(ctx, []string{path}, 5*time.Millisecond))
+}
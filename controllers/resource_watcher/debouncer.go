@@ -26,6 +26,11 @@ type Debouncer struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	lastScanTime time.Time // time of the last completed scan
+	pendingSince time.Time // time the oldest pending resource was queued; zero if the queue is empty
+	pendingAdds  int       // number of Add calls since the last flush, including ones for already queued keys
+
+	consecutiveFailures int   // number of scans that failed in a row
+	lastScanErr         error // error of the last failed scan
 }
 
 // NewDebouncer creates a new Debouncer with the given intervals and scan function.
@@ -46,7 +51,11 @@ func (d *Debouncer) Add(key string, resource K8sResourceIdentifier) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	if len(d.pending) == 0 {
+		d.pendingSince = time.Now()
+	}
 	d.pending[key] = resource
+	d.pendingAdds++
 	debouncerLogger.V(1).Info("Added resource to debounce queue", "key", key, "resource", resource, "queueSize", len(d.pending))
 
 	// Reset the timer if it exists, or start a new one
@@ -95,6 +104,7 @@ func (d *Debouncer) flush() {
 		if elapsed < d.minInterval {
 			waitTime := d.minInterval - elapsed
 			debouncerLogger.Info("Rate limiting: rescheduling flush", "waitTime", waitTime, "pendingCount", len(d.pending))
+			rateLimitDeferralsTotal.Inc()
 			d.timer = time.AfterFunc(waitTime, d.flush)
 			d.mu.Unlock()
 			return
@@ -110,10 +120,23 @@ func (d *Debouncer) flush() {
 	}
 
 	// Clear pending
+	events := d.pendingAdds
 	d.pending = make(map[string]K8sResourceIdentifier)
+	d.pendingSince = time.Time{}
+	d.pendingAdds = 0
 	d.mu.Unlock()
 
-	debouncerLogger.Info("Flushing debounce queue", "resourceCount", len(resources))
+	// Events for resources that were already queued are merged into a single scan target
+	deduplicated := max(events-len(resources), 0)
+	ratio := 0.0
+	if events > 0 {
+		ratio = float64(deduplicated) / float64(events)
+	}
+	eventsDeduplicatedTotal.Add(float64(deduplicated))
+	dedupeRatio.Set(ratio)
+
+	debouncerLogger.Info("Flushing debounce queue", "resourceCount", len(resources), "eventCount", events, "dedupeRatio", ratio)
+	flushBatchSize.Observe(float64(len(resources)))
 
 	// Execute scan
 	ctx := d.ctx
@@ -121,18 +144,42 @@ func (d *Debouncer) flush() {
 		ctx = context.Background()
 	}
 
-	if err := d.scanFunc(ctx, resources); err != nil {
+	err := d.scanFunc(ctx, resources)
+	if err != nil {
 		debouncerLogger.Error(err, "Failed to scan resources", "keys", keys)
 	} else {
 		debouncerLogger.Info("Successfully scanned resources", "keys", keys)
 	}
 
-	// Update last scan time after scan completes
+	// Update last scan time and scan health after scan completes
 	d.mu.Lock()
 	d.lastScanTime = time.Now()
+	if err != nil {
+		d.consecutiveFailures++
+		d.lastScanErr = err
+	} else {
+		d.consecutiveFailures = 0
+		d.lastScanErr = nil
+	}
 	d.mu.Unlock()
 }
 
+// ConsecutiveFailures returns the number of scans that failed in a row together with the error
+// of the last failed scan. The count is reset by the next successful scan.
+func (d *Debouncer) ConsecutiveFailures() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.consecutiveFailures, d.lastScanErr
+}
+
+// PendingSince returns the time the oldest resource in the queue was added. It returns the zero
+// time if the queue is empty.
+func (d *Debouncer) PendingSince() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pendingSince
+}
+
 // QueueSize returns the current number of pending resources.
 func (d *Debouncer) QueueSize() int {
 	d.mu.Lock()
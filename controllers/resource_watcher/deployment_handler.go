@@ -5,6 +5,8 @@ package resource_watcher
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,13 +16,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/controllers/k8s_scan"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 )
 
 var deploymentHandlerLogger = ctrl.Log.WithName("resource-watcher-handler")
 
-// DeploymentHandler handles the reconciliation of the resource watcher deployment.
+// DeploymentHandler handles the reconciliation of the resource watcher deployments for the local and external clusters.
 type DeploymentHandler struct {
 	KubeClient             client.Client
 	Mondoo                 *v1alpha2.MondooAuditConfig
@@ -28,21 +31,40 @@ type DeploymentHandler struct {
 	MondooOperatorConfig   *v1alpha2.MondooOperatorConfig
 }
 
-// Reconcile ensures the resource watcher deployment matches the desired state.
+// Reconcile ensures the resource watcher deployments match the desired state.
 func (h *DeploymentHandler) Reconcile(ctx context.Context) (ctrl.Result, error) {
-	// Resource watcher is only enabled if K8s resources scanning is enabled AND resource watcher is enabled
-	if !h.Mondoo.Spec.KubernetesResources.Enable || !h.Mondoo.Spec.KubernetesResources.ResourceWatcher.Enable {
-		return ctrl.Result{}, h.down(ctx)
+	// The local resource watcher is only enabled if K8s resources scanning is enabled AND resource watcher is enabled
+	if !localWatcherEnabled(h.Mondoo) {
+		if err := h.downLocal(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if !anyWatcherEnabled(h.Mondoo) {
+		if err := h.cleanupExternalClusterDeployments(ctx, map[string]bool{}); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Clear any remnant status
+		updateResourceWatcherConditions(h.Mondoo, false, &corev1.PodList{})
+		return ctrl.Result{}, nil
 	}
 
-	if err := h.syncDeployment(ctx); err != nil {
+	if err := h.syncDeployments(ctx); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func (h *DeploymentHandler) syncDeployment(ctx context.Context) error {
+func (h *DeploymentHandler) syncDeployments(ctx context.Context) error {
+	if msg := h.invalidLabelSelectorMessage(); msg != "" {
+		// Retrying won't help until the spec is fixed, so report it and wait for the next change
+		deploymentHandlerLogger.Info("Invalid resource watcher label selector", "message", msg)
+		h.Mondoo.Status.Conditions = mondoo.SetMondooAuditCondition(
+			h.Mondoo.Status.Conditions, v1alpha2.ResourceWatcherDegraded, corev1.ConditionTrue, "ResourceWatcherInvalidConfig",
+			msg, mondoo.UpdateConditionIfReasonOrMessageChange, []string{}, "")
+		return nil
+	}
+
 	mondooClientImage, err := h.ContainerImageResolver.MondooOperatorImage(
 		ctx, h.Mondoo.Spec.Scanner.Image.Name, h.Mondoo.Spec.Scanner.Image.Tag, h.Mondoo.Spec.Scanner.Image.Digest, h.MondooOperatorConfig.Spec.SkipContainerResolution)
 	if err != nil {
@@ -62,12 +84,51 @@ func (h *DeploymentHandler) syncDeployment(ctx context.Context) error {
 		deploymentHandlerLogger.Info("Failed to get integration MRN, continuing without it", "error", err)
 	}
 
-	desired := Deployment(mondooClientImage, integrationMRN, clusterUID, h.Mondoo, *h.MondooOperatorConfig)
-	obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
-	if _, err := k8s.CreateOrUpdate(ctx, h.KubeClient, obj, h.Mondoo, deploymentHandlerLogger, func() error {
-		k8s.UpdateDeploymentFields(obj, desired)
-		return nil
-	}); err != nil {
+	if localWatcherEnabled(h.Mondoo) {
+		filtering, err := k8s.ResolveNamespaceFiltering(ctx, h.KubeClient, h.Mondoo.Spec.Filtering)
+		switch {
+		case errors.Is(err, k8s.ErrNoMatchingNamespaces):
+			// Nothing to watch until a matching namespace is created
+			deploymentHandlerLogger.Info("No namespaces match the namespace filtering, removing the local resource watcher")
+			if err := h.downLocal(ctx); err != nil {
+				return err
+			}
+		case err != nil:
+			deploymentHandlerLogger.Error(err, "Failed to resolve the namespace filtering")
+			return err
+		default:
+			routing, err := k8s.ResolveSpaceRouting(ctx, h.KubeClient, *h.Mondoo, filtering)
+			if err != nil {
+				deploymentHandlerLogger.Error(err, "Failed to resolve the space routing")
+				return err
+			}
+			if err := h.syncLocalDeployments(ctx, mondooClientImage, integrationMRN, clusterUID, routing); err != nil {
+				return err
+			}
+		}
+	}
+
+	configuredClusters := make(map[string]bool)
+	for _, cluster := range h.Mondoo.Spec.KubernetesResources.ExternalClusters {
+		if cluster.ResourceWatcher == nil || !cluster.ResourceWatcher.Enable {
+			continue
+		}
+		// The k8s_scan DeploymentHandler reports invalid configurations, just skip the cluster here
+		if err := k8s_scan.ValidateExternalClusterAuth(cluster); err != nil {
+			deploymentHandlerLogger.Error(err, "Skipping resource watcher for external cluster with invalid auth configuration", "cluster", cluster.Name)
+			continue
+		}
+		if err := k8s_scan.ValidateExternalClusterFiltering(cluster, *h.Mondoo); err != nil {
+			deploymentHandlerLogger.Error(err, "Skipping resource watcher for external cluster with invalid namespace filtering", "cluster", cluster.Name)
+			continue
+		}
+		configuredClusters[cluster.Name] = true
+		desired := ExternalClusterDeployment(mondooClientImage, integrationMRN, clusterUID, cluster, h.Mondoo, *h.MondooOperatorConfig)
+		if err := h.syncDeployment(ctx, desired); err != nil {
+			return err
+		}
+	}
+	if err := h.cleanupExternalClusterDeployments(ctx, configuredClusters); err != nil {
 		return err
 	}
 
@@ -77,17 +138,20 @@ func (h *DeploymentHandler) syncDeployment(ctx context.Context) error {
 		return err
 	}
 
-	// Get Pods for this Deployment
+	// Get Pods for the Deployments
 	pods := &corev1.PodList{}
 	if len(deployments) > 0 {
-		opts := &client.ListOptions{
-			Namespace:     h.Mondoo.Namespace,
-			LabelSelector: labels.SelectorFromSet(DeploymentLabels(*h.Mondoo)),
-		}
-		err = h.KubeClient.List(ctx, pods, opts)
-		if err != nil {
-			deploymentHandlerLogger.Error(err, "Failed to list Pods for Resource Watcher")
-			return err
+		for _, ls := range watcherLabels(*h.Mondoo) {
+			opts := &client.ListOptions{
+				Namespace:     h.Mondoo.Namespace,
+				LabelSelector: labels.SelectorFromSet(ls),
+			}
+			list := &corev1.PodList{}
+			if err := h.KubeClient.List(ctx, list, opts); err != nil {
+				deploymentHandlerLogger.Error(err, "Failed to list Pods for Resource Watcher")
+				return err
+			}
+			pods.Items = append(pods.Items, list.Items...)
 		}
 	}
 
@@ -95,6 +159,69 @@ func (h *DeploymentHandler) syncDeployment(ctx context.Context) error {
 	return nil
 }
 
+// syncLocalDeployments syncs the resource watchers of the local cluster: one for the default space and one for
+// every space of spec.spaceRouting.
+func (h *DeploymentHandler) syncLocalDeployments(ctx context.Context, image, integrationMRN, clusterUID string, routing k8s.SpaceRouting) error {
+	// Without an Include list the default watcher would watch all namespaces, so it is removed if all of
+	// them are routed to other spaces
+	if routing.DefaultEmpty {
+		if err := h.downDefaultSpace(ctx); err != nil {
+			return err
+		}
+	} else {
+		// The watcher gets the namespace names the filtering resolved to
+		m := h.Mondoo.DeepCopy()
+		m.Spec.Filtering = routing.Default
+		if err := h.syncDeployment(ctx, Deployment(image, integrationMRN, clusterUID, m, *h.MondooOperatorConfig)); err != nil {
+			return err
+		}
+	}
+
+	activeSpaces := make(map[string]bool)
+	for _, route := range routing.Routes {
+		if err := h.syncDeployment(ctx, SpaceDeployment(image, integrationMRN, clusterUID, h.Mondoo, route, *h.MondooOperatorConfig)); err != nil {
+			return err
+		}
+		activeSpaces[route.SpaceID] = true
+	}
+	return h.cleanupSpaceDeployments(ctx, activeSpaces)
+}
+
+func (h *DeploymentHandler) syncDeployment(ctx context.Context, desired *appsv1.Deployment) error {
+	// Roll the watcher when the credentials or another Secret it uses change
+	if err := k8s.SetSecretsHashAnnotation(ctx, h.KubeClient, desired.Namespace, &desired.Spec.Template); err != nil {
+		deploymentHandlerLogger.Error(err, "Failed to hash the Secrets of the resource watcher", "deployment", desired.Name)
+		return err
+	}
+	obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	_, err := k8s.CreateOrUpdate(ctx, h.KubeClient, obj, h.Mondoo, deploymentHandlerLogger, func() error {
+		k8s.UpdateDeploymentFields(obj, desired)
+		return nil
+	})
+	return err
+}
+
+// invalidLabelSelectorMessage returns a message describing the first invalid label selector of an enabled
+// resource watcher, or an empty string if all of them are valid.
+func (h *DeploymentHandler) invalidLabelSelectorMessage() string {
+	if localWatcherEnabled(h.Mondoo) {
+		if ls := h.Mondoo.Spec.KubernetesResources.ResourceWatcher.LabelSelector; ls != nil {
+			if _, err := metav1.LabelSelectorAsSelector(ls); err != nil {
+				return "Resource Watcher labelSelector is invalid: " + err.Error()
+			}
+		}
+	}
+	for _, cluster := range h.Mondoo.Spec.KubernetesResources.ExternalClusters {
+		if cluster.ResourceWatcher == nil || !cluster.ResourceWatcher.Enable || cluster.ResourceWatcher.LabelSelector == nil {
+			continue
+		}
+		if _, err := metav1.LabelSelectorAsSelector(cluster.ResourceWatcher.LabelSelector); err != nil {
+			return fmt.Sprintf("Resource Watcher labelSelector for external cluster %s is invalid: %s", cluster.Name, err)
+		}
+	}
+	return ""
+}
+
 // areDeploymentsReady checks if all deployments have their desired replicas available.
 func areDeploymentsReady(deployments []appsv1.Deployment) bool {
 	for _, d := range deployments {
@@ -110,18 +237,83 @@ func areDeploymentsReady(deployments []appsv1.Deployment) bool {
 }
 
 func (h *DeploymentHandler) getDeploymentsForAuditConfig(ctx context.Context) ([]appsv1.Deployment, error) {
+	var result []appsv1.Deployment
+	for _, ls := range watcherLabels(*h.Mondoo) {
+		deployments := &appsv1.DeploymentList{}
+		listOpts := &client.ListOptions{Namespace: h.Mondoo.Namespace, LabelSelector: labels.SelectorFromSet(ls)}
+		if err := h.KubeClient.List(ctx, deployments, listOpts); err != nil {
+			deploymentHandlerLogger.Error(err, "Failed to list Deployments in namespace", "namespace", h.Mondoo.Namespace)
+			return nil, err
+		}
+		result = append(result, deployments.Items...)
+	}
+	return result, nil
+}
+
+// cleanupExternalClusterDeployments deletes the resource watcher Deployments of external clusters that are
+// no longer configured or have the resource watcher disabled.
+func (h *DeploymentHandler) cleanupExternalClusterDeployments(ctx context.Context, configuredClusters map[string]bool) error {
 	deployments := &appsv1.DeploymentList{}
-	deploymentLabels := DeploymentLabels(*h.Mondoo)
+	listOpts := &client.ListOptions{
+		Namespace:     h.Mondoo.Namespace,
+		LabelSelector: labels.SelectorFromSet(externalClusterWatcherLabels(*h.Mondoo)),
+	}
+	if err := h.KubeClient.List(ctx, deployments, listOpts); err != nil {
+		deploymentHandlerLogger.Error(err, "Failed to list external cluster resource watcher Deployments")
+		return err
+	}
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if configuredClusters[d.Labels["cluster_name"]] {
+			continue
+		}
+		if err := k8s.DeleteIfExists(ctx, h.KubeClient, d); err != nil {
+			deploymentHandlerLogger.Error(
+				err, "failed to clean up external cluster resource watcher Deployment", "namespace", d.Namespace, "name", d.Name)
+			return err
+		}
+		deploymentHandlerLogger.Info("Deleted orphaned external cluster resource watcher Deployment", "name", d.Name)
+	}
+	return nil
+}
 
-	listOpts := &client.ListOptions{Namespace: h.Mondoo.Namespace, LabelSelector: labels.SelectorFromSet(deploymentLabels)}
+// cleanupSpaceDeployments deletes the resource watcher Deployments of routed spaces that are not in activeSpaces.
+func (h *DeploymentHandler) cleanupSpaceDeployments(ctx context.Context, activeSpaces map[string]bool) error {
+	deployments := &appsv1.DeploymentList{}
+	listOpts := &client.ListOptions{
+		Namespace:     h.Mondoo.Namespace,
+		LabelSelector: labels.SelectorFromSet(spaceWatcherLabels(*h.Mondoo)),
+	}
 	if err := h.KubeClient.List(ctx, deployments, listOpts); err != nil {
-		deploymentHandlerLogger.Error(err, "Failed to list Deployments in namespace", "namespace", h.Mondoo.Namespace)
-		return nil, err
+		deploymentHandlerLogger.Error(err, "Failed to list space resource watcher Deployments")
+		return err
 	}
-	return deployments.Items, nil
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if activeSpaces[d.Labels[k8s.SpaceIDLabel]] {
+			continue
+		}
+		if err := k8s.DeleteIfExists(ctx, h.KubeClient, d); err != nil {
+			deploymentHandlerLogger.Error(
+				err, "failed to clean up space resource watcher Deployment", "namespace", d.Namespace, "name", d.Name)
+			return err
+		}
+		deploymentHandlerLogger.Info("Deleted resource watcher Deployment of a space that is no longer routed", "name", d.Name)
+	}
+	return nil
 }
 
-func (h *DeploymentHandler) down(ctx context.Context) error {
+func (h *DeploymentHandler) downLocal(ctx context.Context) error {
+	if err := h.downDefaultSpace(ctx); err != nil {
+		return err
+	}
+	return h.cleanupSpaceDeployments(ctx, nil)
+}
+
+// downDefaultSpace deletes the local resource watcher Deployment of the default space.
+func (h *DeploymentHandler) downDefaultSpace(ctx context.Context) error {
 	// Delete Deployment
 	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: DeploymentName(h.Mondoo.Name), Namespace: h.Mondoo.Namespace}}
 	if err := k8s.DeleteIfExists(ctx, h.KubeClient, deployment); err != nil {
@@ -129,9 +321,5 @@ func (h *DeploymentHandler) down(ctx context.Context) error {
 			err, "failed to clean up resource watcher Deployment", "namespace", deployment.Namespace, "name", deployment.Name)
 		return err
 	}
-
-	// Clear any remnant status
-	updateResourceWatcherConditions(h.Mondoo, false, &corev1.PodList{})
-
 	return nil
 }
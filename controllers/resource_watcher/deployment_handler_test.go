@@ -0,0 +1,172 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
+	fakeMondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo/fake"
+	"go.mondoo.com/mondoo-operator/pkg/utils/test"
+	"go.mondoo.com/mondoo-operator/tests/framework/utils"
+)
+
+type DeploymentHandlerSuite struct {
+	suite.Suite
+	ctx    context.Context
+	scheme *runtime.Scheme
+
+	auditConfig mondoov1alpha2.MondooAuditConfig
+	kubeClient  client.Client
+}
+
+func (s *DeploymentHandlerSuite) SetupSuite() {
+	s.ctx = context.Background()
+	s.scheme = clientgoscheme.Scheme
+	s.Require().NoError(mondoov1alpha2.AddToScheme(s.scheme))
+}
+
+func (s *DeploymentHandlerSuite) BeforeTest(suiteName, testName string) {
+	s.auditConfig = utils.DefaultAuditConfig("mondoo-operator", true, false, false)
+	s.auditConfig.Spec.KubernetesResources.ResourceWatcher.Enable = true
+	s.auditConfig.Spec.KubernetesResources.ExternalClusters = []mondoov1alpha2.ExternalCluster{
+		{
+			Name:                "prod",
+			KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "prod-kubeconfig"},
+			ResourceWatcher:     &mondoov1alpha2.ResourceWatcherSpec{Enable: true},
+		},
+		{
+			Name:                "staging",
+			KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "staging-kubeconfig"},
+		},
+	}
+	s.kubeClient = fake.NewClientBuilder().
+		WithScheme(s.scheme).
+		WithObjects(test.TestKubeSystemNamespace(), &s.auditConfig).
+		Build()
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_ExternalClusters() {
+	s.reconcile()
+
+	s.Equal([]string{
+		DeploymentName(s.auditConfig.Name),
+		ExternalClusterDeploymentName(s.auditConfig.Name, "prod"),
+	}, s.deploymentNames())
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_OnlyExternalClusters() {
+	s.auditConfig.Spec.KubernetesResources.Enable = false
+	s.reconcile()
+
+	s.Equal([]string{ExternalClusterDeploymentName(s.auditConfig.Name, "prod")}, s.deploymentNames())
+
+	cond := mondoo.FindMondooAuditConditions(s.auditConfig.Status.Conditions, mondoov1alpha2.ResourceWatcherDegraded)
+	s.Require().NotNil(cond)
+	s.NotEqual("ResourceWatcherDisabled", cond.Reason)
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_RemovesOrphanedExternalClusters() {
+	s.reconcile()
+	s.Len(s.deploymentNames(), 2)
+
+	s.auditConfig.Spec.KubernetesResources.ExternalClusters[0].ResourceWatcher.Enable = false
+	s.reconcile()
+	s.Equal([]string{DeploymentName(s.auditConfig.Name)}, s.deploymentNames())
+
+	s.auditConfig.Spec.KubernetesResources.ResourceWatcher.Enable = false
+	s.reconcile()
+	s.Empty(s.deploymentNames())
+
+	cond := mondoo.FindMondooAuditConditions(s.auditConfig.Status.Conditions, mondoov1alpha2.ResourceWatcherDegraded)
+	s.Require().NotNil(cond)
+	s.Equal("ResourceWatcherDisabled", cond.Reason)
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_SkipsInvalidAuth() {
+	s.auditConfig.Spec.KubernetesResources.ExternalClusters[0].KubeconfigSecretRef = nil
+	s.reconcile()
+
+	s.Equal([]string{DeploymentName(s.auditConfig.Name)}, s.deploymentNames())
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_NamespacePatterns() {
+	s.auditConfig.Spec.Filtering.Namespaces.Include = []string{"kube-*"}
+	s.reconcile()
+
+	// The external cluster inherits the patterns, which cannot be resolved for it
+	s.Equal([]string{DeploymentName(s.auditConfig.Name)}, s.deploymentNames())
+	deployment := &appsv1.Deployment{}
+	s.Require().NoError(s.kubeClient.Get(s.ctx, client.ObjectKey{Namespace: s.auditConfig.Namespace, Name: DeploymentName(s.auditConfig.Name)}, deployment))
+	s.Contains(strings.Join(deployment.Spec.Template.Spec.Containers[0].Command, " "), "--namespaces kube-system")
+
+	s.auditConfig.Spec.Filtering.Namespaces.Include = []string{"team-*"}
+	s.reconcile()
+	s.Empty(s.deploymentNames())
+}
+
+func (s *DeploymentHandlerSuite) TestReconcile_RollsOnSecretChange() {
+	creds := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: s.auditConfig.Spec.MondooCredsSecretRef.Name, Namespace: s.auditConfig.Namespace},
+		Data:       map[string][]byte{"config": []byte(`{"mrn":"old"}`)},
+	}
+	s.Require().NoError(s.kubeClient.Create(s.ctx, creds))
+	s.reconcile()
+	initial := s.secretsHash(DeploymentName(s.auditConfig.Name))
+	s.NotEmpty(initial)
+
+	s.reconcile()
+	s.Equal(initial, s.secretsHash(DeploymentName(s.auditConfig.Name)))
+
+	creds.Data["config"] = []byte(`{"mrn":"new"}`)
+	s.Require().NoError(s.kubeClient.Update(s.ctx, creds))
+	s.reconcile()
+	s.NotEqual(initial, s.secretsHash(DeploymentName(s.auditConfig.Name)))
+}
+
+func (s *DeploymentHandlerSuite) secretsHash(name string) string {
+	deployment := &appsv1.Deployment{}
+	s.Require().NoError(s.kubeClient.Get(s.ctx, client.ObjectKey{Namespace: s.auditConfig.Namespace, Name: name}, deployment))
+	return deployment.Spec.Template.Annotations[k8s.SecretsHashAnnotation]
+}
+
+func (s *DeploymentHandlerSuite) reconcile() {
+	d := DeploymentHandler{
+		KubeClient:             s.kubeClient,
+		Mondoo:                 &s.auditConfig,
+		ContainerImageResolver: fakeMondoo.NewNoOpContainerImageResolver(),
+		MondooOperatorConfig:   &mondoov1alpha2.MondooOperatorConfig{},
+	}
+	result, err := d.Reconcile(s.ctx)
+	s.Require().NoError(err)
+	s.True(result.IsZero())
+}
+
+func (s *DeploymentHandlerSuite) deploymentNames() []string {
+	deployments := &appsv1.DeploymentList{}
+	s.Require().NoError(s.kubeClient.List(s.ctx, deployments))
+	names := []string{}
+	for _, d := range deployments.Items {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func TestDeploymentHandlerSuite(t *testing.T) {
+	suite.Run(t, new(DeploymentHandlerSuite))
+}
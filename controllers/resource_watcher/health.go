@@ -0,0 +1,79 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HealthProbePort is the port the resource watcher serves /healthz and /readyz on.
+const HealthProbePort = 8081
+
+// HealthChecker implements the liveness and readiness checks of the resource watcher.
+type HealthChecker struct {
+	watcher   *ResourceWatcher
+	debouncer *Debouncer
+	// maxConsecutiveScanFailures is the number of scans failing in a row after which the watcher
+	// is reported unhealthy. Set to 0 to disable the check.
+	maxConsecutiveScanFailures int
+	// stallTimeout is how long resources may wait in the debounce queue before the debouncer is
+	// considered stalled. Set to 0 to disable the check.
+	stallTimeout time.Duration
+}
+
+// NewHealthChecker creates a new HealthChecker for the given watcher and debouncer.
+func NewHealthChecker(watcher *ResourceWatcher, debouncer *Debouncer, maxConsecutiveScanFailures int, stallTimeout time.Duration) *HealthChecker {
+	return &HealthChecker{
+		watcher:                    watcher,
+		debouncer:                  debouncer,
+		maxConsecutiveScanFailures: maxConsecutiveScanFailures,
+		stallTimeout:               stallTimeout,
+	}
+}
+
+// InformersSynced is a readiness check that passes once all informers report HasSynced.
+func (h *HealthChecker) InformersSynced(_ *http.Request) error {
+	if !h.watcher.HasSynced() {
+		return fmt.Errorf("informers have not synced yet")
+	}
+	return nil
+}
+
+// ScansHealthy is a liveness check that fails after too many consecutive cnspec scan failures.
+func (h *HealthChecker) ScansHealthy(_ *http.Request) error {
+	if h.maxConsecutiveScanFailures <= 0 {
+		return nil
+	}
+	if failures, lastErr := h.debouncer.ConsecutiveFailures(); failures >= h.maxConsecutiveScanFailures {
+		return fmt.Errorf("%d consecutive cnspec scans failed, last error: %w", failures, lastErr)
+	}
+	return nil
+}
+
+// DebouncerHealthy is a liveness check that fails if resources have been waiting in the debounce
+// queue for longer than the stall timeout.
+func (h *HealthChecker) DebouncerHealthy(_ *http.Request) error {
+	if h.stallTimeout <= 0 {
+		return nil
+	}
+	pendingSince := h.debouncer.PendingSince()
+	if pendingSince.IsZero() {
+		return nil
+	}
+	if waiting := time.Since(pendingSince); waiting > h.stallTimeout {
+		return fmt.Errorf("debouncer stalled: %d resources have been queued for %s without a scan",
+			h.debouncer.QueueSize(), waiting.Round(time.Second))
+	}
+	return nil
+}
+
+// Healthy runs all liveness checks and returns the first failure.
+func (h *HealthChecker) Healthy() error {
+	if err := h.ScansHealthy(nil); err != nil {
+		return err
+	}
+	return h.DebouncerHealthy(nil)
+}
@@ -0,0 +1,112 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopScan(ctx context.Context, resources []K8sResourceIdentifier) error {
+	return nil
+}
+
+func TestHealthChecker_InformersSynced(t *testing.T) {
+	w := &ResourceWatcher{}
+	h := NewHealthChecker(w, NewDebouncer(time.Second, 0, noopScan), 5, time.Minute)
+
+	// Not started yet
+	assert.Error(t, h.InformersSynced(nil))
+
+	w.started = true
+	assert.NoError(t, h.InformersSynced(nil))
+}
+
+func TestHealthChecker_ScansHealthy(t *testing.T) {
+	scanErr := errors.New("cnspec exited with code 1")
+	scanFunc := func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return scanErr
+	}
+
+	d := NewDebouncer(10*time.Millisecond, 0, scanFunc)
+	h := NewHealthChecker(&ResourceWatcher{}, d, 2, 0)
+
+	assert.NoError(t, h.ScansHealthy(nil))
+
+	d.Add("default/pods/test1", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test1"})
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, h.ScansHealthy(nil), "a single failure should not fail the check")
+
+	d.Add("default/pods/test2", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test2"})
+	time.Sleep(50 * time.Millisecond)
+
+	err := h.ScansHealthy(nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, scanErr)
+	assert.Contains(t, err.Error(), "2 consecutive cnspec scans failed")
+	assert.Equal(t, err, h.Healthy())
+}
+
+func TestHealthChecker_ScansRecover(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	scanFunc := func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		if fail.Load() {
+			return errors.New("scan failed")
+		}
+		return nil
+	}
+
+	d := NewDebouncer(10*time.Millisecond, 0, scanFunc)
+	h := NewHealthChecker(&ResourceWatcher{}, d, 1, 0)
+
+	d.Add("default/pods/test1", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test1"})
+	time.Sleep(50 * time.Millisecond)
+	assert.Error(t, h.ScansHealthy(nil))
+
+	fail.Store(false)
+	d.Add("default/pods/test1", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test1"})
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, h.ScansHealthy(nil))
+
+	failures, lastErr := d.ConsecutiveFailures()
+	assert.Equal(t, 0, failures)
+	assert.NoError(t, lastErr)
+}
+
+func TestHealthChecker_DebouncerStalled(t *testing.T) {
+	// Long debounce interval so the queue is not flushed during the test
+	d := NewDebouncer(time.Hour, 0, noopScan)
+	h := NewHealthChecker(&ResourceWatcher{}, d, 0, 20*time.Millisecond)
+
+	assert.NoError(t, h.DebouncerHealthy(nil), "an empty queue is never stalled")
+
+	d.Add("default/pods/test1", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test1"})
+	assert.NoError(t, h.DebouncerHealthy(nil))
+
+	time.Sleep(50 * time.Millisecond)
+	err := h.DebouncerHealthy(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "debouncer stalled: 1 resources have been queued")
+	assert.Error(t, h.Healthy())
+
+	d.stop()
+}
+
+func TestHealthChecker_Disabled(t *testing.T) {
+	d := NewDebouncer(time.Hour, 0, noopScan)
+	h := NewHealthChecker(&ResourceWatcher{}, d, 0, 0)
+
+	d.Add("default/pods/test1", K8sResourceIdentifier{Type: "pod", Namespace: "default", Name: "test1"})
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, h.Healthy())
+
+	d.stop()
+}
@@ -0,0 +1,205 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace = "mondoo_resource_watcher"
+
+	// MetricsPort is the port the resource watcher serves Prometheus metrics on.
+	MetricsPort = 8080
+	// MetricsPortName is the name of the metrics container and service port.
+	MetricsPortName = "metrics"
+)
+
+// Reasons used for the events_filtered_total metric.
+const (
+	filterReasonNamespace     = "namespace"
+	filterReasonAnnotation    = "annotation"
+	filterReasonLabelSelector = "label_selector"
+	filterReasonStandalonePod = "standalone_pod"
+)
+
+// Results used for the purge_requests_total metric.
+const (
+	purgeResultSuccess = "success"
+	purgeResultFailure = "failure"
+)
+
+// Triggers used for the syncs_total metric.
+const (
+	syncTriggerInitial  = "initial"
+	syncTriggerPeriodic = "periodic"
+)
+
+// metricsRegistry is a dedicated registry for the resource watcher. The operator binary also
+// links this package, so registering with the controller-runtime registry would make the operator
+// export resource watcher metrics that are never updated.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	eventsReceivedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_received_total",
+			Help:      "Number of resource events received from the informers.",
+		},
+		[]string{"event_type", "resource_type", "namespace"},
+	)
+
+	eventsFilteredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_filtered_total",
+			Help:      "Number of resource events dropped by filters before reaching the debounce queue.",
+		},
+		[]string{"resource_type", "reason"},
+	)
+
+	eventsResolvedToOwnerTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_resolved_to_owner_total",
+			Help:      "Number of resource events that were replaced by an event for their top-level owner.",
+		},
+		[]string{"resource_type", "owner_type"},
+	)
+
+	eventsDeduplicatedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "events_deduplicated_total",
+			Help:      "Number of queued resource events that were merged with an event for the same resource before a scan.",
+		},
+	)
+
+	dedupeRatio = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "dedupe_ratio",
+			Help:      "Fraction of the events queued for the last scan that were merged into another event (0 to 1).",
+		},
+	)
+
+	flushBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "flush_batch_size",
+			Help:      "Number of resources handed to a single scan when the debounce queue is flushed.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		},
+	)
+
+	rateLimitDeferralsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rate_limit_deferrals_total",
+			Help:      "Number of flushes that were postponed because the minimum scan interval had not elapsed.",
+		},
+	)
+
+	scanDurationSeconds = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_duration_seconds",
+			Help:      "Duration of cnspec scans triggered by the resource watcher.",
+			Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800},
+		},
+	)
+
+	scanFailuresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_failures_total",
+			Help:      "Number of cnspec scans that failed, including timeouts.",
+		},
+	)
+
+	scanTimeoutsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scan_timeouts_total",
+			Help:      "Number of cnspec scans that were aborted because they exceeded the scan timeout.",
+		},
+	)
+
+	purgeRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "purge_requests_total",
+			Help:      "Number of requests to delete the assets of deleted resources in Mondoo Platform, by result.",
+		},
+		[]string{"result"},
+	)
+
+	assetsPurgedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "assets_purged_total",
+			Help:      "Number of assets deleted in Mondoo Platform because their resource was deleted.",
+		},
+	)
+
+	syncsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "syncs_total",
+			Help:      "Number of times all watched resources were queued for scanning, by trigger.",
+		},
+		[]string{"trigger"},
+	)
+
+	syncQueuedResources = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "sync_queued_resources",
+			Help:      "Number of resources queued for scanning by the last initial scan or resync.",
+		},
+	)
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		eventsReceivedTotal,
+		eventsFilteredTotal,
+		eventsResolvedToOwnerTotal,
+		eventsDeduplicatedTotal,
+		dedupeRatio,
+		flushBatchSize,
+		rateLimitDeferralsTotal,
+		scanDurationSeconds,
+		scanFailuresTotal,
+		scanTimeoutsTotal,
+		purgeRequestsTotal,
+		assetsPurgedTotal,
+		syncsTotal,
+		syncQueuedResources,
+	)
+}
+
+// RegisterQueueDepthMetric exposes the number of resources pending in the debouncer's queue as a gauge.
+func RegisterQueueDepthMetric(d *Debouncer) error {
+	return metricsRegistry.Register(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "queue_depth",
+			Help:      "Number of resources waiting in the debounce queue.",
+		},
+		func() float64 { return float64(d.QueueSize()) },
+	))
+}
+
+// MetricsHandler returns an http.Handler that serves the resource watcher metrics.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
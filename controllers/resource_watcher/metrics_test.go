@@ -0,0 +1,58 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMetrics_EventsReceivedAndFiltered(t *testing.T) {
+	w := NewResourceWatcher(nil, NewDebouncer(time.Hour, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return nil
+	}), WatcherConfig{NamespacesExclude: []string{"kube-system"}})
+	h := &resourceEventHandler{watcher: w, resourceType: "deployments"}
+
+	received := testutil.ToFloat64(eventsReceivedTotal.WithLabelValues("update", "deployments", "kube-system"))
+	filtered := testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("deployments", filterReasonNamespace))
+
+	h.handleEvent(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}}, "update")
+
+	assert.Equal(t, received+1, testutil.ToFloat64(eventsReceivedTotal.WithLabelValues("update", "deployments", "kube-system")))
+	assert.Equal(t, filtered+1, testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("deployments", filterReasonNamespace)))
+	assert.Equal(t, 0, w.debouncer.QueueSize())
+}
+
+func TestMetrics_RateLimitDeferrals(t *testing.T) {
+	d := NewDebouncer(10*time.Millisecond, time.Hour, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return nil
+	})
+
+	before := testutil.ToFloat64(rateLimitDeferralsTotal)
+
+	d.Add("default/deployments/a", K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "a"})
+	time.Sleep(50 * time.Millisecond)
+	d.Add("default/deployments/b", K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "b"})
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(rateLimitDeferralsTotal))
+	assert.Equal(t, 1, d.QueueSize())
+}
+
+func TestMetricsHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	require.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), "mondoo_resource_watcher_rate_limit_deferrals_total")
+	assert.Contains(t, rec.Body.String(), "mondoo_resource_watcher_scan_duration_seconds")
+}
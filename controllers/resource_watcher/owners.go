@@ -0,0 +1,107 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+)
+
+// maxOwnerDepth limits how many ownerReferences are followed, guarding against reference cycles.
+const maxOwnerDepth = 10
+
+// scannableOwnerKinds maps the owner kinds cnspec can scan to their resource type, keyed by API group.
+var scannableOwnerKinds = map[string]map[string]string{
+	"": {
+		"Pod": "pods",
+	},
+	"apps": {
+		"Deployment":  "deployments",
+		"DaemonSet":   "daemonsets",
+		"StatefulSet": "statefulsets",
+		"ReplicaSet":  "replicasets",
+	},
+	"batch": {
+		"Job":     "jobs",
+		"CronJob": "cronjobs",
+	},
+}
+
+// ownerResolver resolves resources to the top-level controller that owns them, so a rollout that
+// touches a Deployment, its ReplicaSet and all of its Pods results in a single scan of the Deployment.
+type ownerResolver struct {
+	reader client.Reader
+	// watchedKinds are the kinds the reader's cache holds. Owners of other kinds aren't read, so resolving
+	// doesn't start informers for them or wait for kinds the watcher may not be allowed to list.
+	watchedKinds map[schema.GroupKind]bool
+}
+
+// newOwnerResolver creates an ownerResolver that reads the owners of the watched resource types from reader.
+func newOwnerResolver(reader client.Reader, resourceTypes []string) *ownerResolver {
+	watchedKinds := make(map[schema.GroupKind]bool, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		obj, err := metadataObjectForResourceType(resourceType)
+		if err != nil {
+			continue
+		}
+		watchedKinds[obj.GetObjectKind().GroupVersionKind().GroupKind()] = true
+	}
+	return &ownerResolver{reader: reader, watchedKinds: watchedKinds}
+}
+
+// resolveRoot follows the controller ownerReferences of obj and returns the top-most owner that cnspec
+// can scan. Owners of other kinds (e.g. custom resources) are never returned. Only owners of watched kinds
+// are read, resolution stops at the first owner of another kind. owned reports whether obj has a controlling
+// owner at all, optedOut whether one of the owners that were read opted out of scanning.
+func (r *ownerResolver) resolveRoot(ctx context.Context, obj client.Object, resourceType string) (root K8sResourceIdentifier, owned, optedOut bool) {
+	root = K8sResourceIdentifier{Type: resourceType, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+	current := obj
+	for range maxOwnerDepth {
+		ref := metav1.GetControllerOfNoCopy(current)
+		if ref == nil {
+			break
+		}
+		owned = true
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			watcherLogger.V(1).Info("Ignoring owner with invalid apiVersion", "apiVersion", ref.APIVersion, "kind", ref.Kind, "name", ref.Name)
+			break
+		}
+
+		// Owners are always in the same namespace as the objects they own
+		scannableType, scannable := scannableOwnerKinds[gv.Group][ref.Kind]
+		if scannable {
+			root = K8sResourceIdentifier{Type: scannableType, Namespace: current.GetNamespace(), Name: ref.Name}
+		}
+
+		gvk := gv.WithKind(ref.Kind)
+		if !r.watchedKinds[gvk.GroupKind()] {
+			watcherLogger.V(1).Info("Owner kind isn't watched, stopping owner resolution",
+				"kind", ref.Kind, "namespace", current.GetNamespace(), "name", ref.Name)
+			break
+		}
+
+		owner := &metav1.PartialObjectMetadata{}
+		owner.SetGroupVersionKind(gvk)
+		err = r.reader.Get(ctx, client.ObjectKey{Namespace: current.GetNamespace(), Name: ref.Name}, owner)
+		if err != nil {
+			// The owner may already be gone or not be readable. Stop here and scan what we resolved so far.
+			watcherLogger.V(1).Info("Failed to get owner, stopping owner resolution",
+				"kind", ref.Kind, "namespace", current.GetNamespace(), "name", ref.Name, "error", err.Error())
+			break
+		}
+		if k8s.ScanOptedOut(owner.GetAnnotations()) {
+			optedOut = true
+		}
+		current = owner
+	}
+	return root, owned, optedOut
+}
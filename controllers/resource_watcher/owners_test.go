@@ -0,0 +1,226 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func controllerRef(apiVersion, kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: types.UID("uid-" + name), Controller: ptr.To(true)}}
+}
+
+func TestOwnerResolver_DeploymentChain(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "Deployment", "web"),
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8-abcde", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+	}}
+
+	r := newOwnerResolver(fake.NewClientBuilder().WithObjects(deployment, replicaSet).Build(), DefaultResourceTypes)
+
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, root)
+
+	root, owned, _ = r.resolveRoot(context.Background(), replicaSet, "replicasets")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, root)
+
+	root, owned, _ = r.resolveRoot(context.Background(), deployment, "deployments")
+	assert.False(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, root)
+}
+
+func TestOwnerResolver_CronJobChain(t *testing.T) {
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: "backup-28000000", Namespace: "default", OwnerReferences: controllerRef("batch/v1", "CronJob", "backup"),
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "backup-28000000-xyz", Namespace: "default", OwnerReferences: controllerRef("batch/v1", "Job", "backup-28000000"),
+	}}
+
+	r := newOwnerResolver(fake.NewClientBuilder().WithObjects(cronJob, job).Build(), DefaultResourceTypes)
+
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "cronjobs", Namespace: "default", Name: "backup"}, root)
+}
+
+func TestOwnerResolver_CustomOwner(t *testing.T) {
+	rollout := &unstructured.Unstructured{}
+	rollout.SetAPIVersion("argoproj.io/v1alpha1")
+	rollout.SetKind("Rollout")
+	rollout.SetName("web")
+	rollout.SetNamespace("default")
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8", Namespace: "default", OwnerReferences: controllerRef("argoproj.io/v1alpha1", "Rollout", "web"),
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8-abcde", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+	}}
+
+	reader := &recordingReader{Reader: fake.NewClientBuilder().WithObjects(rollout, replicaSet).Build()}
+	r := newOwnerResolver(reader, DefaultResourceTypes)
+
+	// cnspec cannot scan the Rollout, so the ReplicaSet is the top-most scannable owner. The Rollout isn't
+	// watched, so it isn't read.
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "replicasets", Namespace: "default", Name: "web-5d4f8"}, root)
+	assert.Equal(t, []string{"ReplicaSet/web-5d4f8"}, reader.gets)
+}
+
+func TestOwnerResolver_UnwatchedOwnerKind(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8-abcde", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+	}}
+	reader := &recordingReader{Reader: fake.NewClientBuilder().Build()}
+	r := newOwnerResolver(reader, []string{"pods"})
+
+	// The reference names the ReplicaSet, but it isn't read because only Pods are watched
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "replicasets", Namespace: "default", Name: "web-5d4f8"}, root)
+	assert.Empty(t, reader.gets)
+}
+
+// recordingReader records the kinds and names of the objects that are read.
+type recordingReader struct {
+	client.Reader
+	gets []string
+}
+
+func (r *recordingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets = append(r.gets, obj.GetObjectKind().GroupVersionKind().Kind+"/"+key.Name)
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestOwnerResolver_MissingOwner(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8-abcde", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+	}}
+
+	r := newOwnerResolver(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), DefaultResourceTypes)
+
+	// The ReplicaSet is already gone, but the reference still tells us what to scan
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.True(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "replicasets", Namespace: "default", Name: "web-5d4f8"}, root)
+}
+
+func TestOwnerResolver_NonControllerOwner(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "debug", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "uid"}},
+	}}
+
+	r := newOwnerResolver(fake.NewClientBuilder().Build(), DefaultResourceTypes)
+
+	root, owned, _ := r.resolveRoot(context.Background(), pod, "pods")
+	assert.False(t, owned)
+	assert.Equal(t, K8sResourceIdentifier{Type: "pods", Namespace: "default", Name: "debug"}, root)
+}
+
+func TestHandleEvent_ResolveOwners(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f8", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "Deployment", "web"),
+	}}
+	objs := []client.Object{deployment, replicaSet}
+
+	var received []K8sResourceIdentifier
+	d := NewDebouncer(time.Hour, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		received = resources
+		return nil
+	})
+	w := NewResourceWatcher(nil, d, WatcherConfig{ResolveOwners: true})
+	w.owners = newOwnerResolver(fake.NewClientBuilder().WithObjects(objs...).Build(), DefaultResourceTypes)
+
+	resolvedBefore := testutil.ToFloat64(eventsResolvedToOwnerTotal.WithLabelValues("pods", "deployments"))
+	standaloneBefore := testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("pods", filterReasonStandalonePod))
+	dedupedBefore := testutil.ToFloat64(eventsDeduplicatedTotal)
+
+	deployments := &resourceEventHandler{watcher: w, resourceType: "deployments"}
+	replicaSets := &resourceEventHandler{watcher: w, resourceType: "replicasets"}
+	pods := &resourceEventHandler{watcher: w, resourceType: "pods"}
+
+	deployments.handleEvent(deployment, "update")
+	replicaSets.handleEvent(replicaSet, "add")
+	for _, name := range []string{"web-5d4f8-a", "web-5d4f8-b", "web-5d4f8-c"} {
+		pods.handleEvent(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+		}}, "add")
+	}
+	pods.handleEvent(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}}, "add")
+
+	assert.Equal(t, 1, d.QueueSize())
+	assert.Equal(t, resolvedBefore+3, testutil.ToFloat64(eventsResolvedToOwnerTotal.WithLabelValues("pods", "deployments")))
+	assert.Equal(t, standaloneBefore+1, testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("pods", filterReasonStandalonePod)))
+
+	d.flush()
+	assert.Equal(t, []K8sResourceIdentifier{{Type: "deployments", Namespace: "default", Name: "web"}}, received)
+	assert.Equal(t, dedupedBefore+4, testutil.ToFloat64(eventsDeduplicatedTotal))
+	assert.InDelta(t, 0.8, testutil.ToFloat64(dedupeRatio), 0.001)
+}
+
+func TestHandleEvent_ResolveOwnersIncludeStandalonePods(t *testing.T) {
+	d := NewDebouncer(time.Hour, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return nil
+	})
+	w := NewResourceWatcher(nil, d, WatcherConfig{ResolveOwners: true, IncludeStandalonePods: true})
+	w.owners = newOwnerResolver(fake.NewClientBuilder().Build(), DefaultResourceTypes)
+
+	pods := &resourceEventHandler{watcher: w, resourceType: "pods"}
+	pods.handleEvent(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}}, "add")
+
+	assert.Equal(t, 1, d.QueueSize())
+}
+
+func TestHandleEvent_ScanOptOut(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "noisy", Namespace: "default", Annotations: map[string]string{constants.MondooScanAnnotation: "false"},
+	}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "noisy-5d4f8", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "Deployment", "noisy"),
+	}}
+
+	d := NewDebouncer(time.Hour, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return nil
+	})
+	w := NewResourceWatcher(nil, d, WatcherConfig{ResolveOwners: true})
+	w.owners = newOwnerResolver(fake.NewClientBuilder().WithObjects(deployment, replicaSet).Build(), DefaultResourceTypes)
+
+	filteredBefore := testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("pods", filterReasonAnnotation))
+
+	deployments := &resourceEventHandler{watcher: w, resourceType: "deployments"}
+	pods := &resourceEventHandler{watcher: w, resourceType: "pods"}
+	deployments.handleEvent(deployment, "update")
+	pods.handleEvent(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "noisy-5d4f8-a", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "noisy-5d4f8"),
+	}}, "add")
+
+	assert.Equal(t, 0, d.QueueSize())
+	assert.Equal(t, filteredBefore+1, testutil.ToFloat64(eventsFilteredTotal.WithLabelValues("pods", filterReasonAnnotation)))
+}
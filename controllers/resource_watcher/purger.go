@@ -0,0 +1,268 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	mondoo "go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
+)
+
+var purgerLogger = ctrl.Log.WithName("resource-watcher-purger")
+
+// k8sPlatformIdPrefix is the prefix the k8s provider of cnspec uses for the platform IDs of Kubernetes assets.
+const k8sPlatformIdPrefix = "//platformid.api.mondoo.app/runtime/k8s/uid/"
+
+// namespaceLookupTimeout is how long the purger waits for the UID of the namespace of a deleted resource.
+const namespaceLookupTimeout = 5 * time.Second
+
+// PlatformID returns the platform ID the k8s provider of cnspec assigns to the asset of a resource, built like
+// its shared.NewNamespacePlatformId and shared.NewWorkloadPlatformId do. The IDs of a namespace and of the
+// resources in it are based on the UID of the namespace. It returns "" for cluster-scoped resources other than
+// namespaces, which the provider doesn't discover as assets.
+func PlatformID(resource K8sResourceIdentifier, namespaceUID string) string {
+	kind := ToSingular(resource.Type)
+	if kind == "namespace" {
+		return k8sPlatformIdPrefix + namespaceUID + "/namespace/" + resource.Name
+	}
+	if resource.Namespace == "" {
+		return ""
+	}
+	// The provider makes the kind plural by appending an "s", whatever the kind
+	return k8sPlatformIdPrefix + namespaceUID + "/namespace/" + resource.Namespace + "/" + kind + "s/name/" + resource.Name
+}
+
+// PurgerConfig holds configuration for the Purger.
+type PurgerConfig struct {
+	// ConfigPath is the path to the mondoo.yml config file containing service account credentials.
+	ConfigPath string
+	// APIProxy is the HTTP proxy to use for API requests (optional).
+	APIProxy string
+	// ClientCertificateDir is the directory with the client certificate (tls.crt and tls.key) to present
+	// to the Mondoo API (optional).
+	ClientCertificateDir string
+	// ClusterUID is the unique identifier of the cluster. Only assets managed by the operator
+	// in this cluster are deleted.
+	ClusterUID string
+	// KubeClient reads the namespaces of deleted resources from the watched cluster, their UIDs are part of the
+	// platform IDs. It shouldn't be backed by a cache, the purger only needs a few namespaces.
+	KubeClient client.Reader
+	// ClusterName is the name of the external cluster that is watched, whose assets have their own ManagedBy
+	// label. Empty for the local cluster.
+	ClusterName string
+	// Interval is how long deleted resources are batched before they are purged.
+	Interval time.Duration
+	// ClientBuilder creates the Mondoo API client.
+	ClientBuilder func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error)
+}
+
+// Purger batches the platform IDs of deleted resources and deletes their assets in Mondoo Platform,
+// so they don't stay active until the next garbage collection.
+type Purger struct {
+	config PurgerConfig
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	timer   *time.Timer
+	ctx     context.Context
+	// namespaceUIDs caches the UIDs of the namespaces of the queued resources until they are purged
+	namespaceUIDs map[string]string
+}
+
+// NewPurger creates a new Purger with the given configuration.
+func NewPurger(config PurgerConfig) *Purger {
+	if config.ClientBuilder == nil {
+		config.ClientBuilder = mondooclient.NewClient
+	}
+	return &Purger{
+		config:        config,
+		pending:       make(map[string]struct{}),
+		namespaceUIDs: make(map[string]string),
+	}
+}
+
+// Add queues the asset of a deleted resource for purging. uid is the UID of the resource itself.
+func (p *Purger) Add(resource K8sResourceIdentifier, uid string) {
+	namespaceUID, err := p.namespaceUID(resource, uid)
+	if err != nil {
+		purgerLogger.Error(err, "Failed to get the namespace of the deleted resource, leaving its asset to garbage collection",
+			"resource", resource.String())
+		return
+	}
+	platformId := PlatformID(resource, namespaceUID)
+	if platformId == "" {
+		purgerLogger.V(1).Info("Deleted resource has no asset", "resource", resource.String())
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending[platformId] = struct{}{}
+	purgerLogger.V(1).Info("Added deleted resource to purge queue", "resource", resource.String(), "platformId", platformId, "queueSize", len(p.pending))
+
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.config.Interval, p.flush)
+	}
+}
+
+// namespaceUID returns the UID of the namespace the platform ID of a deleted resource is based on. For a
+// namespace, it is the UID of the resource itself. Other namespaces still exist while the resources in them
+// are deleted, so their UIDs are read from the cluster.
+func (p *Purger) namespaceUID(resource K8sResourceIdentifier, uid string) (string, error) {
+	if ToSingular(resource.Type) == "namespace" {
+		return uid, nil
+	}
+	if resource.Namespace == "" {
+		return "", nil
+	}
+
+	p.mu.Lock()
+	namespaceUID, ok := p.namespaceUIDs[resource.Namespace]
+	p.mu.Unlock()
+	if ok {
+		return namespaceUID, nil
+	}
+	if p.config.KubeClient == nil {
+		return "", fmt.Errorf("no Kubernetes client configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), namespaceLookupTimeout)
+	defer cancel()
+	namespace := &corev1.Namespace{}
+	if err := p.config.KubeClient.Get(ctx, client.ObjectKey{Name: resource.Namespace}, namespace); err != nil {
+		return "", fmt.Errorf("failed to get namespace %s: %w", resource.Namespace, err)
+	}
+
+	p.mu.Lock()
+	p.namespaceUIDs[resource.Namespace] = string(namespace.UID)
+	p.mu.Unlock()
+	return string(namespace.UID), nil
+}
+
+// Start runs the purger until the context is cancelled. Resources that are still queued are
+// purged before it returns.
+func (p *Purger) Start(ctx context.Context) error {
+	p.mu.Lock()
+	p.ctx = ctx
+	p.mu.Unlock()
+
+	purgerLogger.Info("Purger started", "interval", p.config.Interval)
+	<-ctx.Done()
+
+	p.mu.Lock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	// Use a fresh context for the final flush, the watcher's context is already cancelled
+	p.ctx = context.Background()
+	p.mu.Unlock()
+
+	p.flush()
+	purgerLogger.Info("Purger stopped")
+	return nil
+}
+
+// flush purges all queued platform IDs in a single request. Failed batches are not retried,
+// the scheduled garbage collection removes the assets eventually.
+func (p *Purger) flush() {
+	p.mu.Lock()
+	p.timer = nil
+	if len(p.pending) == 0 {
+		p.mu.Unlock()
+		return
+	}
+	platformIds := make([]string, 0, len(p.pending))
+	for id := range p.pending {
+		platformIds = append(platformIds, id)
+	}
+	p.pending = make(map[string]struct{})
+	// A namespace might be recreated with the same name, so its UID is looked up again for the next batch
+	clear(p.namespaceUIDs)
+	ctx := p.ctx
+	p.mu.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sort.Strings(platformIds)
+
+	deleted, err := p.purge(ctx, platformIds)
+	if err != nil {
+		purgeRequestsTotal.WithLabelValues(purgeResultFailure).Inc()
+		purgerLogger.Error(err, "Failed to purge assets of deleted resources", "platformIds", platformIds)
+		return
+	}
+	purgeRequestsTotal.WithLabelValues(purgeResultSuccess).Inc()
+	assetsPurgedTotal.Add(float64(deleted))
+	purgerLogger.Info("Purged assets of deleted resources", "requested", len(platformIds), "deleted", deleted)
+}
+
+// purge deletes the assets with the given platform IDs and returns how many assets were deleted.
+func (p *Purger) purge(ctx context.Context, platformIds []string) (int, error) {
+	// Read the credentials on every purge so rotated credentials are picked up
+	data, err := os.ReadFile(p.config.ConfigPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read config file: %w", err)
+	}
+	tokenSource, err := mondoo.TokenSourceForFile(p.config.ConfigPath, data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load service account: %w", err)
+	}
+	sa := tokenSource.ServiceAccount()
+
+	scopeMrn := sa.ScopeMrn
+	if scopeMrn == "" {
+		scopeMrn = sa.SpaceMrn
+	}
+	if scopeMrn == "" {
+		return 0, fmt.Errorf("no scope MRN determinable from service account")
+	}
+
+	opts := mondooclient.MondooClientOptions{
+		ApiEndpoint: sa.ApiEndpoint,
+		TokenSource: tokenSource,
+	}
+	if p.config.APIProxy != "" {
+		opts.HttpProxy = &p.config.APIProxy
+		opts.HttpsProxy = &p.config.APIProxy
+	}
+	if dir := p.config.ClientCertificateDir; dir != "" {
+		// Read on every purge so renewed certificates are picked up. The CA bundle is trusted through SSL_CERT_DIR.
+		tlsConfig, err := mondoo.APITLSConfigFromFiles("", filepath.Join(dir, corev1.TLSCertKey), filepath.Join(dir, corev1.TLSPrivateKeyKey))
+		if err != nil {
+			return 0, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	mc, err := p.config.ClientBuilder(opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create mondoo client: %w", err)
+	}
+
+	managedBy := mondoo.ManagedByLabel(p.config.ClusterUID)
+	if p.config.ClusterName != "" {
+		managedBy = mondoo.ManagedByExternalClusterLabel(p.config.ClusterUID, p.config.ClusterName)
+	}
+	resp, err := mc.DeleteAssets(ctx, &mondooclient.DeleteAssetsRequest{
+		ScopeMrn:    scopeMrn,
+		ManagedBy:   managedBy,
+		PlatformIds: platformIds,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete assets API call failed: %w", err)
+	}
+	return len(resp.AssetMrns), nil
+}
@@ -0,0 +1,250 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+)
+
+const testClusterUID = "abcd-1234"
+
+func TestPlatformID(t *testing.T) {
+	// The expected IDs are built like shared.NewNamespacePlatformId and shared.NewWorkloadPlatformId of the
+	// pinned k8s provider do for the resources it discovers in a namespace
+	tests := []struct {
+		name         string
+		resource     K8sResourceIdentifier
+		namespaceUID string
+		expected     string
+	}{
+		{
+			name:         "namespaced resource",
+			resource:     K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"},
+			namespaceUID: "default-uid",
+			expected:     "//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/web",
+		},
+		{
+			name:         "ingress",
+			resource:     K8sResourceIdentifier{Type: "ingresses", Namespace: "default", Name: "web"},
+			namespaceUID: "default-uid",
+			expected:     "//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/ingresss/name/web",
+		},
+		{
+			name:         "namespace",
+			resource:     K8sResourceIdentifier{Type: "namespaces", Name: "team-a"},
+			namespaceUID: "team-a-uid",
+			expected:     "//platformid.api.mondoo.app/runtime/k8s/uid/team-a-uid/namespace/team-a",
+		},
+		{
+			name:     "cluster-scoped resource",
+			resource: K8sResourceIdentifier{Type: "clusterroles", Name: "admin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlatformID(tt.resource, tt.namespaceUID))
+		})
+	}
+}
+
+func TestPurger_BatchesDeletes(t *testing.T) {
+	client := &fakeMondooClient{}
+	p := newTestPurger(t, client)
+
+	before := testutil.ToFloat64(assetsPurgedTotal)
+	requests := testutil.ToFloat64(purgeRequestsTotal.WithLabelValues(purgeResultSuccess))
+
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, "")
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "api"}, "")
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, "")
+
+	require.Eventually(t, func() bool { return len(client.Requests()) == 1 }, time.Second, 5*time.Millisecond)
+
+	req := client.Requests()[0]
+	assert.Equal(t, "//captain.api.mondoo.app/spaces/test-space", req.ScopeMrn)
+	assert.Equal(t, "mondoo-operator-"+testClusterUID, req.ManagedBy)
+	assert.Equal(t, []string{
+		"//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/api",
+		"//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/web",
+	}, req.PlatformIds)
+
+	assert.Equal(t, before+2, testutil.ToFloat64(assetsPurgedTotal))
+	assert.Equal(t, requests+1, testutil.ToFloat64(purgeRequestsTotal.WithLabelValues(purgeResultSuccess)))
+}
+
+func TestPurger_ExternalCluster(t *testing.T) {
+	client := &fakeMondooClient{}
+	p := newTestPurger(t, client)
+	p.config.ClusterName = "remote"
+
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, "")
+	require.Eventually(t, func() bool { return len(client.Requests()) == 1 }, time.Second, 5*time.Millisecond)
+
+	req := client.Requests()[0]
+	assert.Equal(t, "mondoo-operator-external-remote-"+testClusterUID, req.ManagedBy)
+	assert.Equal(t, []string{
+		"//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/web",
+	}, req.PlatformIds)
+}
+
+func TestPurger_Namespaces(t *testing.T) {
+	client := &fakeMondooClient{}
+	p := newTestPurger(t, client)
+	p.config.Interval = time.Hour
+
+	// The asset of a deleted namespace has the namespace's own UID
+	p.Add(K8sResourceIdentifier{Type: "namespaces", Name: "team-a"}, "team-a-uid")
+	// Resources in unknown namespaces are left to garbage collection
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "missing", Name: "web"}, "")
+	// Other cluster-scoped resources have no asset
+	p.Add(K8sResourceIdentifier{Type: "clusterroles", Name: "admin"}, "")
+
+	p.flush()
+	require.Len(t, client.Requests(), 1)
+	assert.Equal(t, []string{
+		"//platformid.api.mondoo.app/runtime/k8s/uid/team-a-uid/namespace/team-a",
+	}, client.Requests()[0].PlatformIds)
+}
+
+func TestPurger_Failure(t *testing.T) {
+	client := &fakeMondooClient{err: errors.New("unavailable")}
+	p := newTestPurger(t, client)
+
+	failures := testutil.ToFloat64(purgeRequestsTotal.WithLabelValues(purgeResultFailure))
+
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, "")
+	require.Eventually(t, func() bool { return len(client.Requests()) == 1 }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(purgeRequestsTotal.WithLabelValues(purgeResultFailure)) == failures+1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPurger_FlushesOnStop(t *testing.T) {
+	client := &fakeMondooClient{}
+	p := newTestPurger(t, client)
+	p.config.Interval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = p.Start(ctx)
+		close(done)
+	}()
+
+	p.Add(K8sResourceIdentifier{Type: "deployments", Namespace: "default", Name: "web"}, "")
+	cancel()
+	<-done
+
+	assert.Len(t, client.Requests(), 1)
+}
+
+func TestOnDelete(t *testing.T) {
+	client := &fakeMondooClient{}
+	p := newTestPurger(t, client)
+	p.config.Interval = time.Hour
+
+	w := NewResourceWatcher(nil, nil, WatcherConfig{NamespacesExclude: []string{"kube-system"}, Purger: p})
+	h := &resourceEventHandler{watcher: w, resourceType: "deployments"}
+
+	h.OnDelete(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	h.OnDelete(toolscache.DeletedFinalStateUnknown{
+		Key: "default/api",
+		Obj: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: types.UID("uid")}},
+	})
+	h.OnDelete(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}})
+	h.OnDelete(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ignored",
+		Namespace:   "default",
+		Annotations: map[string]string{"mondoo.com/watch": "false"},
+	}})
+
+	p.flush()
+	require.Len(t, client.Requests(), 1)
+	assert.Equal(t, []string{
+		"//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/api",
+		"//platformid.api.mondoo.app/runtime/k8s/uid/default-uid/namespace/default/deployments/name/web",
+	}, client.Requests()[0].PlatformIds)
+}
+
+func newTestPurger(t *testing.T, client *fakeMondooClient) *Purger {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "default-uid"}}
+	return NewPurger(PurgerConfig{
+		ConfigPath: writeTestServiceAccount(t),
+		ClusterUID: testClusterUID,
+		KubeClient: fake.NewClientBuilder().WithObjects(namespace).Build(),
+		Interval:   10 * time.Millisecond,
+		ClientBuilder: func(opts mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) {
+			return client, nil
+		},
+	})
+}
+
+// writeTestServiceAccount writes a mondoo.yml with a generated private key and returns its path.
+func writeTestServiceAccount(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	x509Encoded, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	data, err := yaml.Marshal(mondooclient.ServiceAccountCredentials{
+		Mrn:         "//agents.api.mondoo.app/spaces/test-space/serviceaccounts/test",
+		SpaceMrn:    "//captain.api.mondoo.app/spaces/test-space",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: x509Encoded})),
+		ApiEndpoint: "https://api.mondoo.com",
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "mondoo.yml")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// fakeMondooClient implements just enough of MondooClient to test purging
+type fakeMondooClient struct {
+	mondooclient.MondooClient
+	err error
+
+	mu       sync.Mutex
+	requests []mondooclient.DeleteAssetsRequest
+}
+
+func (f *fakeMondooClient) DeleteAssets(ctx context.Context, req *mondooclient.DeleteAssetsRequest) (*mondooclient.DeleteAssetsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, *req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &mondooclient.DeleteAssetsResponse{AssetMrns: req.PlatformIds}, nil
+}
+
+func (f *fakeMondooClient) Requests() []mondooclient.DeleteAssetsRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]mondooclient.DeleteAssetsRequest(nil), f.requests...)
+}
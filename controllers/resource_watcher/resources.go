@@ -5,15 +5,19 @@ package resource_watcher
 
 import (
 	"fmt"
+	"maps"
+	"path/filepath"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/controllers/k8s_scan"
 	"go.mondoo.com/mondoo-operator/pkg/annotations"
 	"go.mondoo.com/mondoo-operator/pkg/constants"
 	"go.mondoo.com/mondoo-operator/pkg/feature_flags"
@@ -31,6 +35,11 @@ func DeploymentName(prefix string) string {
 	return fmt.Sprintf("%s%s", prefix, DeploymentNameSuffix)
 }
 
+// MetricsServiceName returns the name of the Service exposing the resource watcher metrics.
+func MetricsServiceName(prefix string) string {
+	return fmt.Sprintf("%s%s-metrics", prefix, DeploymentNameSuffix)
+}
+
 // DeploymentLabels returns the labels for the resource watcher deployment.
 func DeploymentLabels(m v1alpha2.MondooAuditConfig) map[string]string {
 	return map[string]string{
@@ -39,10 +48,129 @@ func DeploymentLabels(m v1alpha2.MondooAuditConfig) map[string]string {
 	}
 }
 
+// metricsPodLabels returns the labels of the pods of all resource watcher deployments of the MondooAuditConfig:
+// the local one, the ones of the routed spaces and the ones of the external clusters. The metrics Service
+// selects them.
+func metricsPodLabels(m v1alpha2.MondooAuditConfig) map[string]string {
+	return map[string]string{
+		"component": "mondoo-resource-watcher",
+		"mondoo_cr": m.Name,
+	}
+}
+
+// ExternalClusterDeploymentName returns the name of the resource watcher deployment for an external cluster.
+func ExternalClusterDeploymentName(prefix, clusterName string) string {
+	return fmt.Sprintf("%s%s-%s", prefix, DeploymentNameSuffix, clusterName)
+}
+
+// ExternalClusterDeploymentLabels returns the labels for the resource watcher deployment of an external cluster.
+// They don't overlap with DeploymentLabels.
+func ExternalClusterDeploymentLabels(m v1alpha2.MondooAuditConfig, clusterName string) map[string]string {
+	ls := externalClusterWatcherLabels(m)
+	ls["cluster_name"] = clusterName
+	return ls
+}
+
+// externalClusterWatcherLabels returns the labels shared by the resource watcher deployments of all external clusters.
+func externalClusterWatcherLabels(m v1alpha2.MondooAuditConfig) map[string]string {
+	return map[string]string{
+		"app":       "mondoo-external-resource-watcher",
+		"mondoo_cr": m.Name,
+	}
+}
+
+// SpaceDeploymentName returns the name of the resource watcher deployment for a space of spec.spaceRouting.
+func SpaceDeploymentName(prefix, spaceID string) string {
+	return fmt.Sprintf("%s%s-space-%s", prefix, DeploymentNameSuffix, spaceID)
+}
+
+// SpaceDeploymentLabels returns the labels for the resource watcher deployment of a space of spec.spaceRouting.
+// They don't overlap with DeploymentLabels.
+func SpaceDeploymentLabels(m v1alpha2.MondooAuditConfig, spaceID string) map[string]string {
+	ls := spaceWatcherLabels(m)
+	ls[k8s.SpaceIDLabel] = spaceID
+	return ls
+}
+
+// spaceWatcherLabels returns the labels shared by the resource watcher deployments of all routed spaces.
+func spaceWatcherLabels(m v1alpha2.MondooAuditConfig) map[string]string {
+	return map[string]string{
+		"app":       "mondoo-space-resource-watcher",
+		"mondoo_cr": m.Name,
+	}
+}
+
+// watcherLabels returns label sets that together select the local, routed space and all external cluster
+// resource watchers.
+func watcherLabels(m v1alpha2.MondooAuditConfig) []map[string]string {
+	return []map[string]string{DeploymentLabels(m), spaceWatcherLabels(m), externalClusterWatcherLabels(m)}
+}
+
 // Deployment creates a Deployment spec for the resource watcher.
 func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) *appsv1.Deployment {
-	ls := DeploymentLabels(*m)
+	spec := m.Spec.KubernetesResources.ResourceWatcher
+	// Without the scheduled scan, the watcher has to scan the cluster once it starts
+	if k8s_scan.ScheduledScanReplaced(*m) {
+		spec.InitialScan = true
+	}
+	cmd := watcherCommand(integrationMRN, clusterUID, spec, m.Spec.Filtering, m, cfg)
+	deployment := newDeployment(DeploymentName(m.Name), DeploymentLabels(*m), image, cmd, m, k8s.KubernetesResourcesScan, cfg)
+	deployment.Spec.Template.Spec.ServiceAccountName = m.Spec.Scanner.ServiceAccountName
+	return deployment
+}
+
+// SpaceDeployment creates a Deployment spec for the resource watcher of the namespaces spec.spaceRouting routes
+// to a space. It only watches these namespaces and reports to the space with the space's derived config Secret.
+func SpaceDeployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAuditConfig, route k8s.SpaceRoute, cfg v1alpha2.MondooOperatorConfig) *appsv1.Deployment {
+	routed := k8s.SpaceAuditConfig(*m, route)
+	spec := routed.Spec.KubernetesResources.ResourceWatcher
+	if k8s_scan.ScheduledScanReplaced(routed) {
+		spec.InitialScan = true
+	}
+	cmd := watcherCommand(integrationMRN, clusterUID, spec, routed.Spec.Filtering, &routed, cfg)
+	deployment := newDeployment(SpaceDeploymentName(m.Name, route.SpaceID), SpaceDeploymentLabels(*m, route.SpaceID), image, cmd, &routed, k8s.KubernetesResourcesScan, cfg)
+	deployment.Spec.Template.Spec.ServiceAccountName = m.Spec.Scanner.ServiceAccountName
+	return deployment
+}
+
+// ExternalClusterDeployment creates a Deployment spec for the resource watcher of an external cluster. The watcher
+// authenticates to the external cluster the same way the scheduled scans for the cluster do.
+func ExternalClusterDeployment(image, integrationMRN, clusterUID string, cluster v1alpha2.ExternalCluster, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) *appsv1.Deployment {
+	kubeconfigPath := filepath.Join(k8s_scan.ExternalClusterKubeconfigDir, "kubeconfig")
+
+	// The watcher restarts when its credentials change, since the informers don't reload them
+	credentialsFiles := []string{kubeconfigPath}
+	if cluster.SPIFFEAuth != nil {
+		credentialsFiles = append(credentialsFiles, "/etc/spiffe-certs/svid.pem")
+	}
+
+	cmd := watcherCommand(integrationMRN, clusterUID, *cluster.ResourceWatcher, k8s_scan.ExternalClusterFiltering(cluster, *m), m, cfg)
+	cmd = append(cmd, "--cluster-name", cluster.Name, "--credentials-files", strings.Join(credentialsFiles, ","))
+
+	ls := ExternalClusterDeploymentLabels(*m, cluster.Name)
+	deployment := newDeployment(ExternalClusterDeploymentName(m.Name, cluster.Name), ls, image, cmd, m, k8s.ExternalClusterScan(cluster.Name), cfg)
+
+	auth := k8s_scan.ExternalClusterPodAuth(cluster, m)
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.ServiceAccountName = auth.ServiceAccountName
+	podSpec.AutomountServiceAccountToken = auth.AutomountServiceAccountToken
+	podSpec.InitContainers = auth.InitContainers
+	podSpec.Volumes = append(podSpec.Volumes, auth.Volumes...)
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, auth.VolumeMounts...)
+	podSpec.Containers[0].Env = append(podSpec.Containers[0].Env, corev1.EnvVar{Name: "KUBECONFIG", Value: kubeconfigPath})
+
+	// Short-lived credentials have to be refreshed for as long as the watcher runs
+	if refresh := k8s_scan.ExternalClusterKubeconfigRefreshContainer(cluster); refresh != nil {
+		podSpec.Containers = append(podSpec.Containers, *refresh)
+	}
 
+	maps.Copy(deployment.Spec.Template.Labels, auth.PodLabels)
+
+	return deployment
+}
+
+// watcherCommand builds the command line of the resource watcher container.
+func watcherCommand(integrationMRN, clusterUID string, spec v1alpha2.ResourceWatcherSpec, filtering v1alpha2.Filtering, m *v1alpha2.MondooAuditConfig, cfg v1alpha2.MondooOperatorConfig) []string {
 	// Build command arguments
 	cmd := []string{
 		"/mondoo-operator", "resource-watcher",
@@ -61,34 +189,61 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 
 	// Add debounce interval if configured
 	debounceInterval := defaultDebounceInterval
-	if m.Spec.KubernetesResources.ResourceWatcher.DebounceInterval.Duration > 0 {
-		debounceInterval = m.Spec.KubernetesResources.ResourceWatcher.DebounceInterval.Duration
+	if spec.DebounceInterval.Duration > 0 {
+		debounceInterval = spec.DebounceInterval.Duration
 	}
 	cmd = append(cmd, "--debounce-interval", debounceInterval.String())
 
 	// Add minimum scan interval if configured
 	minimumScanInterval := defaultMinimumScanInterval
-	if m.Spec.KubernetesResources.ResourceWatcher.MinimumScanInterval.Duration > 0 {
-		minimumScanInterval = m.Spec.KubernetesResources.ResourceWatcher.MinimumScanInterval.Duration
+	if spec.MinimumScanInterval.Duration > 0 {
+		minimumScanInterval = spec.MinimumScanInterval.Duration
 	}
 	cmd = append(cmd, "--minimum-scan-interval", minimumScanInterval.String())
 
 	// Add watch all resources flag if enabled
-	if m.Spec.KubernetesResources.ResourceWatcher.WatchAllResources {
+	if spec.WatchAllResources {
 		cmd = append(cmd, "--watch-all-resources")
 	}
 
 	// Add resource types if configured (overrides watch-all-resources)
-	if len(m.Spec.KubernetesResources.ResourceWatcher.ResourceTypes) > 0 {
-		cmd = append(cmd, "--resource-types", strings.Join(m.Spec.KubernetesResources.ResourceWatcher.ResourceTypes, ","))
+	if len(spec.ResourceTypes) > 0 {
+		cmd = append(cmd, "--resource-types", strings.Join(spec.ResourceTypes, ","))
+	}
+
+	// Add owner resolution if enabled
+	if spec.ResolveOwners {
+		cmd = append(cmd, "--resolve-owners")
+		if spec.IncludeStandalonePods {
+			cmd = append(cmd, "--include-standalone-pods")
+		}
+	}
+
+	// Add initial scan and resyncs of all watched resources
+	if spec.InitialScan {
+		cmd = append(cmd, "--initial-scan")
+	}
+	if spec.ResyncInterval.Duration > 0 {
+		cmd = append(cmd, "--resync-interval", spec.ResyncInterval.Duration.String())
+	}
+
+	if spec.PurgeDeletedAssets {
+		cmd = append(cmd, "--purge-deleted-assets")
 	}
 
 	// Add namespace filtering
-	if len(m.Spec.Filtering.Namespaces.Include) > 0 {
-		cmd = append(cmd, "--namespaces", strings.Join(m.Spec.Filtering.Namespaces.Include, ","))
+	if len(filtering.Namespaces.Include) > 0 {
+		cmd = append(cmd, "--namespaces", strings.Join(filtering.Namespaces.Include, ","))
 	}
-	if len(m.Spec.Filtering.Namespaces.Exclude) > 0 {
-		cmd = append(cmd, "--namespaces-exclude", strings.Join(m.Spec.Filtering.Namespaces.Exclude, ","))
+	if len(filtering.Namespaces.Exclude) > 0 {
+		cmd = append(cmd, "--namespaces-exclude", strings.Join(filtering.Namespaces.Exclude, ","))
+	}
+
+	// Add label selector if configured. It is validated by the DeploymentHandler before the Deployment is built.
+	if ls := spec.LabelSelector; ls != nil {
+		if selector, err := metav1.LabelSelectorAsSelector(ls); err == nil && !selector.Empty() {
+			cmd = append(cmd, "--label-selector", selector.String())
+		}
 	}
 
 	// Add API proxy if configured (respect SkipProxyForCnspec since resource watcher uses cnspec)
@@ -98,9 +253,25 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 		}
 	}
 
+	// Present the mounted client certificate when purging assets
+	if ref := cfg.Spec.ClientCertificateSecretRef; ref != nil && ref.Name != "" {
+		cmd = append(cmd, "--client-certificate-dir", k8s.ClientCertificateMountPath)
+	}
+
 	// Add annotations (sorted for deterministic ordering)
 	cmd = append(cmd, annotations.AnnotationArgs(m.Spec.Annotations)...)
+	cmd = append(cmd, annotations.FromLabelsArgs(m.Spec.AnnotationsFromLabels)...)
+
+	// Serve Prometheus metrics on a fixed port so the metrics Service can target it
+	cmd = append(cmd, "--metrics-bind-address", fmt.Sprintf(":%d", MetricsPort))
+	cmd = append(cmd, "--health-probe-bind-address", fmt.Sprintf(":%d", HealthProbePort))
 
+	return cmd
+}
+
+// newDeployment creates the Deployment spec shared by the resource watchers for the local and external clusters.
+// The mondoo config is the one of the given scan.
+func newDeployment(name string, ls map[string]string, image string, cmd []string, m *v1alpha2.MondooAuditConfig, scan k8s.ScanType, cfg v1alpha2.MondooOperatorConfig) *appsv1.Deployment {
 	envVars := feature_flags.AllFeatureFlagsAsEnv()
 	envVars = append(envVars, corev1.EnvVar{Name: "MONDOO_AUTO_UPDATE", Value: "false"})
 
@@ -112,9 +283,12 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 	// Add custom scanner env vars
 	envVars = append(envVars, m.Spec.Scanner.Env...)
 
+	podLabels := maps.Clone(ls)
+	maps.Copy(podLabels, metricsPodLabels(*m))
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      DeploymentName(m.Name),
+			Name:      name,
 			Namespace: m.Namespace,
 			Labels:    ls,
 		},
@@ -126,7 +300,7 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: ls,
+					Labels: podLabels,
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
@@ -135,7 +309,34 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 							Image:           image,
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							Command:         cmd,
-							Resources:       k8s.ResourcesRequirementsWithDefaults(m.Spec.Scanner.Resources, k8s.DefaultK8sResourceScanningResources),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          MetricsPortName,
+									ContainerPort: MetricsPort,
+									Protocol:      corev1.ProtocolTCP,
+								},
+							},
+							LivenessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/healthz",
+										Port: intstr.FromInt32(HealthProbePort),
+									},
+								},
+								InitialDelaySeconds: 15,
+								PeriodSeconds:       20,
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/readyz",
+										Port: intstr.FromInt32(HealthProbePort),
+									},
+								},
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       10,
+							},
+							Resources: k8s.ResourcesRequirementsWithDefaults(m.Spec.Scanner.Resources, k8s.DefaultK8sResourceScanningResources),
 							SecurityContext: &corev1.SecurityContext{
 								AllowPrivilegeEscalation: ptr.To(false),
 								ReadOnlyRootFilesystem:   ptr.To(true),
@@ -162,7 +363,6 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
 						},
 					},
-					ServiceAccountName: m.Spec.Scanner.ServiceAccountName,
 					Volumes: []corev1.Volume{
 						{
 							Name: "temp",
@@ -178,7 +378,7 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 									Sources: []corev1.VolumeProjection{
 										{
 											Secret: &corev1.SecretProjection{
-												LocalObjectReference: k8s.ConfigSecretRef(*m),
+												LocalObjectReference: k8s.ConfigSecretRef(*m, scan),
 												Items: []corev1.KeyToPath{{
 													Key:  constants.MondooCredsSecretServiceAccountKey,
 													Path: "mondoo.yml",
@@ -202,5 +402,35 @@ func Deployment(image, integrationMRN, clusterUID string, m *v1alpha2.MondooAudi
 			cfg.Spec.ImagePullSecrets...)
 	}
 
+	// Trust the CA bundle and present the client certificate configured for the Mondoo API
+	k8s.AddMondooAPITLSToSpec(&deployment.Spec.Template.Spec, cfg)
+
+	// The watcher runs the operator image, which also reads the credentials from Vault
+	k8s.AddMondooCredsSourceToSpec(&deployment.Spec.Template.Spec, *m, scan, image)
+
 	return deployment
 }
+
+// MetricsService creates a Service exposing the metrics endpoint of all resource watchers of the
+// MondooAuditConfig.
+func MetricsService(m *v1alpha2.MondooAuditConfig) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MetricsServiceName(m.Name),
+			Namespace: m.Namespace,
+			Labels:    DeploymentLabels(*m),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: metricsPodLabels(*m),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       MetricsPortName,
+					Port:       MetricsPort,
+					TargetPort: intstr.FromString(MetricsPortName),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
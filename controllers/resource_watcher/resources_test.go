@@ -10,11 +10,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/utils/ptr"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 )
 
 func TestDeploymentName(t *testing.T) {
@@ -276,6 +279,32 @@ func TestDeployment_WithAnnotations(t *testing.T) {
 	assert.True(t, annotationArgs["team=platform"], "expected --annotation team=platform")
 }
 
+func TestDeployment_WithAnnotationsFromLabels(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable: true,
+				},
+			},
+			AnnotationsFromLabels: map[string]string{
+				"team":        "metadata.labels['app.kubernetes.io/team']",
+				"cost-center": "namespace.labels['cost-center']",
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+
+	cmd := strings.Join(deployment.Spec.Template.Spec.Containers[0].Command, " ")
+	assert.Contains(t, cmd, "--annotation-from-label cost-center=namespace.labels['cost-center'] --annotation-from-label team=metadata.labels['app.kubernetes.io/team']")
+}
+
 func TestDeployment_HighPriorityByDefault(t *testing.T) {
 	config := &v1alpha2.MondooAuditConfig{
 		ObjectMeta: metav1.ObjectMeta{
@@ -501,6 +530,44 @@ func TestDeployment_WithImagePullSecrets(t *testing.T) {
 	assert.Equal(t, "my-registry-secret", secrets[0].Name)
 }
 
+func TestDeployment_WithMondooAPITLS(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable: true,
+				},
+			},
+		},
+	}
+
+	operatorConfig := v1alpha2.MondooOperatorConfig{
+		Spec: v1alpha2.MondooOperatorConfigSpec{
+			CABundleSecretRef:          &corev1.LocalObjectReference{Name: "mondoo-ca"},
+			ClientCertificateSecretRef: &corev1.LocalObjectReference{Name: "mondoo-client-cert"},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, operatorConfig)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, strings.Join(container.Command, " "), "--client-certificate-dir "+k8s.ClientCertificateMountPath)
+	assert.Contains(t, envToMap(container.Env)["SSL_CERT_DIR"], k8s.CABundleMountPath)
+
+	volumes := map[string]string{}
+	for _, v := range deployment.Spec.Template.Spec.Volumes {
+		if v.Secret != nil {
+			volumes[v.Name] = v.Secret.SecretName
+		}
+	}
+	assert.Equal(t, "mondoo-ca", volumes[k8s.CABundleVolumeName])
+	assert.Equal(t, "mondoo-client-cert", volumes[k8s.ClientCertificateVolumeName])
+}
+
 // envToMap converts a slice of EnvVar to a map for easy lookup.
 func envToMap(envVars []corev1.EnvVar) map[string]string {
 	m := make(map[string]string, len(envVars))
@@ -509,3 +576,402 @@ func envToMap(envVars []corev1.EnvVar) map[string]string {
 	}
 	return m
 }
+
+func TestDeployment_MetricsPort(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable: true,
+				},
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, strings.Join(container.Command, " "), "--metrics-bind-address :8080")
+	require.Len(t, container.Ports, 1)
+	assert.Equal(t, "metrics", container.Ports[0].Name)
+	assert.Equal(t, int32(8080), container.Ports[0].ContainerPort)
+}
+
+func TestMetricsService(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+	}
+
+	svc := MetricsService(config)
+
+	assert.Equal(t, "my-config-resource-watcher-metrics", svc.Name)
+	assert.Equal(t, "mondoo-operator", svc.Namespace)
+	assert.Equal(t, DeploymentLabels(*config), svc.Labels)
+
+	// The Service selects the pods of the local, the routed space and the external cluster watchers
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	for _, deployment := range []*appsv1.Deployment{
+		Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{}),
+		SpaceDeployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, k8s.SpaceRoute{SpaceID: "prod"}, v1alpha2.MondooOperatorConfig{}),
+		ExternalClusterDeployment("ghcr.io/mondoohq/cnspec:latest", "", "", v1alpha2.ExternalCluster{
+			Name:                "prod",
+			KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "prod-kubeconfig"},
+			ResourceWatcher:     &v1alpha2.ResourceWatcherSpec{Enable: true},
+		}, config, v1alpha2.MondooOperatorConfig{}),
+	} {
+		assert.True(t, selector.Matches(labels.Set(deployment.Spec.Template.Labels)), deployment.Name)
+	}
+	require.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, "metrics", svc.Spec.Ports[0].Name)
+	assert.Equal(t, int32(8080), svc.Spec.Ports[0].Port)
+	assert.Equal(t, "metrics", svc.Spec.Ports[0].TargetPort.StrVal)
+}
+
+func TestDeployment_HealthProbes(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable: true,
+				},
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, strings.Join(container.Command, " "), "--health-probe-bind-address :8081")
+
+	require.NotNil(t, container.LivenessProbe)
+	require.NotNil(t, container.LivenessProbe.HTTPGet)
+	assert.Equal(t, "/healthz", container.LivenessProbe.HTTPGet.Path)
+	assert.Equal(t, int32(8081), container.LivenessProbe.HTTPGet.Port.IntVal)
+
+	require.NotNil(t, container.ReadinessProbe)
+	require.NotNil(t, container.ReadinessProbe.HTTPGet)
+	assert.Equal(t, "/readyz", container.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, int32(8081), container.ReadinessProbe.HTTPGet.Port.IntVal)
+}
+
+func TestDeployment_LabelSelector(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable: true,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"team": "platform"},
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"ci", "preview"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, strings.Join(container.Command, " "), "--label-selector env notin (ci,preview),team=platform")
+}
+
+func TestDeployment_NoLabelSelector(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--label-selector")
+}
+
+func TestDeployment_ResolveOwners(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable:                true,
+					ResolveOwners:         true,
+					IncludeStandalonePods: true,
+				},
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	cmd := deployment.Spec.Template.Spec.Containers[0].Command
+	assert.Contains(t, cmd, "--resolve-owners")
+	assert.Contains(t, cmd, "--include-standalone-pods")
+
+	// Standalone pods are only relevant with owner resolution
+	config.Spec.KubernetesResources.ResourceWatcher.ResolveOwners = false
+	deployment = Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	cmd = deployment.Spec.Template.Spec.Containers[0].Command
+	assert.NotContains(t, cmd, "--resolve-owners")
+	assert.NotContains(t, cmd, "--include-standalone-pods")
+}
+
+func TestExternalClusterDeployment(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			Scanner: v1alpha2.Scanner{ServiceAccountName: "mondoo-operator-k8s-resources-scanning"},
+			Filtering: v1alpha2.Filtering{
+				Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}},
+			},
+		},
+	}
+	cluster := v1alpha2.ExternalCluster{
+		Name:                "prod",
+		KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "prod-kubeconfig"},
+		ResourceWatcher: &v1alpha2.ResourceWatcherSpec{
+			Enable:           true,
+			DebounceInterval: metav1.Duration{Duration: 30 * time.Second},
+		},
+	}
+
+	deployment := ExternalClusterDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "cluster-uid", cluster, config, v1alpha2.MondooOperatorConfig{})
+
+	assert.Equal(t, "my-config-resource-watcher-prod", deployment.Name)
+	assert.Equal(t, ExternalClusterDeploymentLabels(*config, "prod"), deployment.Labels)
+	assert.Equal(t, ExternalClusterDeploymentLabels(*config, "prod"), deployment.Spec.Selector.MatchLabels)
+	assert.NotEqual(t, DeploymentLabels(*config)["app"], deployment.Labels["app"], "the metrics Service must not select external cluster watchers")
+
+	podSpec := deployment.Spec.Template.Spec
+	assert.Empty(t, podSpec.ServiceAccountName, "the scanner service account must not be used for external clusters")
+	assert.False(t, *podSpec.AutomountServiceAccountToken)
+	require.Len(t, podSpec.Containers, 1)
+
+	container := podSpec.Containers[0]
+	cmd := strings.Join(container.Command, " ")
+	assert.Contains(t, cmd, "--cluster-uid cluster-uid")
+	assert.Contains(t, cmd, "--cluster-name prod")
+	assert.Contains(t, cmd, "--credentials-files /etc/opt/mondoo/kubeconfig/kubeconfig")
+	assert.Contains(t, cmd, "--debounce-interval 30s")
+	assert.Contains(t, cmd, "--namespaces-exclude kube-system")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "KUBECONFIG", Value: "/etc/opt/mondoo/kubeconfig/kubeconfig"})
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: "kubeconfig", ReadOnly: true, MountPath: "/etc/opt/mondoo/kubeconfig"})
+
+	var kubeconfigVolume *corev1.Volume
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == "kubeconfig" {
+			kubeconfigVolume = &podSpec.Volumes[i]
+		}
+	}
+	require.NotNil(t, kubeconfigVolume)
+	assert.Equal(t, "prod-kubeconfig", kubeconfigVolume.Secret.SecretName)
+}
+
+func TestSpaceDeployment(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "mondoo-creds"},
+			Scanner:              v1alpha2.Scanner{ServiceAccountName: "mondoo-operator-k8s-resources-scanning"},
+			Filtering: v1alpha2.Filtering{
+				Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}},
+			},
+			SpaceRouting: []v1alpha2.SpaceRoutingRule{{SpaceID: "team-a", Namespaces: []string{"team-a-*"}}},
+		},
+	}
+	route := k8s.SpaceRoute{SpaceID: "team-a", Namespaces: []string{"team-a-dev", "team-a-prod"}}
+
+	deployment := SpaceDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "cluster-uid", config, route, v1alpha2.MondooOperatorConfig{})
+
+	assert.Equal(t, "my-config-resource-watcher-space-team-a", deployment.Name)
+	assert.Equal(t, SpaceDeploymentLabels(*config, "team-a"), deployment.Labels)
+	assert.Equal(t, SpaceDeploymentLabels(*config, "team-a"), deployment.Spec.Selector.MatchLabels)
+	assert.NotEqual(t, DeploymentLabels(*config)["app"], deployment.Labels["app"], "the metrics Service must not select space watchers")
+
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, "mondoo-operator-k8s-resources-scanning", podSpec.ServiceAccountName)
+	cmd := strings.Join(podSpec.Containers[0].Command, " ")
+	assert.Contains(t, cmd, "--namespaces team-a-dev,team-a-prod")
+	assert.NotContains(t, cmd, "--namespaces-exclude")
+	assert.Equal(t, k8s.SpaceConfigSecretName("my-config", "team-a"), podSpec.Volumes[1].Projected.Sources[0].Secret.Name)
+}
+
+func TestExternalClusterDeployment_ClusterFiltering(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-config", Namespace: "mondoo-operator"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			Filtering: v1alpha2.Filtering{
+				Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}},
+			},
+		},
+	}
+	cluster := v1alpha2.ExternalCluster{
+		Name:                "prod",
+		KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "prod-kubeconfig"},
+		Filtering: &v1alpha2.Filtering{
+			Namespaces: v1alpha2.FilteringSpec{Include: []string{"app"}},
+		},
+		ResourceWatcher: &v1alpha2.ResourceWatcherSpec{Enable: true},
+	}
+
+	deployment := ExternalClusterDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "", cluster, config, v1alpha2.MondooOperatorConfig{})
+
+	cmd := deployment.Spec.Template.Spec.Containers[0].Command
+	assert.Contains(t, strings.Join(cmd, " "), "--namespaces app")
+	assert.NotContains(t, cmd, "--namespaces-exclude")
+}
+
+func TestExternalClusterDeployment_WorkloadIdentity(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-config", Namespace: "mondoo-operator"},
+	}
+	cluster := v1alpha2.ExternalCluster{
+		Name: "gke",
+		WorkloadIdentity: &v1alpha2.WorkloadIdentityConfig{
+			Provider: v1alpha2.CloudProviderGKE,
+			GKE: &v1alpha2.GKEWorkloadIdentity{
+				ProjectID:            "project",
+				ClusterName:          "cluster",
+				ClusterLocation:      "us-central1",
+				GoogleServiceAccount: "scanner@project.iam.gserviceaccount.com",
+			},
+		},
+		ResourceWatcher: &v1alpha2.ResourceWatcherSpec{Enable: true},
+	}
+
+	deployment := ExternalClusterDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "", cluster, config, v1alpha2.MondooOperatorConfig{})
+
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, "my-config-wif-gke", podSpec.ServiceAccountName)
+	require.Len(t, podSpec.InitContainers, 1)
+	assert.Equal(t, "generate-kubeconfig", podSpec.InitContainers[0].Name)
+
+	// The access token expires, so a sidecar keeps regenerating the kubeconfig
+	require.Len(t, podSpec.Containers, 2)
+	refresh := podSpec.Containers[1]
+	assert.Equal(t, "refresh-kubeconfig", refresh.Name)
+	assert.Equal(t, podSpec.InitContainers[0].Image, refresh.Image)
+	assert.Contains(t, refresh.Command[2], "while true")
+	assert.Contains(t, refresh.Command[2], "gcloud auth print-access-token")
+}
+
+func TestExternalClusterDeployment_SPIFFE(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-config", Namespace: "mondoo-operator"},
+	}
+	cluster := v1alpha2.ExternalCluster{
+		Name: "spiffe",
+		SPIFFEAuth: &v1alpha2.SPIFFEAuthConfig{
+			Server:               "https://remote.example.com:6443",
+			TrustBundleSecretRef: corev1.LocalObjectReference{Name: "trust-bundle"},
+		},
+		ResourceWatcher: &v1alpha2.ResourceWatcherSpec{Enable: true},
+	}
+
+	deployment := ExternalClusterDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "", cluster, config, v1alpha2.MondooOperatorConfig{})
+
+	podSpec := deployment.Spec.Template.Spec
+	assert.Contains(t, strings.Join(podSpec.Containers[0].Command, " "),
+		"--credentials-files /etc/opt/mondoo/kubeconfig/kubeconfig,/etc/spiffe-certs/svid.pem")
+	require.Len(t, podSpec.Containers, 2)
+	assert.Equal(t, "refresh-kubeconfig", podSpec.Containers[1].Name)
+}
+
+func TestExternalClusterDeployment_AKSPodLabel(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-config", Namespace: "mondoo-operator"},
+	}
+	cluster := v1alpha2.ExternalCluster{
+		Name: "aks",
+		WorkloadIdentity: &v1alpha2.WorkloadIdentityConfig{
+			Provider: v1alpha2.CloudProviderAKS,
+			AKS:      &v1alpha2.AKSWorkloadIdentity{ClusterName: "cluster"},
+		},
+		ResourceWatcher: &v1alpha2.ResourceWatcherSpec{Enable: true},
+	}
+
+	deployment := ExternalClusterDeployment("ghcr.io/mondoohq/mondoo-operator:latest", "", "", cluster, config, v1alpha2.MondooOperatorConfig{})
+
+	assert.Equal(t, "true", deployment.Spec.Template.Labels["azure.workload.identity/use"])
+	assert.NotContains(t, deployment.Spec.Selector.MatchLabels, "azure.workload.identity/use")
+}
+
+func TestDeployment_InitialScanAndResync(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable: true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{
+					Enable:         true,
+					ResyncInterval: metav1.Duration{Duration: 6 * time.Hour},
+				},
+			},
+		},
+	}
+
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	cmd := deployment.Spec.Template.Spec.Containers[0].Command
+	assert.NotContains(t, cmd, "--initial-scan")
+	assert.Contains(t, strings.Join(cmd, " "), "--resync-interval 6h0m0s")
+
+	// Replacing the scheduled scan implies an initial scan
+	config.Spec.KubernetesResources.ResourceWatcher.ReplaceScheduledScan = true
+	deployment = Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--initial-scan")
+}
+
+func TestDeployment_PurgeDeletedAssets(t *testing.T) {
+	config := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-config",
+			Namespace: "mondoo-operator",
+		},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			KubernetesResources: v1alpha2.KubernetesResources{
+				Enable:          true,
+				ResourceWatcher: v1alpha2.ResourceWatcherSpec{Enable: true},
+			},
+		},
+	}
+
+	// Deleted resources are left to garbage collection unless enabled
+	deployment := Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	assert.NotContains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--purge-deleted-assets")
+
+	config.Spec.KubernetesResources.ResourceWatcher.PurgeDeletedAssets = true
+	deployment = Deployment("ghcr.io/mondoohq/cnspec:latest", "", "", config, v1alpha2.MondooOperatorConfig{})
+	assert.Contains(t, deployment.Spec.Template.Spec.Containers[0].Command, "--purge-deleted-assets")
+}
@@ -14,6 +14,7 @@ import (
 
 	"go.mondoo.com/mql/v13/providers-sdk/v1/inventory"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	"go.mondoo.com/mondoo-operator/pkg/annotations"
@@ -32,6 +33,10 @@ type ScannerConfig struct {
 	Timeout time.Duration
 	// Annotations are key-value pairs to attach to all scanned assets.
 	Annotations map[string]string
+	// LabelRules derive annotations from the labels of the scanned resources and their namespaces.
+	LabelRules []annotations.LabelRule
+	// Reader reads the labels of the scanned resources and their namespaces. Required for LabelRules.
+	Reader client.Reader
 	// Namespaces to include in scanning. Empty means all namespaces.
 	Namespaces []string
 	// NamespacesExclude are namespaces to exclude from scanning.
@@ -40,6 +45,8 @@ type ScannerConfig struct {
 	ClusterUID string
 	// IntegrationMRN is the integration MRN for asset labeling.
 	IntegrationMRN string
+	// ClusterName is the name of the external cluster that is watched. Empty for the local cluster.
+	ClusterName string
 }
 
 // Scanner executes cnspec scans on K8s resources.
@@ -61,7 +68,7 @@ func (s *Scanner) ScanResources(ctx context.Context, resources []K8sResourceIden
 	}
 
 	// Generate inventory file
-	inv, err := s.generateInventory(resources)
+	inv, err := s.generateInventory(s.groupByAnnotations(ctx, resources))
 	if err != nil {
 		return fmt.Errorf("failed to generate inventory: %w", err)
 	}
@@ -102,8 +109,11 @@ func (s *Scanner) ScanResources(ctx context.Context, resources []K8sResourceIden
 	if s.config.APIProxy != "" {
 		cnspecArgs = append(cnspecArgs, "--api-proxy", s.config.APIProxy)
 	}
-	// Add annotations as command-line arguments (sorted for deterministic ordering)
-	cnspecArgs = append(cnspecArgs, annotations.AnnotationArgs(s.config.Annotations)...)
+	// Add annotations as command-line arguments (sorted for deterministic ordering). With label rules they
+	// are set in the inventory instead, so the annotations derived from labels override them.
+	if len(s.config.LabelRules) == 0 {
+		cnspecArgs = append(cnspecArgs, annotations.AnnotationArgs(s.config.Annotations)...)
+	}
 
 	// Create context with timeout
 	scanCtx := ctx
@@ -121,9 +131,14 @@ func (s *Scanner) ScanResources(ctx context.Context, resources []K8sResourceIden
 
 	scannerLogger.V(1).Info("Executing cnspec scan", "args", cnspecArgs)
 
-	if err := cmd.Run(); err != nil {
+	start := time.Now()
+	err = cmd.Run()
+	scanDurationSeconds.Observe(time.Since(start).Seconds())
+	if err != nil {
+		scanFailuresTotal.Inc()
 		// Check if it was a timeout
 		if scanCtx.Err() == context.DeadlineExceeded {
+			scanTimeoutsTotal.Inc()
 			return fmt.Errorf("cnspec scan timed out after %v: %w", s.config.Timeout, err)
 		}
 		return fmt.Errorf("cnspec scan failed: %w", err)
@@ -133,61 +148,136 @@ func (s *Scanner) ScanResources(ctx context.Context, resources []K8sResourceIden
 	return nil
 }
 
-// generateInventory creates an inventory YAML for scanning specific resources via K8s API.
-func (s *Scanner) generateInventory(resources []K8sResourceIdentifier) ([]byte, error) {
-	// Build resource filter string for k8s-resources option
-	// Format: type:namespace:name,type:namespace:name,...
-	resourceFilters := make([]string, 0, len(resources))
-	for _, r := range resources {
-		resourceFilters = append(resourceFilters, r.String())
+// resourceGroup holds resources that get the same annotations derived from labels.
+type resourceGroup struct {
+	annotations map[string]string
+	resources   []K8sResourceIdentifier
+}
+
+// groupByAnnotations groups the resources by the annotations the label rules derive from their labels.
+// Resources that are no longer in the cache get no derived annotations.
+func (s *Scanner) groupByAnnotations(ctx context.Context, resources []K8sResourceIdentifier) []resourceGroup {
+	if len(s.config.LabelRules) == 0 || s.config.Reader == nil {
+		return []resourceGroup{{resources: resources}}
 	}
 
-	// Extract unique resource types for discovery targets
-	typeSet := make(map[string]struct{})
+	readObjectLabels := annotations.HasSource(s.config.LabelRules, annotations.ObjectLabel)
+	readNamespaceLabels := annotations.HasSource(s.config.LabelRules, annotations.NamespaceLabel)
+	namespaceLabels := map[string]map[string]string{}
+
+	var groups []resourceGroup
+	groupIndex := map[string]int{}
 	for _, r := range resources {
-		typeSet[r.Type] = struct{}{} // Type is already plural (e.g., "deployments")
-	}
-	targets := make([]string, 0, len(typeSet))
-	for t := range typeSet {
-		targets = append(targets, t)
-	}
-	sort.Strings(targets)
+		var objectLabels map[string]string
+		if readObjectLabels || r.Type == "namespaces" {
+			objectLabels = s.readLabels(ctx, r.Type, r.Namespace, r.Name)
+		}
+
+		ns := r.Namespace
+		if r.Type == "namespaces" {
+			ns = r.Name
+			namespaceLabels[ns] = objectLabels
+		}
+		if _, ok := namespaceLabels[ns]; !ok && readNamespaceLabels && ns != "" {
+			namespaceLabels[ns] = s.readLabels(ctx, "namespaces", "", ns)
+		}
 
-	opts := map[string]string{
-		"k8s-resources": strings.Join(resourceFilters, ","),
+		derived := annotations.FromLabels(s.config.LabelRules, objectLabels, namespaceLabels[ns])
+		key := strings.Join(annotations.AnnotationArgs(derived), "\x00")
+		if i, ok := groupIndex[key]; ok {
+			groups[i].resources = append(groups[i].resources, r)
+			continue
+		}
+		groupIndex[key] = len(groups)
+		groups = append(groups, resourceGroup{annotations: derived, resources: []K8sResourceIdentifier{r}})
 	}
-	if len(s.config.Namespaces) > 0 {
-		opts["namespaces"] = strings.Join(s.config.Namespaces, ",")
+	return groups
+}
+
+// readLabels returns the labels of a resource from the cache, or nil if it can't be read.
+func (s *Scanner) readLabels(ctx context.Context, resourceType, namespace, name string) map[string]string {
+	obj, err := metadataObjectForResourceType(resourceType)
+	if err != nil {
+		scannerLogger.V(1).Info("Unknown resource type, skipping label annotations", "type", resourceType)
+		return nil
 	}
-	if len(s.config.NamespacesExclude) > 0 {
-		opts["namespaces-exclude"] = strings.Join(s.config.NamespacesExclude, ",")
+	if err := s.config.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+		scannerLogger.V(1).Info("Failed to read labels, skipping label annotations",
+			"type", resourceType, "namespace", namespace, "name", name, "error", err.Error())
+		return nil
 	}
+	return obj.GetLabels()
+}
 
+// generateInventory creates an inventory YAML for scanning specific resources via K8s API. Each group of
+// resources is scanned by its own asset, since cnspec applies the annotations of an inventory asset to all
+// the assets it discovers.
+func (s *Scanner) generateInventory(groups []resourceGroup) ([]byte, error) {
 	managedBy := mondoo.ManagedByLabel(s.config.ClusterUID)
+	if s.config.ClusterName != "" {
+		managedBy = mondoo.ManagedByExternalClusterLabel(s.config.ClusterUID, s.config.ClusterName)
+	}
 
 	inv := &inventory.Inventory{
 		Metadata: &inventory.ObjectMeta{
 			Name: "mondoo-resource-watcher-inventory",
 		},
-		Spec: &inventory.InventorySpec{
-			Assets: []*inventory.Asset{
+		Spec: &inventory.InventorySpec{},
+	}
+
+	for _, g := range groups {
+		// Build resource filter string for k8s-resources option
+		// Format: type:namespace:name,type:namespace:name,...
+		resourceFilters := make([]string, 0, len(g.resources))
+		for _, r := range g.resources {
+			resourceFilters = append(resourceFilters, r.String())
+		}
+
+		// Extract unique resource types for discovery targets
+		typeSet := make(map[string]struct{})
+		for _, r := range g.resources {
+			typeSet[r.Type] = struct{}{} // Type is already plural (e.g., "deployments")
+		}
+		targets := make([]string, 0, len(typeSet))
+		for t := range typeSet {
+			targets = append(targets, t)
+		}
+		sort.Strings(targets)
+
+		opts := map[string]string{
+			"k8s-resources": strings.Join(resourceFilters, ","),
+		}
+		if len(s.config.Namespaces) > 0 {
+			opts["namespaces"] = strings.Join(s.config.Namespaces, ",")
+		}
+		if len(s.config.NamespacesExclude) > 0 {
+			opts["namespaces-exclude"] = strings.Join(s.config.NamespacesExclude, ",")
+		}
+
+		asset := &inventory.Asset{
+			Connections: []*inventory.Config{
 				{
-					Connections: []*inventory.Config{
-						{
-							Type:    "k8s",
-							Options: opts,
-							Discover: &inventory.Discovery{
-								Targets: targets,
-							},
-						},
-					},
-					Labels: map[string]string{
-						"k8s.mondoo.com/kind": "cluster",
+					Type:    "k8s",
+					Options: opts,
+					Discover: &inventory.Discovery{
+						Targets: targets,
 					},
-					ManagedBy: managedBy,
 				},
 			},
-		},
+			Labels: map[string]string{
+				"k8s.mondoo.com/kind": "cluster",
+			},
+			ManagedBy: managedBy,
+		}
+		if len(s.config.LabelRules) > 0 {
+			if len(s.config.Annotations) > 0 {
+				asset.AddAnnotations(s.config.Annotations)
+			}
+			if len(g.annotations) > 0 {
+				asset.AddAnnotations(g.annotations)
+			}
+		}
+		inv.Spec.Assets = append(inv.Spec.Assets, asset)
 	}
 
 	if s.config.IntegrationMRN != "" {
@@ -196,6 +286,13 @@ func (s *Scanner) generateInventory(resources []K8sResourceIdentifier) ([]byte,
 		}
 	}
 
+	if s.config.ClusterName != "" {
+		for i := range inv.Spec.Assets {
+			inv.Spec.Assets[i].Labels["mondoo.com/cluster-name"] = s.config.ClusterName
+			inv.Spec.Assets[i].Labels["mondoo.com/external-scan"] = "true"
+		}
+	}
+
 	return yaml.Marshal(inv)
 }
 
@@ -0,0 +1,110 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mondoo.com/mql/v13/providers-sdk/v1/inventory"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"go.mondoo.com/mondoo-operator/pkg/annotations"
+)
+
+func TestGenerateInventory_WithoutLabelRules(t *testing.T) {
+	s := NewScanner(ScannerConfig{
+		Annotations: map[string]string{"env": "prod"},
+		ClusterUID:  "abcdefg",
+	})
+	resources := []K8sResourceIdentifier{
+		{Type: "deployments", Namespace: "default", Name: "web"},
+		{Type: "pods", Namespace: "default", Name: "web-1"},
+	}
+
+	groups := s.groupByAnnotations(context.Background(), resources)
+	require.Len(t, groups, 1)
+
+	inv := generateTestInventory(t, s, groups)
+	require.Len(t, inv.Spec.Assets, 1)
+	assert.Equal(t, "deployment:default:web,pod:default:web-1", inv.Spec.Assets[0].Connections[0].Options["k8s-resources"])
+	assert.Equal(t, []string{"deployments", "pods"}, inv.Spec.Assets[0].Connections[0].Discover.Targets)
+	// Without label rules the annotations are passed as cnspec arguments
+	assert.Empty(t, inv.Spec.Assets[0].Annotations)
+}
+
+func TestGenerateInventory_ManagedBy(t *testing.T) {
+	resources := []K8sResourceIdentifier{{Type: "deployments", Namespace: "default", Name: "web"}}
+
+	s := NewScanner(ScannerConfig{ClusterUID: "abcdefg"})
+	inv := generateTestInventory(t, s, s.groupByAnnotations(context.Background(), resources))
+	assert.Equal(t, "mondoo-operator-abcdefg", inv.Spec.Assets[0].ManagedBy)
+
+	// Assets of an external cluster are managed under the name of the cluster
+	s = NewScanner(ScannerConfig{ClusterUID: "abcdefg", ClusterName: "remote"})
+	inv = generateTestInventory(t, s, s.groupByAnnotations(context.Background(), resources))
+	assert.Equal(t, "mondoo-operator-external-remote-abcdefg", inv.Spec.Assets[0].ManagedBy)
+}
+
+func TestGenerateInventory_WithLabelRules(t *testing.T) {
+	rules, err := annotations.ParseLabelRules(map[string]string{
+		"team":        "metadata.labels['team']",
+		"cost-center": "namespace.labels['cost-center']",
+	})
+	require.NoError(t, err)
+
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"cost-center": "cc-42"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments", Labels: map[string]string{"team": "payments"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "payments", Labels: map[string]string{"team": "payments"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"team": "frontend"}}},
+	).Build()
+
+	s := NewScanner(ScannerConfig{
+		Annotations: map[string]string{"env": "prod", "team": "unknown"},
+		LabelRules:  rules,
+		Reader:      reader,
+		ClusterUID:  "abcdefg",
+	})
+	resources := []K8sResourceIdentifier{
+		{Type: "deployments", Namespace: "payments", Name: "api"},
+		{Type: "deployments", Namespace: "default", Name: "web"},
+		{Type: "deployments", Namespace: "payments", Name: "worker"},
+		{Type: "deployments", Namespace: "default", Name: "deleted"},
+		{Type: "namespaces", Name: "payments"},
+	}
+
+	inv := generateTestInventory(t, s, s.groupByAnnotations(context.Background(), resources))
+	require.Len(t, inv.Spec.Assets, 4)
+
+	byResources := map[string]map[string]string{}
+	for _, a := range inv.Spec.Assets {
+		byResources[a.Connections[0].Options["k8s-resources"]] = a.Annotations
+	}
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments", "cost-center": "cc-42"},
+		byResources["deployment:payments:api,deployment:payments:worker"])
+	assert.Equal(t, map[string]string{"env": "prod", "team": "frontend"}, byResources["deployment:default:web"])
+	// Static annotations still apply to resources without labels
+	assert.Equal(t, map[string]string{"env": "prod", "team": "unknown"}, byResources["deployment:default:deleted"])
+	// A namespace gets the annotations of its own labels
+	assert.Equal(t, map[string]string{"env": "prod", "team": "unknown", "cost-center": "cc-42"}, byResources["namespace:payments"])
+}
+
+func generateTestInventory(t *testing.T, s *Scanner, groups []resourceGroup) inventory.Inventory {
+	t.Helper()
+
+	invBytes, err := s.generateInventory(groups)
+	require.NoError(t, err)
+
+	var inv inventory.Inventory
+	require.NoError(t, yaml.Unmarshal(invBytes, &inv))
+	return inv
+}
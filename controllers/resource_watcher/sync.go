@@ -0,0 +1,126 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// syncPollInterval is how often a sync checks whether the informers have synced and whether the
+// debouncer has flushed the previous batch.
+var syncPollInterval = time.Second
+
+// runSyncs queues all watched resources for scanning once the informers have synced if InitialScan
+// is set, and then every ResyncInterval until the context is cancelled.
+func (w *ResourceWatcher) runSyncs(ctx context.Context) {
+	if err := wait.PollUntilContextCancel(ctx, syncPollInterval, true, func(context.Context) (bool, error) {
+		return w.HasSynced(), nil
+	}); err != nil {
+		return
+	}
+
+	if w.config.InitialScan {
+		w.sync(ctx, syncTriggerInitial)
+	}
+	if w.config.ResyncInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.config.ResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sync(ctx, syncTriggerPeriodic)
+		}
+	}
+}
+
+// sync queues all watched resources for scanning. Workloads are queued first, and the resources are handed
+// to the debouncer in batches of SyncBatchSize. The next batch is only queued once the debouncer has flushed
+// the previous one, so changes keep being scanned promptly while a sync is in progress.
+func (w *ResourceWatcher) sync(ctx context.Context, trigger string) {
+	resources := w.listWatchedResources(ctx)
+	syncsTotal.WithLabelValues(trigger).Inc()
+	syncQueuedResources.Set(float64(len(resources)))
+	watcherLogger.Info("Queueing all watched resources for scanning", "trigger", trigger, "resourceCount", len(resources))
+
+	batchSize := w.config.SyncBatchSize
+	if batchSize <= 0 {
+		batchSize = len(resources)
+	}
+	for batch := range slices.Chunk(resources, max(batchSize, 1)) {
+		if err := wait.PollUntilContextCancel(ctx, syncPollInterval, true, func(context.Context) (bool, error) {
+			return w.debouncer.QueueSize() == 0, nil
+		}); err != nil {
+			return
+		}
+		for _, resource := range batch {
+			w.debouncer.Add(resource.key(), resource)
+		}
+	}
+}
+
+// listWatchedResources returns the resources to scan for all objects in the cache that pass the watcher's
+// filters, ordered by priority.
+func (w *ResourceWatcher) listWatchedResources(ctx context.Context) []K8sResourceIdentifier {
+	seen := make(map[string]struct{})
+	var resources []K8sResourceIdentifier
+	for _, resourceType := range syncOrder(w.config.ResourceTypes) {
+		obj, err := metadataObjectForResourceType(resourceType)
+		if err != nil {
+			watcherLogger.Error(err, "Failed to get object for resource type", "resourceType", resourceType)
+			continue
+		}
+		list := &metav1.PartialObjectMetadataList{}
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		gvk.Kind += "List"
+		list.SetGroupVersionKind(gvk)
+		if err := w.cache.List(ctx, list); err != nil {
+			watcherLogger.Error(err, "Failed to list resources for sync", "resourceType", resourceType)
+			continue
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			if w.filterReason(item) != "" {
+				continue
+			}
+			resource, reason := w.scanTarget(item, resourceType)
+			if reason != "" {
+				continue
+			}
+			if _, ok := seen[resource.key()]; ok {
+				continue
+			}
+			seen[resource.key()] = struct{}{}
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// syncOrder returns the resource types with the HighPriorityResourceTypes first, so workloads are scanned
+// before ephemeral and supporting resources.
+func syncOrder(resourceTypes []string) []string {
+	ordered := make([]string, 0, len(resourceTypes))
+	for _, t := range HighPriorityResourceTypes {
+		if slices.Contains(resourceTypes, t) {
+			ordered = append(ordered, t)
+		}
+	}
+	for _, t := range resourceTypes {
+		if !slices.Contains(ordered, t) {
+			ordered = append(ordered, t)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,110 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+// listCache serves List calls from a client, which is all a sync needs from the cache
+type listCache struct {
+	cache.Cache
+	reader client.Reader
+}
+
+func (c *listCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+func TestListWatchedResources(t *testing.T) {
+	objs := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "ignored", Namespace: "default", Annotations: map[string]string{constants.MondooWatchAnnotation: "false"},
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d4f8", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "Deployment", "web"),
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d4f8-abcde", Namespace: "default", OwnerReferences: controllerRef("apps/v1", "ReplicaSet", "web-5d4f8"),
+		}},
+	}
+	reader := fake.NewClientBuilder().WithObjects(objs...).Build()
+
+	w := NewResourceWatcher(&listCache{reader: reader}, nil, WatcherConfig{
+		ResourceTypes:     []string{"pods", "replicasets", "deployments"},
+		NamespacesExclude: []string{"kube-system"},
+		ResolveOwners:     true,
+	})
+	w.owners = newOwnerResolver(reader, w.config.ResourceTypes)
+
+	assert.Equal(t, []K8sResourceIdentifier{
+		{Type: "deployments", Namespace: "default", Name: "web"},
+	}, w.listWatchedResources(context.Background()))
+
+	w.config.ResolveOwners = false
+	assert.Equal(t, []K8sResourceIdentifier{
+		{Type: "deployments", Namespace: "default", Name: "web"},
+		{Type: "replicasets", Namespace: "default", Name: "web-5d4f8"},
+		{Type: "pods", Namespace: "default", Name: "standalone"},
+		{Type: "pods", Namespace: "default", Name: "web-5d4f8-abcde"},
+	}, w.listWatchedResources(context.Background()))
+}
+
+func TestSync_Batches(t *testing.T) {
+	defer func(interval time.Duration) { syncPollInterval = interval }(syncPollInterval)
+	syncPollInterval = time.Millisecond
+
+	reader := fake.NewClientBuilder().WithObjects(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}},
+	).Build()
+
+	var mu sync.Mutex
+	var scans [][]K8sResourceIdentifier
+	d := NewDebouncer(time.Millisecond, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		mu.Lock()
+		defer mu.Unlock()
+		scans = append(scans, resources)
+		return nil
+	})
+	w := NewResourceWatcher(&listCache{reader: reader}, d, WatcherConfig{SyncBatchSize: 2})
+
+	syncs := testutil.ToFloat64(syncsTotal.WithLabelValues(syncTriggerInitial))
+	w.sync(context.Background(), syncTriggerInitial)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(scans) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, scans[0], 2)
+	assert.Len(t, scans[1], 1)
+	assert.Equal(t, syncs+1, testutil.ToFloat64(syncsTotal.WithLabelValues(syncTriggerInitial)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(syncQueuedResources))
+}
+
+func TestSyncOrder(t *testing.T) {
+	assert.Equal(t,
+		[]string{"deployments", "replicasets", "pods", "services"},
+		syncOrder([]string{"pods", "replicasets", "services", "deployments"}))
+}
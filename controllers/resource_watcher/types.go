@@ -26,6 +26,14 @@ func (r K8sResourceIdentifier) String() string {
 	return fmt.Sprintf("%s:%s:%s", singularType, r.Namespace, r.Name)
 }
 
+// key returns a key that is unique per resource, used to deduplicate queued resources.
+func (r K8sResourceIdentifier) key() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Type, r.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Namespace, r.Type, r.Name)
+}
+
 // resourceTypePluralization maps plural resource type names to their singular form.
 // This is needed because Kubernetes uses plural forms (e.g., "ingresses") but cnspec
 // expects singular forms (e.g., "ingress") for resource filtering.
@@ -8,14 +8,21 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	toolscache "k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 )
 
 var watcherLogger = ctrl.Log.WithName("resource-watcher")
@@ -57,6 +64,21 @@ type WatcherConfig struct {
 	// When false (default), only HighPriorityResourceTypes are watched.
 	// When true, all DefaultResourceTypes are watched (including ephemeral resources like Pods).
 	WatchAllResources bool
+	// LabelSelector restricts watching to resources whose labels match. Nil means all resources.
+	LabelSelector labels.Selector
+	// ResolveOwners enqueues the top-level controller of a changed resource instead of the resource itself.
+	ResolveOwners bool
+	// IncludeStandalonePods keeps Pods without a controller when ResolveOwners is enabled.
+	IncludeStandalonePods bool
+	// Purger deletes the assets of deleted resources in Mondoo Platform. Nil disables purging.
+	Purger *Purger
+	// InitialScan queues all watched resources for scanning once the informers have synced.
+	InitialScan bool
+	// ResyncInterval is how often all watched resources are queued for scanning. Zero disables resyncs.
+	ResyncInterval time.Duration
+	// SyncBatchSize is the maximum number of resources an initial scan or resync hands to the debouncer
+	// at once. Zero queues all resources at once.
+	SyncBatchSize int
 }
 
 // ResourceWatcher watches Kubernetes resources and triggers scans when they change.
@@ -64,6 +86,12 @@ type ResourceWatcher struct {
 	cache     cache.Cache
 	debouncer *Debouncer
 	config    WatcherConfig
+	owners    *ownerResolver
+	ctx       context.Context
+
+	mu            sync.Mutex
+	started       bool
+	registrations []toolscache.ResourceEventHandlerRegistration
 }
 
 // NewResourceWatcher creates a new ResourceWatcher.
@@ -81,6 +109,7 @@ func NewResourceWatcher(c cache.Cache, debouncer *Debouncer, config WatcherConfi
 		cache:     c,
 		debouncer: debouncer,
 		config:    config,
+		owners:    newOwnerResolver(c, config.ResourceTypes),
 	}
 }
 
@@ -89,11 +118,15 @@ func (w *ResourceWatcher) Start(ctx context.Context) error {
 	watcherLogger.Info("Starting resource watcher",
 		"namespaces", w.config.Namespaces,
 		"namespacesExclude", w.config.NamespacesExclude,
-		"resourceTypes", w.config.ResourceTypes)
+		"resourceTypes", w.config.ResourceTypes,
+		"resolveOwners", w.config.ResolveOwners,
+		"initialScan", w.config.InitialScan,
+		"resyncInterval", w.config.ResyncInterval)
+	w.ctx = ctx
 
 	// Set up informers for each resource type
 	for _, resourceType := range w.config.ResourceTypes {
-		obj, err := w.getObjectForResourceType(resourceType)
+		obj, err := metadataObjectForResourceType(resourceType)
 		if err != nil {
 			watcherLogger.Error(err, "Failed to get object for resource type", "resourceType", resourceType)
 			continue
@@ -110,23 +143,52 @@ func (w *ResourceWatcher) Start(ctx context.Context) error {
 			resourceType: resourceType,
 		}
 
-		_, err = informer.AddEventHandler(handler)
+		registration, err := informer.AddEventHandler(handler)
 		if err != nil {
 			watcherLogger.Error(err, "Failed to add event handler", "resourceType", resourceType)
 			continue
 		}
 
+		w.mu.Lock()
+		w.registrations = append(w.registrations, registration)
+		w.mu.Unlock()
+
 		watcherLogger.Info("Started watching resource type", "resourceType", resourceType)
 	}
 
+	w.mu.Lock()
+	w.started = true
+	w.mu.Unlock()
+
+	if w.config.InitialScan || w.config.ResyncInterval > 0 {
+		go w.runSyncs(ctx)
+	}
+
 	// Wait for context cancellation
 	<-ctx.Done()
 	watcherLogger.Info("Resource watcher stopped")
 	return nil
 }
 
-// getObjectForResourceType returns the client.Object for a resource type string.
-func (w *ResourceWatcher) getObjectForResourceType(resourceType string) (client.Object, error) {
+// HasSynced returns true once the watcher has registered its event handlers and all of them
+// have received the initial list of their informer.
+func (w *ResourceWatcher) HasSynced() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return false
+	}
+	for _, r := range w.registrations {
+		if !r.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// objectForResourceType returns the client.Object for a resource type string.
+func objectForResourceType(resourceType string) (client.Object, error) {
 	switch strings.ToLower(resourceType) {
 	case "pods", "pod":
 		return &corev1.Pod{}, nil
@@ -170,6 +232,17 @@ func (w *ResourceWatcher) shouldWatchNamespace(namespace string) bool {
 	return !slices.Contains(w.config.NamespacesExclude, namespace)
 }
 
+// shouldWatchObject returns the reason an object is filtered out, or an empty string if it should be watched.
+func (w *ResourceWatcher) shouldWatchObject(obj client.Object) string {
+	if strings.EqualFold(obj.GetAnnotations()[constants.MondooWatchAnnotation], "false") || k8s.ScanOptedOut(obj.GetAnnotations()) {
+		return filterReasonAnnotation
+	}
+	if w.config.LabelSelector != nil && !w.config.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+		return filterReasonLabelSelector
+	}
+	return ""
+}
+
 // resourceEventHandler handles resource events from informers.
 type resourceEventHandler struct {
 	watcher      *ResourceWatcher
@@ -189,32 +262,70 @@ func (h *resourceEventHandler) OnUpdate(oldObj, newObj any) {
 }
 
 func (h *resourceEventHandler) OnDelete(obj any) {
-	// We don't scan on delete - the resource is gone
-	watcherLogger.V(1).Info("Resource deleted", "resourceType", h.resourceType)
-}
-
-func (h *resourceEventHandler) handleEvent(obj any, eventType string) {
+	// The informer may have missed the delete and only knows the last state of the object
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 	clientObj, ok := obj.(client.Object)
 	if !ok {
 		watcherLogger.Error(nil, "Failed to convert object to client.Object")
 		return
 	}
 
-	namespace := clientObj.GetNamespace()
+	eventsReceivedTotal.WithLabelValues("delete", h.resourceType, clientObj.GetNamespace()).Inc()
+	if h.filtered(clientObj) {
+		return
+	}
+
+	// We don't scan on delete - the resource is gone. Its asset is purged instead.
+	watcherLogger.V(1).Info("Resource deleted",
+		"resourceType", h.resourceType,
+		"namespace", clientObj.GetNamespace(),
+		"name", clientObj.GetName())
+	if h.watcher.config.Purger == nil {
+		return
+	}
+	h.watcher.config.Purger.Add(K8sResourceIdentifier{
+		Type:      h.resourceType,
+		Namespace: clientObj.GetNamespace(),
+		Name:      clientObj.GetName(),
+	}, string(clientObj.GetUID()))
+}
+
+// filtered returns true if the watcher should ignore events for the object.
+func (h *resourceEventHandler) filtered(clientObj client.Object) bool {
+	reason := h.watcher.filterReason(clientObj)
+	if reason == "" {
+		return false
+	}
+	watcherLogger.V(2).Info("Skipping resource filtered by "+reason,
+		"resourceType", h.resourceType,
+		"namespace", clientObj.GetNamespace(),
+		"name", clientObj.GetName())
+	eventsFilteredTotal.WithLabelValues(h.resourceType, reason).Inc()
+	return true
+}
 
+// filterReason returns the reason the watcher ignores the object, or an empty string if it is watched.
+func (w *ResourceWatcher) filterReason(clientObj client.Object) string {
 	// Check namespace filtering (skip for cluster-scoped resources)
-	if namespace != "" && !h.watcher.shouldWatchNamespace(namespace) {
-		watcherLogger.V(2).Info("Skipping resource in excluded namespace",
-			"resourceType", h.resourceType,
-			"namespace", namespace,
-			"name", clientObj.GetName())
+	if namespace := clientObj.GetNamespace(); namespace != "" && !w.shouldWatchNamespace(namespace) {
+		return filterReasonNamespace
+	}
+	return w.shouldWatchObject(clientObj)
+}
+
+func (h *resourceEventHandler) handleEvent(obj any, eventType string) {
+	clientObj, ok := obj.(client.Object)
+	if !ok {
+		watcherLogger.Error(nil, "Failed to convert object to client.Object")
 		return
 	}
 
-	// Create unique key for the resource
-	key := fmt.Sprintf("%s/%s/%s", namespace, h.resourceType, clientObj.GetName())
-	if namespace == "" {
-		key = fmt.Sprintf("%s/%s", h.resourceType, clientObj.GetName())
+	namespace := clientObj.GetNamespace()
+	eventsReceivedTotal.WithLabelValues(eventType, h.resourceType, namespace).Inc()
+	if h.filtered(clientObj) {
+		return
 	}
 
 	watcherLogger.V(1).Info("Resource changed",
@@ -223,13 +334,50 @@ func (h *resourceEventHandler) handleEvent(obj any, eventType string) {
 		"namespace", namespace,
 		"name", clientObj.GetName())
 
-	// Create resource identifier for scanning
+	resource, reason := h.watcher.scanTarget(clientObj, h.resourceType)
+	if reason != "" {
+		watcherLogger.V(2).Info("Skipping resource filtered by "+reason,
+			"resourceType", h.resourceType,
+			"namespace", namespace,
+			"name", clientObj.GetName())
+		eventsFilteredTotal.WithLabelValues(h.resourceType, reason).Inc()
+		return
+	}
+	if changed := (K8sResourceIdentifier{Type: h.resourceType, Namespace: namespace, Name: clientObj.GetName()}); resource != changed {
+		watcherLogger.V(1).Info("Resolved resource to its owner",
+			"resource", changed.String(),
+			"owner", resource.String())
+		eventsResolvedToOwnerTotal.WithLabelValues(h.resourceType, resource.Type).Inc()
+	}
+
+	// Add to debouncer
+	h.watcher.debouncer.Add(resource.key(), resource)
+}
+
+// scanTarget returns the resource to scan for an object. With ResolveOwners this is the top-level owner
+// of the object. For objects that should not be scanned, such as Pods without a controller or objects whose
+// owner opted out of scanning, it returns the reason they are skipped.
+func (w *ResourceWatcher) scanTarget(clientObj client.Object, resourceType string) (K8sResourceIdentifier, string) {
 	resource := K8sResourceIdentifier{
-		Type:      h.resourceType, // plural form (e.g., "deployments")
-		Namespace: namespace,
+		Type:      resourceType, // plural form (e.g., "deployments")
+		Namespace: clientObj.GetNamespace(),
 		Name:      clientObj.GetName(),
 	}
+	if !w.config.ResolveOwners {
+		return resource, ""
+	}
 
-	// Add to debouncer
-	h.watcher.debouncer.Add(key, resource)
+	// Scan the top-level owner instead of every ReplicaSet and Pod it controls
+	ctx := w.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	root, owned, optedOut := w.owners.resolveRoot(ctx, clientObj, resourceType)
+	if optedOut {
+		return K8sResourceIdentifier{}, filterReasonAnnotation
+	}
+	if !owned && ToSingular(resourceType) == "pod" && !w.config.IncludeStandalonePods {
+		return K8sResourceIdentifier{}, filterReasonStandalonePod
+	}
+	return root, ""
 }
@@ -0,0 +1,81 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package resource_watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestShouldWatchObject(t *testing.T) {
+	selector, err := labels.Parse("tier!=ci")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		selector    labels.Selector
+		labels      map[string]string
+		annotations map[string]string
+		expected    string
+	}{
+		{name: "no filters", expected: ""},
+		{name: "opt-out annotation", annotations: map[string]string{"mondoo.com/watch": "false"}, expected: filterReasonAnnotation},
+		{name: "opt-out annotation is case insensitive", annotations: map[string]string{"mondoo.com/watch": "False"}, expected: filterReasonAnnotation},
+		{name: "annotation set to true", annotations: map[string]string{"mondoo.com/watch": "true"}, expected: ""},
+		{name: "selector matches", selector: selector, labels: map[string]string{"tier": "web"}, expected: ""},
+		{name: "selector does not match", selector: selector, labels: map[string]string{"tier": "ci"}, expected: filterReasonLabelSelector},
+		{
+			name:        "annotation wins over matching selector",
+			selector:    selector,
+			labels:      map[string]string{"tier": "web"},
+			annotations: map[string]string{"mondoo.com/watch": "false"},
+			expected:    filterReasonAnnotation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewResourceWatcher(nil, nil, WatcherConfig{LabelSelector: tt.selector})
+			obj := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name:        "app",
+				Namespace:   "default",
+				Labels:      tt.labels,
+				Annotations: tt.annotations,
+			}}
+			assert.Equal(t, tt.expected, w.shouldWatchObject(obj))
+		})
+	}
+}
+
+func TestHandleEvent_LabelSelectorAndAnnotation(t *testing.T) {
+	selector, err := labels.Parse("app")
+	require.NoError(t, err)
+
+	d := NewDebouncer(time.Hour, 0, func(ctx context.Context, resources []K8sResourceIdentifier) error {
+		return nil
+	})
+	w := NewResourceWatcher(nil, d, WatcherConfig{LabelSelector: selector})
+	h := &resourceEventHandler{watcher: w, resourceType: "deployments"}
+
+	h.handleEvent(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "unlabeled", Namespace: "default",
+	}}, "update")
+	h.handleEvent(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "opted-out", Namespace: "default",
+		Labels:      map[string]string{"app": "preview"},
+		Annotations: map[string]string{"mondoo.com/watch": "false"},
+	}}, "update")
+	h.handleEvent(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "web", Namespace: "default",
+		Labels: map[string]string{"app": "web"},
+	}}, "update")
+
+	assert.Equal(t, 1, d.QueueSize())
+}
@@ -12,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,6 +21,7 @@ import (
 	"k8s.io/utils/ptr"
 
 	mondoov1alpha2 "go.mondoo.com/mondoo-operator/api/v1alpha2"
+	resourcewatcher "go.mondoo.com/mondoo-operator/controllers/resource_watcher"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 	"go.mondoo.com/mondoo-operator/pkg/utils/mondoo"
 )
@@ -33,6 +35,10 @@ func (s *ServiceMonitor) serviceMonitorName() string {
 	return "mondoo-operator-metrics-monitor"
 }
 
+func (s *ServiceMonitor) resourceWatcherServiceMonitorName() string {
+	return "mondoo-resource-watcher-metrics-monitor"
+}
+
 func (s *ServiceMonitor) declareServiceMonitor(ctx context.Context, clt client.Client, scheme *runtime.Scheme) (ctrl.Result, error) {
 	log := ctrllog.FromContext(ctx)
 
@@ -129,6 +135,120 @@ func (s *ServiceMonitor) metricsEndpoint() monitoringv1.Endpoint {
 	}
 }
 
+// resourceWatcherServiceMonitorForMondoo declares a ServiceMonitor which scrapes the metrics Services of all
+// resource watchers running in the provided namespaces.
+func (s *ServiceMonitor) resourceWatcherServiceMonitorForMondoo(m *mondoov1alpha2.MondooOperatorConfig, namespaces []string) *monitoringv1.ServiceMonitor {
+	ls := labelsForMondoo(m.Name)
+	for key, value := range s.Config.Spec.Metrics.ResourceLabels {
+		ls[key] = value
+	}
+	return &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.resourceWatcherServiceMonitorName(),
+			Namespace: s.TargetNamespace,
+			Labels:    ls,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Endpoints: []monitoringv1.Endpoint{
+				{
+					Path:   "/metrics",
+					Port:   resourcewatcher.MetricsPortName,
+					Scheme: ptr.To(monitoringv1.SchemeHTTP),
+				},
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					// The key/value set for all resource watcher metrics Services
+					"app": "mondoo-resource-watcher",
+				},
+			},
+			NamespaceSelector: monitoringv1.NamespaceSelector{
+				MatchNames: namespaces,
+			},
+		},
+	}
+}
+
+// declareResourceWatcherMetrics creates a metrics Service for every MondooAuditConfig with the resource watcher
+// enabled and, if the ServiceMonitor CRD is available, a ServiceMonitor scraping all of them.
+func (s *ServiceMonitor) declareResourceWatcherMetrics(ctx context.Context, clt client.Client, scheme *runtime.Scheme, serviceMonitorCRDFound bool) error {
+	log := ctrllog.FromContext(ctx)
+
+	auditConfigs := &mondoov1alpha2.MondooAuditConfigList{}
+	if err := clt.List(ctx, auditConfigs); err != nil {
+		log.Error(err, "Failed to list MondooAuditConfigs")
+		return err
+	}
+
+	namespaces := sets.New[string]()
+	for i := range auditConfigs.Items {
+		a := &auditConfigs.Items[i]
+		if !a.DeletionTimestamp.IsZero() || !a.Spec.KubernetesResources.Enable || !a.Spec.KubernetesResources.ResourceWatcher.Enable {
+			if err := k8s.DeleteIfExists(ctx, clt, resourcewatcher.MetricsService(a)); err != nil {
+				log.Error(err, "Failed to delete resource watcher metrics Service", "namespace", a.Namespace, "name", resourcewatcher.MetricsServiceName(a.Name))
+				return err
+			}
+			continue
+		}
+
+		desired := resourcewatcher.MetricsService(a)
+		obj := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+		if _, err := k8s.CreateOrUpdate(ctx, clt, obj, a, log, func() error {
+			obj.Labels = desired.Labels
+			obj.Spec.Type = desired.Spec.Type
+			obj.Spec.Selector = desired.Spec.Selector
+			obj.Spec.Ports = desired.Spec.Ports
+			return nil
+		}); err != nil {
+			return err
+		}
+		namespaces.Insert(a.Namespace)
+	}
+
+	if !serviceMonitorCRDFound {
+		return nil
+	}
+
+	monitor := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: s.resourceWatcherServiceMonitorName(), Namespace: s.TargetNamespace}}
+	if namespaces.Len() == 0 {
+		return k8s.DeleteIfExists(ctx, clt, monitor)
+	}
+
+	declared := s.resourceWatcherServiceMonitorForMondoo(s.Config, sets.List(namespaces))
+	_, err := ctrl.CreateOrUpdate(ctx, clt, monitor, func() error {
+		monitor.Labels = declared.Labels
+		monitor.Spec = declared.Spec
+		return ctrl.SetControllerReference(s.Config, monitor, scheme)
+	})
+	if err != nil {
+		log.Error(err, "Failed to create or update resource watcher ServiceMonitor", "ServiceMonitor.Namespace", monitor.Namespace, "ServiceMonitor.Name", monitor.Name)
+	}
+	return err
+}
+
+// downResourceWatcherMetrics removes the metrics Services and the ServiceMonitor created for resource watchers.
+func (s *ServiceMonitor) downResourceWatcherMetrics(ctx context.Context, clt client.Client, serviceMonitorCRDFound bool) error {
+	log := ctrllog.FromContext(ctx)
+
+	auditConfigs := &mondoov1alpha2.MondooAuditConfigList{}
+	if err := clt.List(ctx, auditConfigs); err != nil {
+		log.Error(err, "Failed to list MondooAuditConfigs")
+		return err
+	}
+	for i := range auditConfigs.Items {
+		if err := k8s.DeleteIfExists(ctx, clt, resourcewatcher.MetricsService(&auditConfigs.Items[i])); err != nil {
+			log.Error(err, "Failed to delete resource watcher metrics Service", "namespace", auditConfigs.Items[i].Namespace)
+			return err
+		}
+	}
+
+	if !serviceMonitorCRDFound {
+		return nil
+	}
+	monitor := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: s.resourceWatcherServiceMonitorName(), Namespace: s.TargetNamespace}}
+	return k8s.DeleteIfExists(ctx, clt, monitor)
+}
+
 func (s *ServiceMonitor) Reconcile(ctx context.Context, clt client.Client, scheme *runtime.Scheme, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrllog.FromContext(ctx)
 	found, err := k8s.VerifyAPI(monitoringv1.SchemeGroupVersion.Group, monitoringv1.SchemeGroupVersion.Version)
@@ -144,6 +264,11 @@ func (s *ServiceMonitor) Reconcile(ctx context.Context, clt client.Client, schem
 			return ctrl.Result{}, err
 		}
 
+		// Services for the resource watchers are useful even without the ServiceMonitor CRD
+		if err := s.declareResourceWatcherMetrics(ctx, clt, scheme, found); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		if !found {
 			// exit early as there is no ServiceMonitor CRD
 			return ctrl.Result{}, nil
@@ -154,6 +279,9 @@ func (s *ServiceMonitor) Reconcile(ctx context.Context, clt client.Client, schem
 			return result, err
 		}
 	} else {
+		if err := s.downResourceWatcherMetrics(ctx, clt, found); err != nil {
+			return ctrl.Result{}, err
+		}
 		if found {
 			return s.down(ctx, clt)
 		}
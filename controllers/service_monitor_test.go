@@ -106,3 +106,28 @@ func TestServiceMonitorForMondoo_HTTPS(t *testing.T) {
 	assert.NotEmpty(t, ep.BearerTokenFile) //nolint:staticcheck
 	require.NotNil(t, ep.TLSConfig)
 }
+
+func TestResourceWatcherServiceMonitor(t *testing.T) {
+	sm := &ServiceMonitor{
+		Config: &mondoov1alpha2.MondooOperatorConfig{
+			Spec: mondoov1alpha2.MondooOperatorConfigSpec{
+				Metrics: mondoov1alpha2.Metrics{
+					Enable:         true,
+					ResourceLabels: map[string]string{"prometheus": "main"},
+				},
+			},
+		},
+		TargetNamespace: "mondoo-operator",
+	}
+
+	monitor := sm.resourceWatcherServiceMonitorForMondoo(sm.Config, []string{"team-a", "team-b"})
+
+	assert.Equal(t, "mondoo-resource-watcher-metrics-monitor", monitor.Name)
+	assert.Equal(t, "mondoo-operator", monitor.Namespace)
+	assert.Equal(t, "main", monitor.Labels["prometheus"])
+	assert.Equal(t, "mondoo-resource-watcher", monitor.Spec.Selector.MatchLabels["app"])
+	assert.Equal(t, []string{"team-a", "team-b"}, monitor.Spec.NamespaceSelector.MatchNames)
+	require.Len(t, monitor.Spec.Endpoints, 1)
+	assert.Equal(t, "metrics", monitor.Spec.Endpoints[0].Port)
+	assert.Equal(t, "/metrics", monitor.Spec.Endpoints[0].Path)
+}
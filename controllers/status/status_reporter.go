@@ -68,22 +68,27 @@ func (r *StatusReporter) Report(ctx context.Context, m v1alpha2.MondooAuditConfi
 		return nil // If the status hasn't change, don't report
 	}
 
-	serviceAccount, err := k8s.GetServiceAccountFromSecret(*secret)
+	tokenSource, err := mondoo.TokenSourceForSecret(secret)
 	if err != nil {
 		return err
 	}
 
-	token, err := mondoo.GenerateTokenFromServiceAccount(*serviceAccount, logger)
+	tlsConfig, err := mondoo.APITLSConfig(ctx, r.kubeClient, m.Namespace, &cfg)
+	if err != nil {
+		return err
+	}
+	proxies, err := k8s.ResolveProxyURLs(ctx, r.kubeClient, m.Namespace, &cfg)
 	if err != nil {
 		return err
 	}
 
 	mondooClient, err := r.mondooClientBuilder(mondooclient.MondooClientOptions{
-		ApiEndpoint: serviceAccount.ApiEndpoint,
-		Token:       token,
-		HttpProxy:   cfg.Spec.HttpProxy,
-		HttpsProxy:  cfg.Spec.HttpsProxy,
-		NoProxy:     cfg.Spec.NoProxy,
+		ApiEndpoint: tokenSource.ServiceAccount().ApiEndpoint,
+		TokenSource: tokenSource,
+		HttpProxy:   proxies.HttpProxy,
+		HttpsProxy:  proxies.HttpsProxy,
+		NoProxy:     proxies.NoProxy,
+		TLSConfig:   tlsConfig,
 	})
 	if err != nil {
 		return err
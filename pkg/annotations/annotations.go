@@ -37,21 +37,35 @@ const maxAnnotationLength = 256
 // Values must be non-empty.
 func Validate(annotations map[string]string) error {
 	for k, v := range annotations {
-		if k == "" {
-			return fmt.Errorf("annotation key must not be empty")
+		if err := validateKey(k); err != nil {
+			return err
 		}
-		if strings.Contains(k, "=") {
-			return fmt.Errorf("annotation key %q must not contain '='", k)
-		}
-		if len(k) > maxAnnotationLength {
-			return fmt.Errorf("annotation key %q exceeds maximum length of %d characters", k, maxAnnotationLength)
-		}
-		if v == "" {
-			return fmt.Errorf("annotation value for key %q must not be empty", k)
-		}
-		if len(v) > maxAnnotationLength {
-			return fmt.Errorf("annotation value for key %q exceeds maximum length of %d characters", k, maxAnnotationLength)
+		if err := validateValue(k, v); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+func validateKey(k string) error {
+	if k == "" {
+		return fmt.Errorf("annotation key must not be empty")
+	}
+	if strings.Contains(k, "=") {
+		return fmt.Errorf("annotation key %q must not contain '='", k)
+	}
+	if len(k) > maxAnnotationLength {
+		return fmt.Errorf("annotation key %q exceeds maximum length of %d characters", k, maxAnnotationLength)
+	}
+	return nil
+}
+
+func validateValue(k, v string) error {
+	if v == "" {
+		return fmt.Errorf("annotation value for key %q must not be empty", k)
+	}
+	if len(v) > maxAnnotationLength {
+		return fmt.Errorf("annotation value for key %q exceeds maximum length of %d characters", k, maxAnnotationLength)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package annotations
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// LabelSource defines whose labels an annotation value is taken from.
+type LabelSource string
+
+const (
+	// ObjectLabel takes the value from a label of the scanned resource.
+	ObjectLabel LabelSource = "metadata"
+	// NamespaceLabel takes the value from a label of the namespace of the scanned resource.
+	NamespaceLabel LabelSource = "namespace"
+)
+
+// labelRuleExpr matches metadata.labels['<label>'] and namespace.labels['<label>'], with single or double quotes.
+var labelRuleExpr = regexp.MustCompile(`^(metadata|namespace)\.labels\[(?:'([^']+)'|"([^"]+)")\]$`)
+
+// LabelRule derives the value of an annotation from a Kubernetes label.
+type LabelRule struct {
+	// Annotation is the key of the annotation added to the asset.
+	Annotation string
+	// Label is the key of the label the value is taken from.
+	Label string
+	// Source defines whose labels are read.
+	Source LabelSource
+}
+
+// ParseLabelRules parses a map of annotation keys to label expressions, as used by
+// spec.annotationsFromLabels. The rules are sorted by annotation key.
+func ParseLabelRules(rules map[string]string) ([]LabelRule, error) {
+	parsed := make([]LabelRule, 0, len(rules))
+	for k, expr := range rules {
+		if err := validateKey(k); err != nil {
+			return nil, err
+		}
+		match := labelRuleExpr.FindStringSubmatch(strings.TrimSpace(expr))
+		if match == nil {
+			return nil, fmt.Errorf("annotation %q: invalid label expression %q, expected metadata.labels['<label>'] or namespace.labels['<label>']", k, expr)
+		}
+		label := match[2] + match[3]
+		if errs := validation.IsQualifiedName(label); len(errs) > 0 {
+			return nil, fmt.Errorf("annotation %q: invalid label key %q: %s", k, label, strings.Join(errs, "; "))
+		}
+		parsed = append(parsed, LabelRule{Annotation: k, Label: label, Source: LabelSource(match[1])})
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Annotation < parsed[j].Annotation })
+	return parsed, nil
+}
+
+// ValidateFromLabels checks that the rules of spec.annotationsFromLabels can be parsed and that their
+// annotation keys pass the same checks as Validate.
+func ValidateFromLabels(rules map[string]string) error {
+	_, err := ParseLabelRules(rules)
+	return err
+}
+
+// HasSource returns true if one of the rules reads labels from source.
+func HasSource(rules []LabelRule, source LabelSource) bool {
+	for _, r := range rules {
+		if r.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// FromLabels returns the annotations the rules derive from the labels of a resource and its namespace.
+// Rules whose label is missing, or whose value would not pass Validate, add no annotation.
+func FromLabels(rules []LabelRule, objectLabels, namespaceLabels map[string]string) map[string]string {
+	derived := map[string]string{}
+	for _, r := range rules {
+		labels := objectLabels
+		if r.Source == NamespaceLabel {
+			labels = namespaceLabels
+		}
+		v := labels[r.Label]
+		if validateValue(r.Annotation, v) != nil {
+			continue
+		}
+		derived[r.Annotation] = v
+	}
+	return derived
+}
+
+// FromLabelsArgs converts the rules of spec.annotationsFromLabels into sorted CLI arguments for the
+// resource watcher's --annotation-from-label flag.
+func FromLabelsArgs(rules map[string]string) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(rules))
+	for k := range rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(rules)*2)
+	for _, key := range keys {
+		args = append(args, "--annotation-from-label", fmt.Sprintf("%s=%s", key, rules[key]))
+	}
+	return args
+}
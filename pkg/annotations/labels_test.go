@@ -0,0 +1,84 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package annotations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelRules(t *testing.T) {
+	t.Run("valid rules are sorted by annotation", func(t *testing.T) {
+		rules, err := ParseLabelRules(map[string]string{
+			"team":        "metadata.labels['app.kubernetes.io/team']",
+			"cost-center": `namespace.labels["cost-center"]`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []LabelRule{
+			{Annotation: "cost-center", Label: "cost-center", Source: NamespaceLabel},
+			{Annotation: "team", Label: "app.kubernetes.io/team", Source: ObjectLabel},
+		}, rules)
+	})
+
+	t.Run("nil map is valid", func(t *testing.T) {
+		rules, err := ParseLabelRules(nil)
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("invalid annotation key is rejected", func(t *testing.T) {
+		_, err := ParseLabelRules(map[string]string{"a=b": "metadata.labels['team']"})
+		assert.ErrorContains(t, err, "must not contain '='")
+
+		_, err = ParseLabelRules(map[string]string{strings.Repeat("a", 257): "metadata.labels['team']"})
+		assert.ErrorContains(t, err, "exceeds maximum length")
+	})
+
+	t.Run("invalid expression is rejected", func(t *testing.T) {
+		_, err := ParseLabelRules(map[string]string{"team": "spec.labels['team']"})
+		assert.ErrorContains(t, err, "invalid label expression")
+
+		_, err = ParseLabelRules(map[string]string{"team": "metadata.labels[team]"})
+		assert.ErrorContains(t, err, "invalid label expression")
+	})
+
+	t.Run("invalid label key is rejected", func(t *testing.T) {
+		_, err := ParseLabelRules(map[string]string{"team": "metadata.labels['team owner']"})
+		assert.ErrorContains(t, err, "invalid label key")
+	})
+}
+
+func TestFromLabels(t *testing.T) {
+	rules, err := ParseLabelRules(map[string]string{
+		"team":        "metadata.labels['app.kubernetes.io/team']",
+		"cost-center": "namespace.labels['cost-center']",
+	})
+	require.NoError(t, err)
+
+	t.Run("values are taken from the object and namespace", func(t *testing.T) {
+		derived := FromLabels(rules,
+			map[string]string{"app.kubernetes.io/team": "payments", "cost-center": "ignored"},
+			map[string]string{"cost-center": "cc-42"})
+		assert.Equal(t, map[string]string{"team": "payments", "cost-center": "cc-42"}, derived)
+	})
+
+	t.Run("missing and empty labels add no annotation", func(t *testing.T) {
+		derived := FromLabels(rules, map[string]string{"app.kubernetes.io/team": ""}, nil)
+		assert.Empty(t, derived)
+	})
+}
+
+func TestFromLabelsArgs(t *testing.T) {
+	assert.Nil(t, FromLabelsArgs(nil))
+	assert.Equal(t, []string{
+		"--annotation-from-label", "cost-center=namespace.labels['cost-center']",
+		"--annotation-from-label", "team=metadata.labels['team']",
+	}, FromLabelsArgs(map[string]string{
+		"team":        "metadata.labels['team']",
+		"cost-center": "namespace.labels['cost-center']",
+	}))
+}
@@ -6,6 +6,7 @@ package common
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -28,6 +29,12 @@ func DefaultHttpClient(httpProxy *string, httpTimeout *time.Duration) (http.Clie
 }
 
 func DefaultHttpClientWithProxy(httpProxy *string, httpsProxy *string, noProxy *string, httpTimeout *time.Duration) (http.Client, error) {
+	return DefaultHttpClientWithTLS(httpProxy, httpsProxy, noProxy, httpTimeout, nil)
+}
+
+// DefaultHttpClientWithTLS returns the default HTTP client with the given proxies and TLS configuration. A nil
+// tlsConfig uses the system CAs without a client certificate.
+func DefaultHttpClientWithTLS(httpProxy *string, httpsProxy *string, noProxy *string, httpTimeout *time.Duration, tlsConfig *tls.Config) (http.Client, error) {
 	tr := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
@@ -38,6 +45,7 @@ func DefaultHttpClientWithProxy(httpProxy *string, httpsProxy *string, noProxy *
 		IdleConnTimeout:       defaultIdleConnTimeout,
 		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
 		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
 	}
 
 	if httpProxy != nil || httpsProxy != nil {
@@ -139,6 +147,8 @@ func shouldBypassProxy(host string, noProxy string) bool {
 	return false
 }
 
+// Request sends a single POST request to the Mondoo API. Responses with a status other than 200 OK are
+// returned as *HTTPError, failures to send the request or to read the response as *NetworkError.
 func Request(ctx context.Context, client http.Client, url, token string, reqBodyBytes []byte) ([]byte, error) {
 	header := make(http.Header)
 	header.Set("Accept", "application/json")
@@ -158,7 +168,7 @@ func Request(ctx context.Context, client http.Client, url, token string, reqBody
 	// do http call
 	resp, err := client.Do(req) //nolint:gosec
 	if err != nil {
-		return nil, fmt.Errorf("failed to do request: %v", err)
+		return nil, &NetworkError{Err: err}
 	}
 
 	defer func() {
@@ -169,11 +179,15 @@ func Request(ctx context.Context, client http.Client, url, token string, reqBody
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read http response body: %s", err)
+		return nil, &NetworkError{Err: fmt.Errorf("failed to read http response body: %w", err)}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http status %d: %s", resp.StatusCode, respBody)
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	return respBody, nil
@@ -0,0 +1,104 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy configures the retries of idempotent requests. Requests that fail with a retryable error
+// (see IsRetryable) are retried with exponential backoff and full jitter.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retries.
+	MaxRetries int
+	// InitialBackoff is the upper bound of the delay before the first retry. It doubles with every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts. If the server asks for a longer delay with
+	// Retry-After, the request is not retried and the error is returned.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used if none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     defaultMaxRetries,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+	}
+}
+
+// backoff returns the jittered delay before the given retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.InitialBackoff << (retry - 1)
+	if ceiling <= 0 || ceiling > p.MaxBackoff {
+		ceiling = p.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) //nolint:gosec
+}
+
+// RequestOptions configures how RequestWithRetry sends a request.
+type RequestOptions struct {
+	// Retry is the retry policy. Only set it for idempotent requests.
+	Retry RetryPolicy
+	// Limiter limits the rate of the attempts. Optional.
+	Limiter *rate.Limiter
+	// Token returns the token of each attempt, so retries after a long backoff don't send an expired
+	// token. If set, it takes precedence over the token argument of RequestWithRetry. Optional.
+	Token func() (string, error)
+}
+
+// RequestWithRetry sends a POST request to the Mondoo API like Request. Every attempt waits for the
+// rate limiter, and attempts that fail with a retryable error are retried according to the retry policy.
+// A delay requested by the server with Retry-After takes precedence over the backoff.
+func RequestWithRetry(ctx context.Context, client http.Client, url, token string, reqBodyBytes []byte, opts RequestOptions) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if opts.Limiter != nil {
+			if err := opts.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if opts.Token != nil {
+			var err error
+			if token, err = opts.Token(); err != nil {
+				return nil, err
+			}
+		}
+
+		respBody, err := Request(ctx, client, url, token, reqBodyBytes)
+		if err == nil || attempt >= opts.Retry.MaxRetries || !IsRetryable(err) {
+			return respBody, err
+		}
+
+		delay := opts.Retry.backoff(attempt + 1)
+		if retryAfter, ok := RetryAfter(err); ok {
+			if retryAfter > opts.Retry.MaxBackoff {
+				return nil, err
+			}
+			delay = retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
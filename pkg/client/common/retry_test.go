@@ -0,0 +1,189 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// testServer answers with the given status codes in order and with 200 OK once they are used up.
+func testServer(t *testing.T, header http.Header, statusCodes ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		if call <= len(statusCodes) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statusCodes[call-1])
+			_, _ = w.Write([]byte("failed"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func fastRetries(maxRetries int) RetryPolicy {
+	return RetryPolicy{MaxRetries: maxRetries, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+}
+
+func TestRequest_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		status    int
+		want      error
+		retryable bool
+	}{
+		{status: http.StatusUnauthorized, want: ErrUnauthorized},
+		{status: http.StatusForbidden, want: ErrForbidden},
+		{status: http.StatusNotFound, want: ErrNotFound},
+		{status: http.StatusTooManyRequests, want: ErrRateLimited, retryable: true},
+		{status: http.StatusBadGateway, want: ErrServerError, retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server, _ := testServer(t, nil, tt.status)
+			_, err := Request(context.Background(), *server.Client(), server.URL, "", nil)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.want)
+			assert.Equal(t, tt.retryable, IsRetryable(err))
+
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.status, httpErr.StatusCode)
+			assert.Equal(t, "failed", httpErr.Body)
+		})
+	}
+
+	t.Run("network", func(t *testing.T) {
+		server, _ := testServer(t, nil)
+		server.Close()
+		_, err := Request(context.Background(), http.Client{}, server.URL, "", nil)
+		assert.ErrorIs(t, err, ErrNetwork)
+		assert.True(t, IsRetryable(err))
+	})
+}
+
+func TestRequestWithRetry(t *testing.T) {
+	t.Run("retries server errors", func(t *testing.T) {
+		server, calls := testServer(t, nil, http.StatusServiceUnavailable, http.StatusInternalServerError)
+		body, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: fastRetries(3)})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server, calls := testServer(t, nil, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: fastRetries(1)})
+		assert.ErrorIs(t, err, ErrServerError)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		server, calls := testServer(t, nil, http.StatusUnauthorized)
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: fastRetries(3)})
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("does not retry without policy", func(t *testing.T) {
+		server, calls := testServer(t, nil, http.StatusServiceUnavailable)
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{})
+		assert.ErrorIs(t, err, ErrServerError)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		server, calls := testServer(t, http.Header{"Retry-After": []string{"1"}}, http.StatusTooManyRequests)
+		policy := RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Second}
+		start := time.Now()
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: policy})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("returns rate limit error if Retry-After exceeds max backoff", func(t *testing.T) {
+		server, calls := testServer(t, http.Header{"Retry-After": []string{"60"}}, http.StatusTooManyRequests)
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: fastRetries(3)})
+		assert.ErrorIs(t, err, ErrRateLimited)
+		retryAfter, ok := RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, time.Minute, retryAfter)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		server, calls := testServer(t, nil, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		policy := RetryPolicy{MaxRetries: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+		_, err := RequestWithRetry(ctx, *server.Client(), server.URL, "", nil, RequestOptions{Retry: policy})
+		require.Error(t, err)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("gets a token for every attempt", func(t *testing.T) {
+		var auth []string
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = append(auth, r.Header.Get("Authorization"))
+			if calls++; calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		tokens := 0
+		token := func() (string, error) {
+			tokens++
+			return fmt.Sprintf("token-%d", tokens), nil
+		}
+		_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Retry: fastRetries(1), Token: token})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, auth)
+	})
+
+	t.Run("rate limiter", func(t *testing.T) {
+		server, calls := testServer(t, nil)
+		limiter := rate.NewLimiter(rate.Every(100*time.Millisecond), 1)
+		start := time.Now()
+		for range 3 {
+			_, err := RequestWithRetry(context.Background(), *server.Client(), server.URL, "", nil, RequestOptions{Limiter: limiter})
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-1", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
+
+func TestIsRetryable_Context(t *testing.T) {
+	err := &NetworkError{Err: context.Canceled}
+	assert.ErrorIs(t, err, ErrNetwork)
+	assert.False(t, IsRetryable(err))
+	assert.False(t, IsRetryable(errors.New("other")))
+}
@@ -5,54 +5,116 @@ package mondooclient
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"go.mondoo.com/mondoo-operator/pkg/client/common"
 )
 
 const (
-	ExchangeRegistrationTokenEndpoint = "/AgentManager/ExchangeRegistrationToken"
-	IntegrationRegisterEndpoint       = "/IntegrationsManager/Register"
-	IntegrationCheckInEndpoint        = "/IntegrationsManager/CheckIn"
-	IntegrationConfigureEndpoint      = "/IntegrationsManager/Configure"
-	IntegrationReportStatusEndpoint   = "/IntegrationsManager/ReportStatus"
-	GarbageCollectAssetsEndpoint      = "/PolicyResolver/PurgeAssets"
-	RefreshAssetScoresEndpoint        = "/PolicyResolver/RefreshAssetScores"
+	ExchangeRegistrationTokenEndpoint   = "/AgentManager/ExchangeRegistrationToken"
+	PingPongEndpoint                    = "/AgentManager/PingPong"
+	IntegrationRegisterEndpoint         = "/IntegrationsManager/Register"
+	IntegrationCheckInEndpoint          = "/IntegrationsManager/CheckIn"
+	IntegrationConfigureEndpoint        = "/IntegrationsManager/Configure"
+	IntegrationReportStatusEndpoint     = "/IntegrationsManager/ReportStatus"
+	GarbageCollectAssetsEndpoint        = "/PolicyResolver/PurgeAssets"
+	PreviewGarbageCollectAssetsEndpoint = "/PolicyResolver/PreviewPurgeAssets"
+	RefreshAssetScoresEndpoint          = "/PolicyResolver/RefreshAssetScores"
+	DeleteAssetsEndpoint                = "/AssetStore/DeleteAssets"
 )
 
+// TokenSource provides the tokens the client authenticates with. The client asks for a token before every
+// request, so long-lived clients keep working when tokens expire.
+type TokenSource interface {
+	Token() (string, error)
+}
+
 type MondooClientOptions struct {
 	ApiEndpoint string
-	Token       string
+	// Token is a static token. It is ignored if TokenSource is set.
+	Token string
+	// TokenSource provides the tokens of the requests. Optional.
+	TokenSource TokenSource
 	HttpProxy   *string
 	HttpsProxy  *string
 	NoProxy     *string
 	HttpTimeout *time.Duration
+	// TLSConfig configures the CAs the client trusts and its client certificate. If nil, the system CAs are
+	// trusted and no client certificate is presented.
+	TLSConfig *tls.Config
+	// Retry configures the retries of idempotent calls. If nil, common.DefaultRetryPolicy is used.
+	Retry *common.RetryPolicy
+	// RateLimiter limits the rate of the requests of the client. Share it between clients to limit the
+	// rate of all of them. Optional.
+	RateLimiter *rate.Limiter
 }
 
 type mondooClient struct {
 	ApiEndpoint string
 	Token       string
+	tokenSource TokenSource
 	httpClient  http.Client
+	retry       common.RetryPolicy
+	limiter     *rate.Limiter
 }
 
 func NewClient(opts MondooClientOptions) (MondooClient, error) {
 	opts.ApiEndpoint = strings.TrimRight(opts.ApiEndpoint, "/")
-	client, err := common.DefaultHttpClientWithProxy(opts.HttpProxy, opts.HttpsProxy, opts.NoProxy, opts.HttpTimeout)
+	client, err := common.DefaultHttpClientWithTLS(opts.HttpProxy, opts.HttpsProxy, opts.NoProxy, opts.HttpTimeout, opts.TLSConfig)
 	if err != nil {
 		return nil, err
 	}
+	retry := common.DefaultRetryPolicy()
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
 	mClient := &mondooClient{
 		ApiEndpoint: opts.ApiEndpoint,
 		Token:       opts.Token,
+		tokenSource: opts.TokenSource,
 		httpClient:  client,
+		retry:       retry,
+		limiter:     opts.RateLimiter,
 	}
 	return mClient, nil
 }
 
+// token returns the token of the next request.
+func (s *mondooClient) token() (string, error) {
+	if s.tokenSource == nil {
+		return s.Token, nil
+	}
+	token, err := s.tokenSource.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
+}
+
+// request sends a request that is not retried, because sending it twice could have a different effect
+// than sending it once.
+func (s *mondooClient) request(ctx context.Context, url string, reqBodyBytes []byte) ([]byte, error) {
+	opts := common.RequestOptions{Limiter: s.limiter}
+	// Every attempt gets a fresh token
+	opts.Token = s.token
+	return common.RequestWithRetry(ctx, s.httpClient, url, "", reqBodyBytes, opts)
+}
+
+// idempotentRequest sends a request that is retried according to the retry policy of the client.
+func (s *mondooClient) idempotentRequest(ctx context.Context, url string, reqBodyBytes []byte) ([]byte, error) {
+	opts := common.RequestOptions{Retry: s.retry, Limiter: s.limiter}
+	// Every attempt gets a fresh token
+	opts.Token = s.token
+	return common.RequestWithRetry(ctx, s.httpClient, url, "", reqBodyBytes, opts)
+}
+
 func (s *mondooClient) ExchangeRegistrationToken(ctx context.Context, in *ExchangeRegistrationTokenInput) (*ExchangeRegistrationTokenOutput, error) {
 	url := s.ApiEndpoint + ExchangeRegistrationTokenEndpoint
 
@@ -61,9 +123,9 @@ func (s *mondooClient) ExchangeRegistrationToken(ctx context.Context, in *Exchan
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.request(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	out := &ExchangeRegistrationTokenOutput{
@@ -81,9 +143,9 @@ func (s *mondooClient) HealthCheck(ctx context.Context, in *common.HealthCheckRe
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	out := &common.HealthCheckResponse{}
@@ -94,6 +156,27 @@ func (s *mondooClient) HealthCheck(ctx context.Context, in *common.HealthCheckRe
 	return out, nil
 }
 
+func (s *mondooClient) PingPong(ctx context.Context, in *Ping) (*Pong, error) {
+	url := s.ApiEndpoint + PingPongEndpoint
+
+	reqBodyBytes, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make ping pong request: %w", err)
+	}
+
+	out := &Pong{}
+	if err = json.Unmarshal(respBodyBytes, out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return out, nil
+}
+
 func (s *mondooClient) IntegrationRegister(ctx context.Context, in *IntegrationRegisterInput) (*IntegrationRegisterOutput, error) {
 	url := s.ApiEndpoint + IntegrationRegisterEndpoint
 
@@ -102,9 +185,9 @@ func (s *mondooClient) IntegrationRegister(ctx context.Context, in *IntegrationR
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.request(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	out := &IntegrationRegisterOutput{}
@@ -123,9 +206,9 @@ func (s *mondooClient) IntegrationCheckIn(ctx context.Context, in *IntegrationCh
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	out := &IntegrationCheckInOutput{}
@@ -144,9 +227,9 @@ func (s *mondooClient) IntegrationConfigure(ctx context.Context, in *Integration
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	out := &IntegrationConfigureOutput{}
@@ -165,9 +248,9 @@ func (s *mondooClient) IntegrationReportStatus(ctx context.Context, in *ReportSt
 		return fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	_, err = common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	_, err = s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return fmt.Errorf("failed to parse response: %v", err)
+		return fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	return nil
@@ -181,14 +264,35 @@ func (s *mondooClient) GarbageCollectAssets(ctx context.Context, req *GarbageCol
 		return fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	_, err = common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	_, err = s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return fmt.Errorf("failed to make garbage collect assets request: %v", err)
+		return fmt.Errorf("failed to make garbage collect assets request: %w", err)
 	}
 
 	return nil
 }
 
+func (s *mondooClient) PreviewGarbageCollectAssets(ctx context.Context, req *GarbageCollectAssetsRequest) (*PreviewGarbageCollectAssetsResponse, error) {
+	url := s.ApiEndpoint + PreviewGarbageCollectAssetsEndpoint
+
+	reqBodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make preview garbage collect assets request: %w", err)
+	}
+
+	out := &PreviewGarbageCollectAssetsResponse{}
+	if err = json.Unmarshal(respBodyBytes, out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return out, nil
+}
+
 func (s *mondooClient) RefreshAssetScores(ctx context.Context, req *RefreshAssetScoresRequest) (*RefreshAssetScoresResponse, error) {
 	url := s.ApiEndpoint + RefreshAssetScoresEndpoint
 
@@ -197,9 +301,9 @@ func (s *mondooClient) RefreshAssetScores(ctx context.Context, req *RefreshAsset
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	respBodyBytes, err := common.Request(ctx, s.httpClient, url, s.Token, reqBodyBytes)
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make refresh asset scores request: %v", err)
+		return nil, fmt.Errorf("failed to make refresh asset scores request: %w", err)
 	}
 
 	out := &RefreshAssetScoresResponse{}
@@ -209,3 +313,26 @@ func (s *mondooClient) RefreshAssetScores(ctx context.Context, req *RefreshAsset
 
 	return out, nil
 }
+
+// DeleteAssets deletes the assets with the given platform IDs in the scope that are managed by
+// req.ManagedBy. The fake server in the fakeserver package implements the same contract.
+func (s *mondooClient) DeleteAssets(ctx context.Context, req *DeleteAssetsRequest) (*DeleteAssetsResponse, error) {
+	url := s.ApiEndpoint + DeleteAssetsEndpoint
+
+	reqBodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	respBodyBytes, err := s.idempotentRequest(ctx, url, reqBodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make delete assets request: %w", err)
+	}
+
+	out := &DeleteAssetsResponse{}
+	if err = json.Unmarshal(respBodyBytes, out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,50 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondooclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient/fakeserver"
+)
+
+func TestDeleteAssets(t *testing.T) {
+	server := fakeserver.FakeServer()
+	t.Cleanup(server.Close)
+
+	client, err := mondooclient.NewClient(mondooclient.MondooClientOptions{ApiEndpoint: server.URL, Token: "token"})
+	require.NoError(t, err)
+
+	scopeMrn := "//captain.api.mondoo.app/spaces/test"
+	platformIDs := []string{
+		"//platformid.api.mondoo.app/runtime/k8s/uid/abcd/namespace/default/deployment/name/web",
+		"//platformid.api.mondoo.app/runtime/k8s/uid/abcd/namespace/default/deployment/name/api",
+	}
+	resp, err := client.DeleteAssets(context.Background(), &mondooclient.DeleteAssetsRequest{
+		ScopeMrn:    scopeMrn,
+		ManagedBy:   "mondoo-operator-abcd",
+		PlatformIds: platformIDs,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		fakeserver.FakeAssetMrn(scopeMrn, platformIDs[0]),
+		fakeserver.FakeAssetMrn(scopeMrn, platformIDs[1]),
+	}, resp.AssetMrns)
+
+	// Requests without ManagedBy are rejected, so assets of other managers can't be deleted
+	_, err = client.DeleteAssets(context.Background(), &mondooclient.DeleteAssetsRequest{
+		ScopeMrn:    scopeMrn,
+		PlatformIds: platformIDs,
+	})
+	var httpErr *common.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+}
@@ -4,14 +4,25 @@
 package fakeserver
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 
 	"go.mondoo.com/mondoo-operator/pkg/client/common"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 )
 
 func FakeServer() *httptest.Server {
+	return FakeServerWithAssets()
+}
+
+// FakeServerWithAssets returns a fake Mondoo API whose garbage collection matches the given assets. Purging
+// deletes all of them.
+func FakeServerWithAssets(assets ...mondooclient.GarbageCollectAsset) *httptest.Server {
+	var mu sync.Mutex
 	mux := http.NewServeMux()
 	mux.HandleFunc(common.HealthCheckEndpoint, func(w http.ResponseWriter, r *http.Request) {
 		result := &common.HealthCheckResponse{
@@ -27,5 +38,72 @@ func FakeServer() *httptest.Server {
 			return
 		}
 	})
+	mux.HandleFunc(mondooclient.PingPongEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		if _, err := w.Write([]byte("{}")); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	})
+	mux.HandleFunc(mondooclient.PreviewGarbageCollectAssetsEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		result := &mondooclient.PreviewGarbageCollectAssetsResponse{
+			Assets:     assets,
+			TotalCount: int64(len(assets)),
+		}
+		data, err := json.Marshal(result)
+		mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if _, err = w.Write(data); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	})
+	mux.HandleFunc(mondooclient.GarbageCollectAssetsEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		assets = nil
+		mu.Unlock()
+		if _, err := w.Write([]byte("{}")); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	})
+	mux.HandleFunc(mondooclient.DeleteAssetsEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		req := &mondooclient.DeleteAssetsRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Assets of other managers are never deleted
+		if req.ScopeMrn == "" || req.ManagedBy == "" {
+			http.Error(w, "scope_mrn and managed_by are required", http.StatusBadRequest)
+			return
+		}
+		result := &mondooclient.DeleteAssetsResponse{}
+		for _, platformID := range req.PlatformIds {
+			result.AssetMrns = append(result.AssetMrns, FakeAssetMrn(req.ScopeMrn, platformID))
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		if _, err = w.Write(data); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+	})
 	return httptest.NewServer(mux)
 }
+
+// FakeAssetMrn returns the MRN the fake Mondoo API reports for the deleted asset with the given platform ID.
+func FakeAssetMrn(scopeMrn, platformID string) string {
+	sum := sha256.Sum256([]byte(platformID))
+	return scopeMrn + "/assets/" + hex.EncodeToString(sum[:8])
+}
@@ -36,6 +36,21 @@ func (m *MockMondooClient) EXPECT() *MockMondooClientMockRecorder {
 	return m.recorder
 }
 
+// DeleteAssets mocks base method.
+func (m *MockMondooClient) DeleteAssets(arg0 context.Context, arg1 *mondooclient.DeleteAssetsRequest) (*mondooclient.DeleteAssetsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAssets", arg0, arg1)
+	ret0, _ := ret[0].(*mondooclient.DeleteAssetsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAssets indicates an expected call of DeleteAssets.
+func (mr *MockMondooClientMockRecorder) DeleteAssets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAssets", reflect.TypeOf((*MockMondooClient)(nil).DeleteAssets), arg0, arg1)
+}
+
 // ExchangeRegistrationToken mocks base method.
 func (m *MockMondooClient) ExchangeRegistrationToken(arg0 context.Context, arg1 *mondooclient.ExchangeRegistrationTokenInput) (*mondooclient.ExchangeRegistrationTokenOutput, error) {
 	m.ctrl.T.Helper()
@@ -139,6 +154,36 @@ func (mr *MockMondooClientMockRecorder) IntegrationReportStatus(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IntegrationReportStatus", reflect.TypeOf((*MockMondooClient)(nil).IntegrationReportStatus), arg0, arg1)
 }
 
+// PingPong mocks base method.
+func (m *MockMondooClient) PingPong(arg0 context.Context, arg1 *mondooclient.Ping) (*mondooclient.Pong, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingPong", arg0, arg1)
+	ret0, _ := ret[0].(*mondooclient.Pong)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PingPong indicates an expected call of PingPong.
+func (mr *MockMondooClientMockRecorder) PingPong(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingPong", reflect.TypeOf((*MockMondooClient)(nil).PingPong), arg0, arg1)
+}
+
+// PreviewGarbageCollectAssets mocks base method.
+func (m *MockMondooClient) PreviewGarbageCollectAssets(arg0 context.Context, arg1 *mondooclient.GarbageCollectAssetsRequest) (*mondooclient.PreviewGarbageCollectAssetsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewGarbageCollectAssets", arg0, arg1)
+	ret0, _ := ret[0].(*mondooclient.PreviewGarbageCollectAssetsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewGarbageCollectAssets indicates an expected call of PreviewGarbageCollectAssets.
+func (mr *MockMondooClientMockRecorder) PreviewGarbageCollectAssets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewGarbageCollectAssets", reflect.TypeOf((*MockMondooClient)(nil).PreviewGarbageCollectAssets), arg0, arg1)
+}
+
 // RefreshAssetScores mocks base method.
 func (m *MockMondooClient) RefreshAssetScores(arg0 context.Context, arg1 *mondooclient.RefreshAssetScoresRequest) (*mondooclient.RefreshAssetScoresResponse, error) {
 	m.ctrl.T.Helper()
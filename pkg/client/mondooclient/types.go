@@ -14,6 +14,8 @@ import (
 type MondooClient interface {
 	common.HealthCheckClient
 	ExchangeRegistrationToken(context.Context, *ExchangeRegistrationTokenInput) (*ExchangeRegistrationTokenOutput, error)
+	// PingPong is an authenticated no-op. It fails with common.ErrUnauthorized if the credentials are rejected.
+	PingPong(context.Context, *Ping) (*Pong, error)
 
 	IntegrationRegister(context.Context, *IntegrationRegisterInput) (*IntegrationRegisterOutput, error)
 	IntegrationCheckIn(context.Context, *IntegrationCheckInInput) (*IntegrationCheckInOutput, error)
@@ -21,9 +23,18 @@ type MondooClient interface {
 	IntegrationReportStatus(context.Context, *ReportStatusRequest) error
 
 	GarbageCollectAssets(context.Context, *GarbageCollectAssetsRequest) error
+	// PreviewGarbageCollectAssets lists the assets GarbageCollectAssets would delete for the same request.
+	PreviewGarbageCollectAssets(context.Context, *GarbageCollectAssetsRequest) (*PreviewGarbageCollectAssetsResponse, error)
 	RefreshAssetScores(context.Context, *RefreshAssetScoresRequest) (*RefreshAssetScoresResponse, error)
+	DeleteAssets(context.Context, *DeleteAssetsRequest) (*DeleteAssetsResponse, error)
 }
 
+// Ping is the request of PingPong
+type Ping struct{}
+
+// Pong is the response of PingPong
+type Pong struct{}
+
 // ExchangeRegistrationTokenInput is used for converting a JWT to a Mondoo service account
 type ExchangeRegistrationTokenInput struct {
 	// JWT token, only available during creation
@@ -148,6 +159,21 @@ type GarbageCollectAssetsRequest struct {
 	ScopeMrn        string            `protobuf:"bytes,8,opt,name=scope_mrn,json=scopeMrn,proto3" json:"scope_mrn,omitempty"`
 }
 
+// PreviewGarbageCollectAssetsResponse matches the server-side PreviewPurgeAssetsResponse proto on the
+// PolicyResolver service (/PolicyResolver/PreviewPurgeAssets). The server lists at most a page of the
+// matching assets, TotalCount counts all of them.
+type PreviewGarbageCollectAssetsResponse struct {
+	Assets     []GarbageCollectAsset `protobuf:"bytes,1,rep,name=assets,proto3" json:"assets,omitempty"`
+	TotalCount int64                 `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+}
+
+// GarbageCollectAsset is an asset that matches a GarbageCollectAssetsRequest.
+type GarbageCollectAsset struct {
+	Mrn         string `protobuf:"bytes,1,opt,name=mrn,proto3" json:"mrn,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	LastUpdated string `protobuf:"bytes,3,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+}
+
 type DateFilter struct {
 	Timestamp  string          `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Comparison Comparison      `protobuf:"varint,2,opt,name=comparison,proto3,enum=cnspec.policy.v1.Comparison" json:"comparison,omitempty"`
@@ -219,3 +245,17 @@ type AssetRefreshResult struct {
 	AssetMrn    string   `protobuf:"bytes,1,opt,name=asset_mrn,json=assetMrn,proto3" json:"asset_mrn,omitempty"`
 	PlatformIds []string `protobuf:"bytes,2,rep,name=platform_ids,json=platformIds,proto3" json:"platform_ids,omitempty"`
 }
+
+// DeleteAssetsRequest deletes specific assets identified by their platform IDs. Unlike
+// GarbageCollectAssetsRequest it does not filter by date, so it is used when the operator
+// knows a resource is gone. Only assets with a matching ManagedBy are deleted.
+type DeleteAssetsRequest struct {
+	ScopeMrn    string   `protobuf:"bytes,1,opt,name=scope_mrn,json=scopeMrn,proto3" json:"scope_mrn,omitempty"`
+	ManagedBy   string   `protobuf:"bytes,2,opt,name=managed_by,json=managedBy,proto3" json:"managed_by,omitempty"`
+	PlatformIds []string `protobuf:"bytes,3,rep,name=platform_ids,json=platformIds,proto3" json:"platform_ids,omitempty"`
+}
+
+type DeleteAssetsResponse struct {
+	// AssetMrns holds the MRNs of the deleted assets. Platform IDs without a matching asset are skipped.
+	AssetMrns []string `protobuf:"bytes,1,rep,name=asset_mrns,json=assetMrns,proto3" json:"asset_mrns,omitempty"`
+}
@@ -21,6 +21,12 @@ const (
 	MondooAuditConfigAnnotation          = "mondoo.com/audit-config/name"
 	MondooAuditConfigNamespaceAnnotation = "mondoo.com/audit-config/namespace"
 	MondooClusterNameAnnotation          = "mondoo.com/audit-config/cluster-name"
+
+	// MondooWatchAnnotation can be set to "false" on a resource to exclude it from the resource watcher
+	MondooWatchAnnotation = "mondoo.com/watch"
+
+	// MondooScanAnnotation can be set to "false" on a Namespace or workload to exclude it from scanning
+	MondooScanAnnotation = "mondoo.com/scan"
 )
 
 // AuditConfigAnnotations returns operator-managed annotations identifying the
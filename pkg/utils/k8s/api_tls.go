@@ -0,0 +1,77 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+)
+
+const (
+	// CABundleSecretKey is the key of the PEM encoded CA certificates in the Secret of caBundleSecretRef
+	CABundleSecretKey = "ca.crt"
+	// CABundleVolumeName is the volume name of the mounted CA bundle
+	CABundleVolumeName = "mondoo-ca-bundle"
+	// CABundleMountPath is where the CA bundle is mounted
+	CABundleMountPath = "/etc/opt/mondoo/ca-certificates"
+	// ClientCertificateVolumeName is the volume name of the mounted client certificate
+	ClientCertificateVolumeName = "mondoo-client-certificate"
+	// ClientCertificateMountPath is where the client certificate is mounted, with the keys tls.crt and tls.key
+	ClientCertificateMountPath = "/etc/opt/mondoo/client-certificate"
+)
+
+// caCertDirs is the value of SSL_CERT_DIR. It replaces the directories Go reads CA certificates from by default,
+// so they are kept in front of the mounted CA bundle.
+const caCertDirs = "/etc/ssl/certs:/etc/pki/tls/certs:" + CABundleMountPath
+
+// AddMondooAPITLSToSpec mounts the CA bundle and the client certificate of the MondooOperatorConfig into all
+// containers of a pod spec. The CA bundle is added to the trusted CAs with SSL_CERT_DIR.
+func AddMondooAPITLSToSpec(podSpec *corev1.PodSpec, cfg v1alpha2.MondooOperatorConfig) {
+	if ref := cfg.Spec.CABundleSecretRef; ref != nil && ref.Name != "" {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: CABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  ref.Name,
+					Items:       []corev1.KeyToPath{{Key: CABundleSecretKey, Path: CABundleSecretKey}},
+					DefaultMode: ptr.To(int32(0o444)),
+				},
+			},
+		})
+		for i := range podSpec.Containers {
+			container := &podSpec.Containers[i]
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      CABundleVolumeName,
+				ReadOnly:  true,
+				MountPath: CABundleMountPath,
+			})
+			container.Env = append(container.Env, corev1.EnvVar{Name: "SSL_CERT_DIR", Value: caCertDirs})
+		}
+	}
+
+	if ref := cfg.Spec.ClientCertificateSecretRef; ref != nil && ref.Name != "" {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: ClientCertificateVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: ref.Name,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+						{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+					},
+					DefaultMode: ptr.To(int32(0o440)),
+				},
+			},
+		})
+		for i := range podSpec.Containers {
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      ClientCertificateVolumeName,
+				ReadOnly:  true,
+				MountPath: ClientCertificateMountPath,
+			})
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+)
+
+func TestAddMondooAPITLSToSpec(t *testing.T) {
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "cnspec"}, {Name: "sidecar"}}}
+	cfg := v1alpha2.MondooOperatorConfig{
+		Spec: v1alpha2.MondooOperatorConfigSpec{
+			CABundleSecretRef:          &corev1.LocalObjectReference{Name: "mondoo-ca"},
+			ClientCertificateSecretRef: &corev1.LocalObjectReference{Name: "mondoo-client-cert"},
+		},
+	}
+
+	AddMondooAPITLSToSpec(&podSpec, cfg)
+
+	require.Len(t, podSpec.Volumes, 2)
+	assert.Equal(t, CABundleVolumeName, podSpec.Volumes[0].Name)
+	assert.Equal(t, "mondoo-ca", podSpec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, ClientCertificateVolumeName, podSpec.Volumes[1].Name)
+	assert.Equal(t, "mondoo-client-cert", podSpec.Volumes[1].Secret.SecretName)
+	assert.Len(t, podSpec.Volumes[1].Secret.Items, 2)
+
+	for _, c := range podSpec.Containers {
+		assert.ElementsMatch(t, []corev1.VolumeMount{
+			{Name: CABundleVolumeName, ReadOnly: true, MountPath: CABundleMountPath},
+			{Name: ClientCertificateVolumeName, ReadOnly: true, MountPath: ClientCertificateMountPath},
+		}, c.VolumeMounts, c.Name)
+		assert.Contains(t, c.Env, corev1.EnvVar{Name: "SSL_CERT_DIR", Value: "/etc/ssl/certs:/etc/pki/tls/certs:" + CABundleMountPath}, c.Name)
+	}
+}
+
+func TestAddMondooAPITLSToSpec_NotConfigured(t *testing.T) {
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{Name: "cnspec"}}}
+
+	AddMondooAPITLSToSpec(&podSpec, v1alpha2.MondooOperatorConfig{})
+
+	assert.Empty(t, podSpec.Volumes)
+	assert.Empty(t, podSpec.Containers[0].VolumeMounts)
+	assert.Empty(t, podSpec.Containers[0].Env)
+}
@@ -0,0 +1,404 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault-client-go"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+const (
+	// CredsSourceVolumeName is the volume name of the credentials of spec.mondooCredsSource
+	CredsSourceVolumeName = "mondoo-credentials"
+	// CredsSourceMountPath is where the credentials of spec.mondooCredsSource are mounted
+	CredsSourceMountPath = "/etc/opt/mondoo/credentials"
+	// VaultAuthVolumeName is the volume name of the service account token and the CA certificate for Vault
+	VaultAuthVolumeName = "mondoo-vault-auth"
+	// VaultAuthMountPath is where the service account token and the CA certificate for Vault are mounted
+	VaultAuthMountPath = "/etc/opt/mondoo/vault-auth"
+	// VaultCredsInitContainerName is the name of the init container that reads the credentials from Vault
+	VaultCredsInitContainerName = "fetch-mondoo-credentials"
+	// VaultCredsFileName is the file name of the service account read from Vault
+	VaultCredsFileName = "mondoo.yml"
+
+	// credsSourceCacheTTL is how long credentials read from Vault are reused, so the handlers of a
+	// reconciliation don't all log in to Vault
+	credsSourceCacheTTL = time.Minute
+)
+
+// CSICredentialsDir is where the SecretProviderClasses of spec.mondooCredsSource.csi are mounted into the
+// operator pod, each in a directory named after the SecretProviderClass.
+var CSICredentialsDir = "/etc/opt/mondoo/csi-credentials"
+
+// ServiceAccountTokenPath is the token of the service account the operator logs in to Vault with.
+var ServiceAccountTokenPath = DefaultServiceAccountTokenPath
+
+var credsSourceCache = struct {
+	sync.Mutex
+	entries map[string]credsSourceCacheEntry
+}{entries: map[string]credsSourceCacheEntry{}}
+
+type credsSourceCacheEntry struct {
+	secret  *corev1.Secret
+	expires time.Time
+}
+
+// UsesCredsSource reports whether the given scan reads its credentials from spec.mondooCredsSource. Scans that
+// override the credentials with their own Secret don't.
+func UsesCredsSource(m v1alpha2.MondooAuditConfig, scan ScanType) bool {
+	if m.Spec.MondooCredsSource == nil {
+		return false
+	}
+	_, credsRef := ScanCredentials(m, scan)
+	return credsRef.Name == ""
+}
+
+// ValidateMondooCredsSource checks that spec.mondooCredsSource is not combined with settings that need the
+// credentials in a Secret. The space of a scan is set in a copy of the credentials Secret, so spaceId and
+// spaceRouting can't be used with it.
+func ValidateMondooCredsSource(m v1alpha2.MondooAuditConfig) error {
+	source := m.Spec.MondooCredsSource
+	if source == nil {
+		return nil
+	}
+	if m.Spec.MondooCredsSecretRef.Name != "" {
+		return fmt.Errorf("mondooCredsSecretRef and mondooCredsSource are mutually exclusive")
+	}
+	if (source.Vault == nil) == (source.CSI == nil) {
+		return fmt.Errorf("exactly one of mondooCredsSource.vault and mondooCredsSource.csi must be set")
+	}
+	if m.Spec.SpaceID != "" {
+		return fmt.Errorf("spaceId can't be used with mondooCredsSource")
+	}
+	if len(m.Spec.SpaceRouting) > 0 {
+		return fmt.Errorf("spaceRouting can't be used with mondooCredsSource")
+	}
+	for _, scan := range ScanTypes(m) {
+		if spaceID, _ := ScanCredentials(m, scan); spaceID != "" && UsesCredsSource(m, scan) {
+			return fmt.Errorf("the spaceId of the %s scans needs a mondooCredsSecretRef, it can't be used with mondooCredsSource", scan)
+		}
+	}
+	return nil
+}
+
+// MondooCredentialsSecret returns the Secret with the Mondoo credentials of the MondooAuditConfig. If
+// spec.mondooCredsSource is set, the credentials are read from the secret store into a Secret that is never
+// stored in the cluster.
+func MondooCredentialsSecret(ctx context.Context, kubeClient client.Client, m v1alpha2.MondooAuditConfig) (*corev1.Secret, error) {
+	if m.Spec.MondooCredsSource != nil {
+		return loadCredsSource(ctx, kubeClient, m)
+	}
+
+	secret := &corev1.Secret{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: m.Spec.MondooCredsSecretRef.Name}, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// ScanCredentialsSecret returns the Secret with the Mondoo credentials the given scan uses, see
+// MondooCredentialsSecret.
+func ScanCredentialsSecret(ctx context.Context, kubeClient client.Client, m v1alpha2.MondooAuditConfig, scan ScanType) (*corev1.Secret, error) {
+	if UsesCredsSource(m, scan) {
+		return loadCredsSource(ctx, kubeClient, m)
+	}
+
+	_, credsRef := ScanCredentials(m, scan)
+	secret := &corev1.Secret{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: credsRef.Name}, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func loadCredsSource(ctx context.Context, kubeClient client.Client, m v1alpha2.MondooAuditConfig) (*corev1.Secret, error) {
+	source := m.Spec.MondooCredsSource
+	switch {
+	case source.Vault != nil:
+		return loadVaultCredentials(ctx, kubeClient, m.Namespace, *source.Vault)
+	case source.CSI != nil:
+		return loadCSICredentials(m.Namespace, *source.CSI)
+	default:
+		return nil, fmt.Errorf("mondooCredsSource has neither vault nor csi set")
+	}
+}
+
+func loadVaultCredentials(ctx context.Context, kubeClient client.Client, namespace string, source v1alpha2.VaultCredsSource) (*corev1.Secret, error) {
+	name := fmt.Sprintf("vault:%s/%s", vaultKVMount(source), source.Path)
+	cacheKey := fmt.Sprintf("%s/%s/%s/%s#%s", namespace, source.VaultAddr, source.AuthRole, name, vaultCredsKey(source))
+
+	credsSourceCache.Lock()
+	defer credsSourceCache.Unlock()
+	if entry, ok := credsSourceCache.entries[cacheKey]; ok && time.Now().Before(entry.expires) {
+		return entry.secret.DeepCopy(), nil
+	}
+
+	saToken, err := os.ReadFile(ServiceAccountTokenPath) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	var caCert []byte
+	if ref := source.CACertSecretRef; ref != nil {
+		caSecret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, caSecret); err != nil {
+			return nil, fmt.Errorf("failed to get Vault CA cert secret: %w", err)
+		}
+		if caCert = caSecret.Data[corev1.ServiceAccountRootCAKey]; len(caCert) == 0 {
+			return nil, fmt.Errorf("vault CA cert secret %q is missing the \"ca.crt\" key", ref.Name)
+		}
+	}
+
+	data, version, err := ReadVaultCredentials(ctx, source, string(saToken), caCert)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{Data: data}
+	secret.Name, secret.Namespace = name, namespace
+	// The UID and resourceVersion identify the credentials for the cached token sources
+	secret.UID, secret.ResourceVersion = types.UID(source.VaultAddr+"/"+name), version
+
+	credsSourceCache.entries[cacheKey] = credsSourceCacheEntry{secret: secret, expires: time.Now().Add(credsSourceCacheTTL)}
+	return secret.DeepCopy(), nil
+}
+
+// ReadVaultCredentials logs in to Vault with the service account token and reads the Mondoo service account
+// from the KV secret of the source. It returns the data of a credentials Secret and the version of the KV
+// secret. The service account may be stored as a JSON string or as an object.
+func ReadVaultCredentials(ctx context.Context, source v1alpha2.VaultCredsSource, saToken string, vaultCACert []byte) (map[string][]byte, string, error) {
+	vaultClient, err := NewVaultClient(ctx, source.VaultAddr, source.AuthPath, source.AuthRole, saToken, vaultCACert)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := vaultClient.Secrets.KvV2Read(ctx, source.Path, vault.WithMountPath(vaultKVMount(source)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read Vault secret %s/%s: %w", vaultKVMount(source), source.Path, err)
+	}
+	if resp == nil || resp.Data.Data == nil {
+		return nil, "", fmt.Errorf("vault secret %s/%s is empty", vaultKVMount(source), source.Path)
+	}
+
+	var sa []byte
+	switch value := resp.Data.Data[vaultCredsKey(source)].(type) {
+	case string:
+		sa = []byte(value)
+	case map[string]any:
+		if sa, err = json.Marshal(value); err != nil {
+			return nil, "", fmt.Errorf("failed to marshal the service account: %w", err)
+		}
+	}
+	if len(sa) == 0 {
+		return nil, "", fmt.Errorf("vault secret %s/%s is missing the %q key", vaultKVMount(source), source.Path, vaultCredsKey(source))
+	}
+
+	data := map[string][]byte{constants.MondooCredsSecretServiceAccountKey: sa}
+	if mrn, ok := resp.Data.Data[constants.MondooCredsSecretIntegrationMRNKey].(string); ok && mrn != "" {
+		data[constants.MondooCredsSecretIntegrationMRNKey] = []byte(mrn)
+	}
+	version := ""
+	if v, ok := resp.Data.Metadata["version"]; ok {
+		version = fmt.Sprint(v)
+	}
+	return data, version, nil
+}
+
+func loadCSICredentials(namespace string, source v1alpha2.CSICredsSource) (*corev1.Secret, error) {
+	dir := filepath.Join(CSICredentialsDir, source.SecretProviderClass)
+	sa, err := os.ReadFile(filepath.Join(dir, csiObjectName(source))) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the credentials of SecretProviderClass %s, it has to be mounted into the operator pod at %s: %w",
+			source.SecretProviderClass, dir, err)
+	}
+
+	data := map[string][]byte{constants.MondooCredsSecretServiceAccountKey: sa}
+	if mrn, err := os.ReadFile(filepath.Join(dir, constants.MondooCredsSecretIntegrationMRNKey)); err == nil { //nolint:gosec
+		data[constants.MondooCredsSecretIntegrationMRNKey] = bytes.TrimSpace(mrn)
+	}
+	sum := sha256.Sum256(sa)
+	secret := &corev1.Secret{Data: data}
+	secret.Name, secret.Namespace = "csi:"+source.SecretProviderClass, namespace
+	// The UID and resourceVersion identify the credentials for the cached token sources
+	secret.UID, secret.ResourceVersion = types.UID(dir), hex.EncodeToString(sum[:8])
+	return secret, nil
+}
+
+// AddMondooCredsSourceToSpec replaces the credentials Secret of the given scan in a pod spec with
+// spec.mondooCredsSource. The CSI volume, or for Vault an in-memory volume that an init container running
+// initImage fills, is mounted into all containers, and their --config flags point to the mounted service
+// account. It does nothing if the scan doesn't use mondooCredsSource.
+func AddMondooCredsSourceToSpec(podSpec *corev1.PodSpec, m v1alpha2.MondooAuditConfig, scan ScanType, initImage string) {
+	if !UsesCredsSource(m, scan) {
+		return
+	}
+	source := m.Spec.MondooCredsSource
+
+	// Without mondooCredsSecretRef the projections of the credentials Secret have no name
+	var removed []string
+	podSpec.Volumes = slices.DeleteFunc(podSpec.Volumes, func(volume corev1.Volume) bool {
+		if volume.Projected == nil {
+			return false
+		}
+		volume.Projected.Sources = slices.DeleteFunc(volume.Projected.Sources, func(projection corev1.VolumeProjection) bool {
+			return projection.Secret != nil && projection.Secret.Name == ""
+		})
+		if len(volume.Projected.Sources) > 0 {
+			return false
+		}
+		removed = append(removed, volume.Name)
+		return true
+	})
+
+	volume := corev1.Volume{Name: CredsSourceVolumeName}
+	configPath := filepath.Join(CredsSourceMountPath, VaultCredsFileName)
+	if source.CSI != nil {
+		configPath = filepath.Join(CredsSourceMountPath, csiObjectName(*source.CSI))
+		volume.CSI = &corev1.CSIVolumeSource{
+			Driver:           csiDriver(*source.CSI),
+			ReadOnly:         ptr.To(true),
+			VolumeAttributes: map[string]string{"secretProviderClass": source.CSI.SecretProviderClass},
+		}
+	} else {
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}
+		podSpec.Volumes = append(podSpec.Volumes, vaultAuthVolume(*source.Vault))
+		podSpec.InitContainers = append(podSpec.InitContainers, vaultCredsInitContainer(*source.Vault, initImage))
+	}
+	podSpec.Volumes = append(podSpec.Volumes, volume)
+
+	setConfigFlag := func(args []string) {
+		for i := 0; i+1 < len(args); i++ {
+			if args[i] == "--config" {
+				args[i+1] = configPath
+			}
+		}
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		container.VolumeMounts = slices.DeleteFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool {
+			return slices.Contains(removed, mount.Name)
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      CredsSourceVolumeName,
+			ReadOnly:  true,
+			MountPath: CredsSourceMountPath,
+		})
+		setConfigFlag(container.Command)
+		setConfigFlag(container.Args)
+	}
+}
+
+// vaultAuthVolume projects the token of the pod's service account and Vault's CA certificate.
+func vaultAuthVolume(source v1alpha2.VaultCredsSource) corev1.Volume {
+	sources := []corev1.VolumeProjection{{
+		ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token", ExpirationSeconds: ptr.To(int64(600))},
+	}}
+	if ref := source.CACertSecretRef; ref != nil {
+		sources = append(sources, corev1.VolumeProjection{Secret: &corev1.SecretProjection{
+			LocalObjectReference: *ref,
+			Items:                []corev1.KeyToPath{{Key: corev1.ServiceAccountRootCAKey, Path: corev1.ServiceAccountRootCAKey}},
+		}})
+	}
+	return corev1.Volume{
+		Name: VaultAuthVolumeName,
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+			DefaultMode: ptr.To(int32(0o440)),
+			Sources:     sources,
+		}},
+	}
+}
+
+func vaultCredsInitContainer(source v1alpha2.VaultCredsSource, image string) corev1.Container {
+	cmd := []string{
+		"/mondoo-operator", "vault-credentials",
+		"--vault-addr", source.VaultAddr,
+		"--auth-path", source.AuthPath,
+		"--auth-role", source.AuthRole,
+		"--kv-mount", vaultKVMount(source),
+		"--path", source.Path,
+		"--key", vaultCredsKey(source),
+		"--token-file", filepath.Join(VaultAuthMountPath, "token"),
+		"--output", filepath.Join(CredsSourceMountPath, VaultCredsFileName),
+	}
+	if source.CACertSecretRef != nil {
+		cmd = append(cmd, "--ca-cert", filepath.Join(VaultAuthMountPath, corev1.ServiceAccountRootCAKey))
+	}
+
+	return corev1.Container{
+		Name:            VaultCredsInitContainerName,
+		Image:           image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         cmd,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: CredsSourceVolumeName, MountPath: CredsSourceMountPath},
+			{Name: VaultAuthVolumeName, ReadOnly: true, MountPath: VaultAuthMountPath},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("200m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			ReadOnlyRootFilesystem:   ptr.To(true),
+			RunAsNonRoot:             ptr.To(true),
+			RunAsUser:                ptr.To(int64(101)),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+	}
+}
+
+func vaultKVMount(source v1alpha2.VaultCredsSource) string {
+	if source.KVMount == "" {
+		return "secret"
+	}
+	return source.KVMount
+}
+
+func vaultCredsKey(source v1alpha2.VaultCredsSource) string {
+	if source.Key == "" {
+		return constants.MondooCredsSecretServiceAccountKey
+	}
+	return source.Key
+}
+
+func csiDriver(source v1alpha2.CSICredsSource) string {
+	if source.Driver == "" {
+		return "secrets-store.csi.k8s.io"
+	}
+	return source.Driver
+}
+
+func csiObjectName(source v1alpha2.CSICredsSource) string {
+	if source.ObjectName == "" {
+		return constants.MondooCredsSecretServiceAccountKey
+	}
+	return source.ObjectName
+}
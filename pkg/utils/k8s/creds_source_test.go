@@ -0,0 +1,283 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func TestValidateMondooCredsSource(t *testing.T) {
+	csi := &v1alpha2.MondooCredsSource{CSI: &v1alpha2.CSICredsSource{SecretProviderClass: "mondoo"}}
+	tests := []struct {
+		name    string
+		spec    v1alpha2.MondooAuditConfigSpec
+		wantErr string
+	}{
+		{name: "no source", spec: v1alpha2.MondooAuditConfigSpec{SpaceID: "abc"}},
+		{name: "csi", spec: v1alpha2.MondooAuditConfigSpec{MondooCredsSource: csi}},
+		{
+			name: "with secret ref",
+			spec: v1alpha2.MondooAuditConfigSpec{
+				MondooCredsSecretRef: corev1.LocalObjectReference{Name: "mondoo-client"},
+				MondooCredsSource:    csi,
+			},
+			wantErr: "mutually exclusive",
+		},
+		{
+			name:    "no store",
+			spec:    v1alpha2.MondooAuditConfigSpec{MondooCredsSource: &v1alpha2.MondooCredsSource{}},
+			wantErr: "exactly one",
+		},
+		{
+			name: "both stores",
+			spec: v1alpha2.MondooAuditConfigSpec{MondooCredsSource: &v1alpha2.MondooCredsSource{
+				CSI:   csi.CSI,
+				Vault: &v1alpha2.VaultCredsSource{VaultAddr: "https://vault", AuthRole: "mondoo", Path: "mondoo"},
+			}},
+			wantErr: "exactly one",
+		},
+		{
+			name:    "space id",
+			spec:    v1alpha2.MondooAuditConfigSpec{MondooCredsSource: csi, SpaceID: "abc"},
+			wantErr: "spaceId",
+		},
+		{
+			name: "space routing",
+			spec: v1alpha2.MondooAuditConfigSpec{
+				MondooCredsSource: csi,
+				SpaceRouting:      []v1alpha2.SpaceRoutingRule{{SpaceID: "abc"}},
+			},
+			wantErr: "spaceRouting",
+		},
+		{
+			name: "scan space id",
+			spec: v1alpha2.MondooAuditConfigSpec{
+				MondooCredsSource: csi,
+				Nodes:             v1alpha2.Nodes{SpaceID: "abc"},
+			},
+			wantErr: "nodes",
+		},
+		{
+			name: "scan space id with own secret",
+			spec: v1alpha2.MondooAuditConfigSpec{
+				MondooCredsSource: csi,
+				Nodes: v1alpha2.Nodes{
+					SpaceID:              "abc",
+					MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "nodes-creds"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateMondooCredsSource(v1alpha2.MondooAuditConfig{Spec: test.spec})
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, test.wantErr)
+		})
+	}
+}
+
+func testCredsSourcePodSpec() corev1.PodSpec {
+	return corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name:    "cnspec",
+			Command: []string{"cnspec", "scan", "k8s", "--config", "/etc/opt/mondoo/mondoo.yml"},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "config", MountPath: "/etc/opt/"},
+				{Name: "temp", MountPath: "/tmp"},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			{
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{}}},
+				}},
+			},
+			{Name: "temp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		},
+	}
+}
+
+func TestAddMondooCredsSourceToSpec_CSI(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{
+		MondooCredsSource: &v1alpha2.MondooCredsSource{CSI: &v1alpha2.CSICredsSource{SecretProviderClass: "mondoo"}},
+	}}
+	podSpec := testCredsSourcePodSpec()
+
+	AddMondooCredsSourceToSpec(&podSpec, m, KubernetesResourcesScan, "")
+
+	require.Len(t, podSpec.Volumes, 2)
+	assert.Equal(t, "temp", podSpec.Volumes[0].Name)
+	volume := podSpec.Volumes[1]
+	assert.Equal(t, CredsSourceVolumeName, volume.Name)
+	require.NotNil(t, volume.CSI)
+	assert.Equal(t, "secrets-store.csi.k8s.io", volume.CSI.Driver)
+	assert.Equal(t, map[string]string{"secretProviderClass": "mondoo"}, volume.CSI.VolumeAttributes)
+	assert.Empty(t, podSpec.InitContainers)
+
+	container := podSpec.Containers[0]
+	assert.Equal(t, []string{"cnspec", "scan", "k8s", "--config", "/etc/opt/mondoo/credentials/config"}, container.Command)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "temp", MountPath: "/tmp"},
+		{Name: CredsSourceVolumeName, ReadOnly: true, MountPath: CredsSourceMountPath},
+	}, container.VolumeMounts)
+}
+
+func TestAddMondooCredsSourceToSpec_Vault(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{
+		MondooCredsSource: &v1alpha2.MondooCredsSource{Vault: &v1alpha2.VaultCredsSource{
+			VaultAddr:       "https://vault:8200",
+			AuthRole:        "mondoo",
+			Path:            "mondoo/sa",
+			CACertSecretRef: &corev1.LocalObjectReference{Name: "vault-ca"},
+		}},
+	}}
+	podSpec := testCredsSourcePodSpec()
+
+	AddMondooCredsSourceToSpec(&podSpec, m, NodesScan, "mondoo-operator:latest")
+
+	require.Len(t, podSpec.Volumes, 3)
+	assert.Equal(t, VaultAuthVolumeName, podSpec.Volumes[1].Name)
+	require.NotNil(t, podSpec.Volumes[1].Projected)
+	assert.Len(t, podSpec.Volumes[1].Projected.Sources, 2)
+	assert.Equal(t, CredsSourceVolumeName, podSpec.Volumes[2].Name)
+	require.NotNil(t, podSpec.Volumes[2].EmptyDir)
+	assert.Equal(t, corev1.StorageMediumMemory, podSpec.Volumes[2].EmptyDir.Medium)
+
+	require.Len(t, podSpec.InitContainers, 1)
+	init := podSpec.InitContainers[0]
+	assert.Equal(t, "mondoo-operator:latest", init.Image)
+	assert.Equal(t, []string{
+		"/mondoo-operator", "vault-credentials",
+		"--vault-addr", "https://vault:8200",
+		"--auth-path", "",
+		"--auth-role", "mondoo",
+		"--kv-mount", "secret",
+		"--path", "mondoo/sa",
+		"--key", "config",
+		"--token-file", "/etc/opt/mondoo/vault-auth/token",
+		"--output", "/etc/opt/mondoo/credentials/mondoo.yml",
+		"--ca-cert", "/etc/opt/mondoo/vault-auth/ca.crt",
+	}, init.Command)
+
+	assert.Equal(t, "/etc/opt/mondoo/credentials/mondoo.yml", podSpec.Containers[0].Command[4])
+}
+
+func TestAddMondooCredsSourceToSpec_ScanWithOwnSecret(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{
+		MondooCredsSource: &v1alpha2.MondooCredsSource{CSI: &v1alpha2.CSICredsSource{SecretProviderClass: "mondoo"}},
+		Nodes:             v1alpha2.Nodes{MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "nodes-creds"}},
+	}}
+	podSpec := testCredsSourcePodSpec()
+	expected := testCredsSourcePodSpec()
+
+	AddMondooCredsSourceToSpec(&podSpec, m, NodesScan, "")
+
+	assert.Equal(t, expected, podSpec)
+}
+
+func newFakeVault(t *testing.T, data map[string]any) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/kubernetes/login":
+			_, _ = w.Write([]byte(`{"data":null,"auth":{"client_token":"vault-token"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/mondoo/sa":
+			assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"data": data, "metadata": map[string]any{"version": 3}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadVaultCredentials(t *testing.T) {
+	server := newFakeVault(t, map[string]any{
+		"config":         map[string]any{"mrn": "//agents.api.mondoo.app/spaces/abc/serviceaccounts/sa"},
+		"integrationmrn": "//integrations/abc",
+	})
+	source := v1alpha2.VaultCredsSource{VaultAddr: server.URL, AuthRole: "mondoo", Path: "mondoo/sa"}
+
+	data, version, err := ReadVaultCredentials(context.Background(), source, "sa-token", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3", version)
+	assert.JSONEq(t, `{"mrn":"//agents.api.mondoo.app/spaces/abc/serviceaccounts/sa"}`,
+		string(data[constants.MondooCredsSecretServiceAccountKey]))
+	assert.Equal(t, "//integrations/abc", string(data[constants.MondooCredsSecretIntegrationMRNKey]))
+
+	source.Key = "missing"
+	_, _, err = ReadVaultCredentials(context.Background(), source, "sa-token", nil)
+	assert.ErrorContains(t, err, `missing the "missing" key`)
+}
+
+func TestMondooCredentialsSecret_Vault(t *testing.T) {
+	server := newFakeVault(t, map[string]any{"config": `{"mrn":"sa"}`})
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token"), 0o600))
+	defer func(path string) { ServiceAccountTokenPath = path }(ServiceAccountTokenPath)
+	ServiceAccountTokenPath = tokenFile
+
+	m := v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo", Namespace: "mondoo-operator"},
+		Spec: v1alpha2.MondooAuditConfigSpec{MondooCredsSource: &v1alpha2.MondooCredsSource{
+			Vault: &v1alpha2.VaultCredsSource{VaultAddr: server.URL, AuthRole: "mondoo", Path: "mondoo/sa"},
+		}},
+	}
+	secret, err := MondooCredentialsSecret(context.Background(), fake.NewClientBuilder().Build(), m)
+	require.NoError(t, err)
+	assert.Equal(t, "vault:secret/mondoo/sa", secret.Name)
+	assert.Equal(t, "mondoo-operator", secret.Namespace)
+	assert.Equal(t, "3", secret.ResourceVersion)
+	assert.Equal(t, `{"mrn":"sa"}`, string(secret.Data[constants.MondooCredsSecretServiceAccountKey]))
+}
+
+func TestMondooCredentialsSecret_CSI(t *testing.T) {
+	dir := t.TempDir()
+	defer func(dir string) { CSICredentialsDir = dir }(CSICredentialsDir)
+	CSICredentialsDir = dir
+
+	m := v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo", Namespace: "mondoo-operator"},
+		Spec: v1alpha2.MondooAuditConfigSpec{MondooCredsSource: &v1alpha2.MondooCredsSource{
+			CSI: &v1alpha2.CSICredsSource{SecretProviderClass: "mondoo", ObjectName: "sa.json"},
+		}},
+	}
+	kubeClient := fake.NewClientBuilder().Build()
+	_, err := MondooCredentialsSecret(context.Background(), kubeClient, m)
+	assert.ErrorContains(t, err, "has to be mounted into the operator pod")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "mondoo"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mondoo", "sa.json"), []byte(`{"mrn":"sa"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mondoo", "integrationmrn"), []byte("//integrations/abc\n"), 0o600))
+
+	secret, err := MondooCredentialsSecret(context.Background(), kubeClient, m)
+	require.NoError(t, err)
+	assert.Equal(t, "csi:mondoo", secret.Name)
+	assert.NotEmpty(t, secret.ResourceVersion)
+	assert.Equal(t, `{"mrn":"sa"}`, string(secret.Data[constants.MondooCredsSecretServiceAccountKey]))
+	assert.Equal(t, "//integrations/abc", string(secret.Data[constants.MondooCredsSecretIntegrationMRNKey]))
+}
@@ -4,28 +4,25 @@
 package k8s
 
 import (
-	"sort"
-
 	corev1 "k8s.io/api/core/v1"
 )
 
 // MergeEnv merges 2 slices of env vars. If the same key is present in
-// both slices, the value from the second slice will be used.
+// both slices, the value from the second slice will be used. The order of
+// the env vars is kept, so they can still reference the ones defined
+// before them with $(VAR_NAME).
 func MergeEnv(a, b []corev1.EnvVar) []corev1.EnvVar {
-	envSet := make(map[string]corev1.EnvVar)
-	for _, e := range a {
-		envSet[e.Name] = e
-	}
-	for _, e := range b {
-		envSet[e.Name] = e
-	}
-
-	mergedEnv := make([]corev1.EnvVar, 0, len(envSet))
-	for _, v := range envSet {
-		mergedEnv = append(mergedEnv, v)
+	mergedEnv := make([]corev1.EnvVar, 0, len(a)+len(b))
+	index := make(map[string]int, len(a)+len(b))
+	for _, list := range [][]corev1.EnvVar{a, b} {
+		for _, e := range list {
+			if i, ok := index[e.Name]; ok {
+				mergedEnv[i] = e
+				continue
+			}
+			index[e.Name] = len(mergedEnv)
+			mergedEnv = append(mergedEnv, e)
+		}
 	}
-	sort.Slice(mergedEnv, func(i, j int) bool {
-		return mergedEnv[i].Name < mergedEnv[j].Name
-	})
 	return mergedEnv
 }
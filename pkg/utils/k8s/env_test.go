@@ -90,3 +90,24 @@ func TestMergeEnv_BEmpty(t *testing.T) {
 
 	assert.ElementsMatch(t, expected, env)
 }
+
+func TestMergeEnv_KeepsOrder(t *testing.T) {
+	a := []corev1.EnvVar{
+		{Name: "USER", Value: "user"},
+		{Name: "PROXY", Value: "http://$(USER)@proxy"},
+	}
+
+	b := []corev1.EnvVar{
+		{Name: "B", Value: "b"},
+		{Name: "USER", Value: "other"},
+	}
+
+	env := MergeEnv(a, b)
+	expected := []corev1.EnvVar{
+		{Name: "USER", Value: "other"},
+		{Name: "PROXY", Value: "http://$(USER)@proxy"},
+		{Name: "B", Value: "b"},
+	}
+
+	assert.Equal(t, expected, env)
+}
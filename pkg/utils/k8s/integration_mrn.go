@@ -13,7 +13,6 @@ import (
 	"go.mondoo.com/mondoo-operator/pkg/constants"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,19 +39,10 @@ func TryGetIntegrationMrnForAuditConfig(ctx context.Context, kubeClient client.C
 	return string(mrn), nil
 }
 
-// GetIntegrationSecretForAuditConfig retrieves the MondooCredsSecretRef for the give MondooAuditConfig.
+// GetIntegrationSecretForAuditConfig retrieves the MondooCredsSecretRef for the give MondooAuditConfig. If
+// MondooCredsSource is set, the credentials are read from the secret store, see MondooCredentialsSecret.
 func GetIntegrationSecretForAuditConfig(ctx context.Context, kubeClient client.Client, auditConfig v1alpha2.MondooAuditConfig) (*corev1.Secret, error) {
-	serviceAccountSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      auditConfig.Spec.MondooCredsSecretRef.Name,
-			Namespace: auditConfig.Namespace,
-		},
-	}
-	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(serviceAccountSecret), serviceAccountSecret); err != nil {
-		return nil, err
-	}
-
-	return serviceAccountSecret, nil
+	return MondooCredentialsSecret(ctx, kubeClient, auditConfig)
 }
 
 func GetIntegrationMrnFromSecret(secret corev1.Secret) (string, error) {
@@ -0,0 +1,129 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+)
+
+// ErrNoMatchingNamespaces is returned when the Include patterns or the namespace selector of a filtering
+// match no namespace. Passing an empty Include list to cnspec would scan all namespaces instead.
+var ErrNoMatchingNamespaces = errors.New("no namespaces match the namespace filtering")
+
+// NamespaceFilteringNeedsResolution returns true if the filtering uses glob patterns or a namespace selector,
+// which have to be resolved to namespace names before they are passed to cnspec or the resource watcher.
+func NamespaceFilteringNeedsResolution(spec v1alpha2.FilteringSpec) bool {
+	if spec.NamespaceSelector != nil {
+		return true
+	}
+	return slices.ContainsFunc(spec.Include, isNamespacePattern) || slices.ContainsFunc(spec.Exclude, isNamespacePattern)
+}
+
+// NamespaceMatchesFiltering returns true if a namespace with the given name and labels is selected by the filtering.
+func NamespaceMatchesFiltering(spec v1alpha2.FilteringSpec, name string, namespaceLabels map[string]string) (bool, error) {
+	if spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+		if !selector.Matches(labels.Set(namespaceLabels)) {
+			return false, nil
+		}
+	}
+	if len(spec.Include) > 0 {
+		return matchesNamespacePattern(spec.Include, name), nil
+	}
+	return !matchesNamespacePattern(spec.Exclude, name), nil
+}
+
+// ResolveNamespaceFiltering resolves glob patterns and the namespace selector of the filtering to the names
+// of the matching namespaces in the cluster, and excludes the namespaces that opted out of scanning. When
+// Include or the selector select namespaces, the result only has an Include list. Otherwise the result
+// excludes the matching namespaces, so namespaces created later are still scanned.
+func ResolveNamespaceFiltering(ctx context.Context, kubeClient client.Reader, filtering v1alpha2.Filtering) (v1alpha2.Filtering, error) {
+	spec := filtering.Namespaces
+
+	namespaces := &corev1.NamespaceList{}
+	if err := kubeClient.List(ctx, namespaces); err != nil {
+		return v1alpha2.Filtering{}, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	var optedOut []string
+	for _, ns := range namespaces.Items {
+		if ScanOptedOut(ns.Annotations) {
+			optedOut = append(optedOut, ns.Name)
+		}
+	}
+	if !NamespaceFilteringNeedsResolution(spec) && len(optedOut) == 0 {
+		return filtering, nil
+	}
+
+	resolved := filtering.DeepCopy()
+	resolved.Namespaces.NamespaceSelector = nil
+	if len(spec.Include) > 0 || spec.NamespaceSelector != nil {
+		var names []string
+		for _, ns := range namespaces.Items {
+			matches, err := NamespaceMatchesFiltering(spec, ns.Name, ns.Labels)
+			if err != nil {
+				return v1alpha2.Filtering{}, err
+			}
+			if matches && !slices.Contains(optedOut, ns.Name) {
+				names = append(names, ns.Name)
+			}
+		}
+		// Keep the included names, they still apply once such a namespace is created
+		for _, i := range spec.Include {
+			if !isNamespacePattern(i) && spec.NamespaceSelector == nil && !slices.Contains(optedOut, i) {
+				names = append(names, i)
+			}
+		}
+		if len(names) == 0 {
+			return v1alpha2.Filtering{}, ErrNoMatchingNamespaces
+		}
+		slices.Sort(names)
+		resolved.Namespaces.Include = slices.Compact(names)
+		resolved.Namespaces.Exclude = nil
+	} else {
+		names := optedOut
+		for _, ns := range namespaces.Items {
+			if matchesNamespacePattern(spec.Exclude, ns.Name) {
+				names = append(names, ns.Name)
+			}
+		}
+		// Keep the excluded names, they still apply once such a namespace is created
+		for _, e := range spec.Exclude {
+			if !isNamespacePattern(e) {
+				names = append(names, e)
+			}
+		}
+		slices.Sort(names)
+		resolved.Namespaces.Exclude = slices.Compact(names)
+	}
+	return *resolved, nil
+}
+
+func isNamespacePattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+func matchesNamespacePattern(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok || p == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func testNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestResolveNamespaceFiltering(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		testNamespace("kube-system", nil),
+		testNamespace("team-a-prod", map[string]string{"tier": "critical"}),
+		testNamespace("team-b-prod", nil),
+		testNamespace("team-b-dev", nil),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "team-c-prod", Annotations: map[string]string{constants.MondooScanAnnotation: "false"},
+		}},
+	).Build()
+
+	tests := []struct {
+		name      string
+		filtering v1alpha2.FilteringSpec
+		expected  v1alpha2.FilteringSpec
+		err       error
+	}{
+		{
+			name:      "names are kept",
+			filtering: v1alpha2.FilteringSpec{Include: []string{"other", "app"}},
+			expected:  v1alpha2.FilteringSpec{Include: []string{"app", "other"}},
+		},
+		{
+			name:      "include patterns",
+			filtering: v1alpha2.FilteringSpec{Include: []string{"team-*-prod", "kube-system"}, Exclude: []string{"team-a-*"}},
+			expected:  v1alpha2.FilteringSpec{Include: []string{"kube-system", "team-a-prod", "team-b-prod"}},
+		},
+		{
+			name:      "exclude patterns keep names",
+			filtering: v1alpha2.FilteringSpec{Exclude: []string{"*-dev", "not-created-yet"}},
+			expected:  v1alpha2.FilteringSpec{Exclude: []string{"not-created-yet", "team-b-dev", "team-c-prod"}},
+		},
+		{
+			name: "namespace selector and exclude",
+			filtering: v1alpha2.FilteringSpec{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist}},
+				},
+				Exclude: []string{"kube-*"},
+			},
+			expected: v1alpha2.FilteringSpec{Include: []string{"team-b-dev", "team-b-prod"}},
+		},
+		{
+			name: "namespace selector and include",
+			filtering: v1alpha2.FilteringSpec{
+				Include:           []string{"team-*"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}},
+			},
+			expected: v1alpha2.FilteringSpec{Include: []string{"team-a-prod"}},
+		},
+		{
+			name:      "opted out namespaces are excluded",
+			filtering: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}},
+			expected:  v1alpha2.FilteringSpec{Exclude: []string{"kube-system", "team-c-prod"}},
+		},
+		{
+			name:      "opted out namespaces are not included",
+			filtering: v1alpha2.FilteringSpec{Include: []string{"team-c-prod", "team-a-prod", "not-created-yet"}},
+			expected:  v1alpha2.FilteringSpec{Include: []string{"not-created-yet", "team-a-prod"}},
+		},
+		{
+			name:      "no matches",
+			filtering: v1alpha2.FilteringSpec{Include: []string{"team-c-*"}},
+			err:       ErrNoMatchingNamespaces,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := ResolveNamespaceFiltering(context.Background(), kubeClient, v1alpha2.Filtering{Namespaces: test.filtering})
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, resolved.Namespaces)
+		})
+	}
+}
+
+func TestNamespaceMatchesFiltering_InvalidSelector(t *testing.T) {
+	spec := v1alpha2.FilteringSpec{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}},
+		},
+	}
+	_, err := NamespaceMatchesFiltering(spec, "default", nil)
+	assert.Error(t, err)
+}
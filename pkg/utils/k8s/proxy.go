@@ -4,27 +4,127 @@
 package k8s
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 )
 
+const (
+	// ProxyUsernameEnvVar holds the username of proxyCredentialsSecretRef in the containers
+	ProxyUsernameEnvVar = "MONDOO_PROXY_USERNAME"
+	// ProxyPasswordEnvVar holds the password of proxyCredentialsSecretRef in the containers
+	ProxyPasswordEnvVar = "MONDOO_PROXY_PASSWORD"
+	// APIProxyEnvVar holds the proxy for the Mondoo API in the containers if it is only known at runtime
+	APIProxyEnvVar = "MONDOO_API_PROXY"
+)
+
+// proxySource is a proxy of the MondooOperatorConfig. The URL is either set in the spec or selected
+// in a Secret.
+type proxySource struct {
+	field     string
+	url       *string
+	secretRef *corev1.SecretKeySelector
+}
+
+func httpProxySource(cfg v1alpha2.MondooOperatorConfig) proxySource {
+	return proxySource{field: "httpProxy", url: cfg.Spec.HttpProxy, secretRef: cfg.Spec.HttpProxySecretRef}
+}
+
+func httpsProxySource(cfg v1alpha2.MondooOperatorConfig) proxySource {
+	return proxySource{field: "httpsProxy", url: cfg.Spec.HttpsProxy, secretRef: cfg.Spec.HttpsProxySecretRef}
+}
+
+func containerProxySource(cfg v1alpha2.MondooOperatorConfig) proxySource {
+	return proxySource{field: "containerProxy", url: cfg.Spec.ContainerProxy, secretRef: cfg.Spec.ContainerProxySecretRef}
+}
+
+// apiProxySource returns the proxy to use for Mondoo API calls. Since the Mondoo API is always HTTPS,
+// it prefers the HTTPS proxy over the HTTP proxy.
+func apiProxySource(cfg v1alpha2.MondooOperatorConfig) proxySource {
+	if https := httpsProxySource(cfg); https.isSet() {
+		return https
+	}
+	return httpProxySource(cfg)
+}
+
+func (p proxySource) isSet() bool {
+	return p.url != nil || p.secretRef != nil
+}
+
+// runtimeOnly reports whether the URL of the proxy is only known at runtime, because it is read from
+// a Secret or gets the proxy credentials.
+func (p proxySource) runtimeOnly(cfg v1alpha2.MondooOperatorConfig) bool {
+	return p.secretRef != nil || (p.url != nil && hasProxyCredentials(cfg))
+}
+
+// envVar returns the env var with the URL of the proxy. Secrets are referenced and the proxy
+// credentials are expanded from the env vars of ProxyCredentialsEnvVars.
+func (p proxySource) envVar(name string, cfg v1alpha2.MondooOperatorConfig) corev1.EnvVar {
+	if p.secretRef != nil {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: p.secretRef}}
+	}
+	if hasProxyCredentials(cfg) {
+		return corev1.EnvVar{Name: name, Value: withProxyCredentials(*p.url, fmt.Sprintf("$(%s):$(%s)", ProxyUsernameEnvVar, ProxyPasswordEnvVar))}
+	}
+	return corev1.EnvVar{Name: name, Value: *p.url}
+}
+
+func hasProxyCredentials(cfg v1alpha2.MondooOperatorConfig) bool {
+	return cfg.Spec.ProxyCredentialsSecretRef != nil && cfg.Spec.ProxyCredentialsSecretRef.Name != ""
+}
+
+// withProxyCredentials adds the user info to the proxy URL. URLs without a scheme get http://.
+func withProxyCredentials(proxyURL, userInfo string) string {
+	scheme, host, ok := strings.Cut(proxyURL, "://")
+	if !ok {
+		scheme, host = "http", proxyURL
+	}
+	// Credentials in the URL itself are replaced
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return fmt.Sprintf("%s://%s@%s", scheme, userInfo, host)
+}
+
+// ProxyCredentialsEnvVars returns the env vars with the proxy credentials of proxyCredentialsSecretRef.
+// They have to be defined before the env vars that reference them.
+func ProxyCredentialsEnvVars(cfg v1alpha2.MondooOperatorConfig) []corev1.EnvVar {
+	if !hasProxyCredentials(cfg) {
+		return nil
+	}
+	ref := *cfg.Spec.ProxyCredentialsSecretRef
+	return []corev1.EnvVar{
+		{Name: ProxyUsernameEnvVar, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref, Key: corev1.BasicAuthUsernameKey}}},
+		{Name: ProxyPasswordEnvVar, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref, Key: corev1.BasicAuthPasswordKey}}},
+	}
+}
+
 // ProxyEnvVars returns environment variables for HTTP/HTTPS proxy configuration.
 // It sets both uppercase and lowercase variants for compatibility with different tools.
+// Proxies read from Secrets or with credentials are only assembled by the kubelet, so
+// they don't show up in the pod spec.
 func ProxyEnvVars(cfg v1alpha2.MondooOperatorConfig) []corev1.EnvVar {
 	var envVars []corev1.EnvVar
 
-	if cfg.Spec.HttpProxy != nil {
-		envVars = append(envVars,
-			corev1.EnvVar{Name: "HTTP_PROXY", Value: *cfg.Spec.HttpProxy},
-			corev1.EnvVar{Name: "http_proxy", Value: *cfg.Spec.HttpProxy},
-		)
+	http, https, api := httpProxySource(cfg), httpsProxySource(cfg), apiProxySource(cfg)
+	if hasProxyCredentials(cfg) && (http.url != nil || https.url != nil) {
+		envVars = append(envVars, ProxyCredentialsEnvVars(cfg)...)
 	}
-	if cfg.Spec.HttpsProxy != nil {
-		envVars = append(envVars,
-			corev1.EnvVar{Name: "HTTPS_PROXY", Value: *cfg.Spec.HttpsProxy},
-			corev1.EnvVar{Name: "https_proxy", Value: *cfg.Spec.HttpsProxy},
-		)
+	if http.isSet() {
+		envVars = append(envVars, http.envVar("HTTP_PROXY", cfg), http.envVar("http_proxy", cfg))
+	}
+	if https.isSet() {
+		envVars = append(envVars, https.envVar("HTTPS_PROXY", cfg), https.envVar("https_proxy", cfg))
+	}
+	if api.isSet() && api.runtimeOnly(cfg) {
+		envVars = append(envVars, api.envVar(APIProxyEnvVar, cfg))
 	}
 	if cfg.Spec.NoProxy != nil {
 		envVars = append(envVars,
@@ -38,9 +138,117 @@ func ProxyEnvVars(cfg v1alpha2.MondooOperatorConfig) []corev1.EnvVar {
 
 // APIProxyURL returns the proxy URL to use for Mondoo API calls.
 // Since the Mondoo API is always HTTPS, it prefers HttpsProxy over HttpProxy.
+// If the URL is only known at runtime, it references the env var of ProxyEnvVars,
+// which the kubelet expands in the command of the container.
 func APIProxyURL(cfg v1alpha2.MondooOperatorConfig) *string {
-	if cfg.Spec.HttpsProxy != nil {
-		return cfg.Spec.HttpsProxy
+	api := apiProxySource(cfg)
+	if !api.isSet() {
+		return nil
+	}
+	if api.runtimeOnly(cfg) {
+		return ptr.To(fmt.Sprintf("$(%s)", APIProxyEnvVar))
+	}
+	return api.url
+}
+
+// ContainerProxyURL returns the proxy for container images that is set in inventories. Inventories are
+// stored in ConfigMaps, so it is nil if the URL is only known at runtime. ContainerProxyEnvVars provides it
+// to the container image scans instead.
+func ContainerProxyURL(cfg v1alpha2.MondooOperatorConfig) *string {
+	if container := containerProxySource(cfg); container.url != nil && !container.runtimeOnly(cfg) {
+		return container.url
+	}
+	return nil
+}
+
+// ContainerProxyEnvVars returns the env vars of container image scans for a proxy for container images
+// that is only known at runtime. It replaces the HTTP/HTTPS proxy of ProxyEnvVars, Mondoo API calls
+// still use the proxy of APIProxyURL. The credentials are referenced from the env vars of
+// ProxyCredentialsEnvVars.
+func ContainerProxyEnvVars(cfg v1alpha2.MondooOperatorConfig) []corev1.EnvVar {
+	container := containerProxySource(cfg)
+	if !container.isSet() || !container.runtimeOnly(cfg) {
+		return nil
+	}
+	var envVars []corev1.EnvVar
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy"} {
+		envVars = append(envVars, container.envVar(name, cfg))
+	}
+	return envVars
+}
+
+// ProxyURLs are the proxies of a MondooOperatorConfig with their Secrets resolved. They may contain
+// credentials, so they must only be used by the operator's own clients and never be logged.
+type ProxyURLs struct {
+	HttpProxy      *string
+	HttpsProxy     *string
+	NoProxy        *string
+	ContainerProxy *string
+}
+
+// ResolveProxyURLs reads the proxies of the MondooOperatorConfig and their credentials from the Secrets
+// in the given namespace.
+func ResolveProxyURLs(ctx context.Context, kubeClient client.Client, namespace string, cfg *v1alpha2.MondooOperatorConfig) (ProxyURLs, error) {
+	if cfg == nil {
+		return ProxyURLs{}, nil
+	}
+
+	var userInfo string
+	if hasProxyCredentials(*cfg) {
+		ref := cfg.Spec.ProxyCredentialsSecretRef
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return ProxyURLs{}, fmt.Errorf("failed to get proxy credentials secret %s: %w", ref.Name, err)
+		}
+		username, password := secret.Data[corev1.BasicAuthUsernameKey], secret.Data[corev1.BasicAuthPasswordKey]
+		if len(username) == 0 || len(password) == 0 {
+			return ProxyURLs{}, fmt.Errorf("proxy credentials secret %s must have the keys %q and %q", ref.Name, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+		}
+		userInfo = fmt.Sprintf("%s:%s", username, password)
+	}
+
+	resolve := func(p proxySource) (*string, error) {
+		if p.url != nil && p.secretRef != nil {
+			return nil, fmt.Errorf("%s and %sSecretRef are mutually exclusive", p.field, p.field)
+		}
+		if p.url != nil {
+			if userInfo != "" {
+				return ptr.To(withProxyCredentials(*p.url, userInfo)), nil
+			}
+			return p.url, nil
+		}
+		if p.secretRef == nil {
+			return nil, nil
+		}
+		optional := ptr.Deref(p.secretRef.Optional, false)
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: p.secretRef.Name}, secret); err != nil {
+			if optional && apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get secret %s of %sSecretRef: %w", p.secretRef.Name, p.field, err)
+		}
+		value, ok := secret.Data[p.secretRef.Key]
+		if !ok || len(value) == 0 {
+			if optional {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("secret %s of %sSecretRef missing key %q", p.secretRef.Name, p.field, p.secretRef.Key)
+		}
+		return ptr.To(strings.TrimSpace(string(value))), nil
+	}
+
+	var urls ProxyURLs
+	var err error
+	if urls.HttpProxy, err = resolve(httpProxySource(*cfg)); err != nil {
+		return ProxyURLs{}, err
+	}
+	if urls.HttpsProxy, err = resolve(httpsProxySource(*cfg)); err != nil {
+		return ProxyURLs{}, err
+	}
+	if urls.ContainerProxy, err = resolve(containerProxySource(*cfg)); err != nil {
+		return ProxyURLs{}, err
 	}
-	return cfg.Spec.HttpProxy
+	urls.NoProxy = cfg.Spec.NoProxy
+	return urls, nil
 }
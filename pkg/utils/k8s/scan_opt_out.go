@@ -0,0 +1,125 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+// ScanOptedOut returns true if the annotations opt the Namespace or workload out of scanning.
+func ScanOptedOut(annotations map[string]string) bool {
+	return strings.EqualFold(annotations[constants.MondooScanAnnotation], "false")
+}
+
+// ScanOptOutIndexField is the field index of the namespaces and workloads that opted out of scanning, so they
+// can be counted without listing all objects of the cluster.
+const ScanOptOutIndexField = "mondoo.scanOptOut"
+
+// IndexScanOptOuts registers the ScanOptOutIndexField index of the namespaces and workloads counted by
+// CountScanOptOuts. Kinds that the operator doesn't cache yet are only cached as metadata.
+func IndexScanOptOuts(ctx context.Context, indexer client.FieldIndexer) error {
+	kinds := append([]scanOptOutKind{{obj: &corev1.Namespace{}}}, scanOptOutWorkloads()...)
+	for _, kind := range kinds {
+		if err := indexer.IndexField(ctx, kind.obj, ScanOptOutIndexField, kind.indexValue); err != nil {
+			return fmt.Errorf("failed to index %T: %w", kind.obj, err)
+		}
+	}
+	return nil
+}
+
+// CountScanOptOuts counts the namespaces and workloads that opted out of scanning. Workloads in namespaces
+// that opted out are not counted, as well as Pods and Jobs that are controlled by another workload. The
+// ScanOptOutIndexField index has to be registered with IndexScanOptOuts.
+func CountScanOptOuts(ctx context.Context, kubeClient client.Reader) (*v1alpha2.ScanOptOutStatus, error) {
+	status := &v1alpha2.ScanOptOutStatus{}
+	optedOut := client.MatchingFields{ScanOptOutIndexField: "true"}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := kubeClient.List(ctx, namespaces, optedOut); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	optedOutNamespaces := make(map[string]bool)
+	for _, ns := range namespaces.Items {
+		optedOutNamespaces[ns.Name] = true
+		status.Namespaces++
+	}
+
+	for _, workloads := range scanOptOutWorkloads() {
+		list := workloads.newList()
+		if err := kubeClient.List(ctx, list, optedOut); err != nil {
+			return nil, fmt.Errorf("failed to list %T: %w", list, err)
+		}
+		err := meta.EachListItem(list, func(o runtime.Object) error {
+			obj, err := meta.Accessor(o)
+			if err != nil {
+				return err
+			}
+			if !optedOutNamespaces[obj.GetNamespace()] {
+				status.Workloads++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+type scanOptOutKind struct {
+	obj     client.Object
+	newList func() client.ObjectList
+	// standaloneOnly only counts the objects without a controlling owner
+	standaloneOnly bool
+}
+
+func (k scanOptOutKind) indexValue(obj client.Object) []string {
+	if !ScanOptedOut(obj.GetAnnotations()) {
+		return nil
+	}
+	if k.standaloneOnly && metav1.GetControllerOfNoCopy(obj) != nil {
+		return nil
+	}
+	return []string{"true"}
+}
+
+// scanOptOutWorkloads returns the kinds of workloads counted by CountScanOptOuts. The operator already caches
+// Pods, Deployments and CronJobs, the other kinds are only cached as metadata.
+func scanOptOutWorkloads() []scanOptOutKind {
+	metadata := func(gvk schema.GroupVersionKind, standaloneOnly bool) scanOptOutKind {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(gvk)
+		return scanOptOutKind{
+			obj: obj,
+			newList: func() client.ObjectList {
+				list := &metav1.PartialObjectMetadataList{}
+				list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+				return list
+			},
+			standaloneOnly: standaloneOnly,
+		}
+	}
+	return []scanOptOutKind{
+		{obj: &appsv1.Deployment{}, newList: func() client.ObjectList { return &appsv1.DeploymentList{} }},
+		{obj: &batchv1.CronJob{}, newList: func() client.ObjectList { return &batchv1.CronJobList{} }},
+		{obj: &corev1.Pod{}, newList: func() client.ObjectList { return &corev1.PodList{} }, standaloneOnly: true},
+		metadata(appsv1.SchemeGroupVersion.WithKind("DaemonSet"), false),
+		metadata(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), false),
+		metadata(batchv1.SchemeGroupVersion.WithKind("Job"), true),
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func TestCountScanOptOuts(t *testing.T) {
+	optOut := map[string]string{constants.MondooScanAnnotation: "false"}
+	builder := fake.NewClientBuilder()
+	require.NoError(t, IndexScanOptOuts(context.Background(), fakeIndexer{builder}))
+	kubeClient := builder.WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vendor", Annotations: optOut}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "noisy", Namespace: "default", Annotations: optOut}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "vendor", Annotations: optOut}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Annotations: optOut}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default", Annotations: optOut}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "backup-1", Namespace: "default", Annotations: optOut,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", UID: "1", Controller: ptr.To(true)}},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default", Annotations: optOut}},
+	).Build()
+
+	status, err := CountScanOptOuts(context.Background(), kubeClient)
+	require.NoError(t, err)
+	assert.Equal(t, &v1alpha2.ScanOptOutStatus{Namespaces: 1, Workloads: 4}, status)
+}
+
+// fakeIndexer registers field indexes with a fake client builder.
+type fakeIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i fakeIndexer) IndexField(_ context.Context, obj client.Object, field string, extract client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extract)
+	return nil
+}
+
+func TestScanOptedOut(t *testing.T) {
+	assert.True(t, ScanOptedOut(map[string]string{constants.MondooScanAnnotation: "False"}))
+	assert.False(t, ScanOptedOut(map[string]string{constants.MondooScanAnnotation: "true"}))
+	assert.False(t, ScanOptedOut(nil))
+}
@@ -0,0 +1,250 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+// SecretsHashAnnotation is set on the pod templates of long-running workloads. It holds a hash of the
+// content of all Secrets the pods use, so the pods are rolled when one of them changes.
+const SecretsHashAnnotation = "k8s.mondoo.com/secrets-hash"
+
+// ReferencedSecretNames returns the names of the Secrets in the namespace of the MondooAuditConfig that
+// the MondooAuditConfig and the MondooOperatorConfig reference. cfg may be nil.
+func ReferencedSecretNames(m v1alpha2.MondooAuditConfig, cfg *v1alpha2.MondooOperatorConfig) []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	addRef := func(ref *corev1.LocalObjectReference) {
+		if ref != nil {
+			add(ref.Name)
+		}
+	}
+
+	add(m.Spec.MondooCredsSecretRef.Name)
+	add(m.Spec.MondooTokenSecretRef.Name)
+	if source := m.Spec.MondooCredsSource; source != nil && source.Vault != nil {
+		addRef(source.Vault.CACertSecretRef)
+	}
+	for _, scan := range ScanTypes(m) {
+		_, credsRef := ScanCredentials(m, scan)
+		add(credsRef.Name)
+	}
+
+	registrySecrets := collectSecretNames(&m)
+	if len(registrySecrets) == 0 {
+		registrySecrets = []string{DefaultPrivateRegistriesSecretName}
+	}
+	for _, name := range registrySecrets {
+		add(name)
+	}
+
+	for _, cluster := range m.Spec.KubernetesResources.ExternalClusters {
+		addRef(cluster.KubeconfigSecretRef)
+		addRef(cluster.PrivateRegistriesPullSecretRef)
+		if cluster.ServiceAccountAuth != nil {
+			add(cluster.ServiceAccountAuth.CredentialsSecretRef.Name)
+		}
+		if cluster.SPIFFEAuth != nil {
+			add(cluster.SPIFFEAuth.TrustBundleSecretRef.Name)
+		}
+		if cluster.VaultAuth != nil {
+			addRef(cluster.VaultAuth.CACertSecretRef)
+			addRef(cluster.VaultAuth.TargetCACertSecretRef)
+		}
+	}
+
+	if cfg != nil {
+		addRef(cfg.Spec.CABundleSecretRef)
+		addRef(cfg.Spec.ClientCertificateSecretRef)
+		addRef(cfg.Spec.ProxyCredentialsSecretRef)
+		for _, ref := range []*corev1.SecretKeySelector{cfg.Spec.HttpProxySecretRef, cfg.Spec.HttpsProxySecretRef, cfg.Spec.ContainerProxySecretRef} {
+			if ref != nil {
+				add(ref.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// ValidateReferencedSecrets checks that the Secrets referenced by the MondooAuditConfig exist and have the
+// keys the workloads read. The default private registries Secret is optional. The Secrets of the
+// MondooOperatorConfig are validated with the TLS and proxy configuration. All problems are returned joined.
+func ValidateReferencedSecrets(ctx context.Context, kubeClient client.Client, m v1alpha2.MondooAuditConfig) error {
+	var errs []error
+	checked := map[string]bool{}
+	check := func(name, field string, validate func(*corev1.Secret) error) {
+		if name == "" || checked[name] {
+			return
+		}
+		checked[name] = true
+
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("secret %s of %s not found", name, field))
+				return
+			}
+			errs = append(errs, fmt.Errorf("failed to get secret %s of %s: %w", name, field, err))
+			return
+		}
+		if err := validate(secret); err != nil {
+			errs = append(errs, fmt.Errorf("secret %s of %s: %w", name, field, err))
+		}
+	}
+
+	// A missing credentials Secret is created from the token, so the token Secret is only checked
+	// through exchanging it
+	for _, scan := range ScanTypes(m) {
+		_, credsRef := ScanCredentials(m, scan)
+		check(credsRef.Name, "mondooCredsSecretRef", validJSONKey(constants.MondooCredsSecretServiceAccountKey))
+	}
+
+	if source := m.Spec.MondooCredsSource; source != nil && source.Vault != nil && source.Vault.CACertSecretRef != nil {
+		check(source.Vault.CACertSecretRef.Name, "mondooCredsSource", hasKeys(corev1.ServiceAccountRootCAKey))
+	}
+
+	for _, name := range collectSecretNames(&m) {
+		check(name, "privateRegistriesPullSecretRefs", validJSONKey(corev1.DockerConfigJsonKey))
+	}
+
+	for _, cluster := range m.Spec.KubernetesResources.ExternalClusters {
+		field := fmt.Sprintf("external cluster %s", cluster.Name)
+		if cluster.KubeconfigSecretRef != nil {
+			check(cluster.KubeconfigSecretRef.Name, field, hasKeys("kubeconfig"))
+		}
+		if cluster.PrivateRegistriesPullSecretRef != nil {
+			check(cluster.PrivateRegistriesPullSecretRef.Name, field, validJSONKey(corev1.DockerConfigJsonKey))
+		}
+		if cluster.ServiceAccountAuth != nil {
+			check(cluster.ServiceAccountAuth.CredentialsSecretRef.Name, field, hasKeys(corev1.ServiceAccountTokenKey, corev1.ServiceAccountRootCAKey))
+		}
+		if cluster.SPIFFEAuth != nil {
+			check(cluster.SPIFFEAuth.TrustBundleSecretRef.Name, field, hasKeys(corev1.ServiceAccountRootCAKey))
+		}
+		if cluster.VaultAuth != nil {
+			if ref := cluster.VaultAuth.CACertSecretRef; ref != nil {
+				check(ref.Name, field, hasKeys(corev1.ServiceAccountRootCAKey))
+			}
+			if ref := cluster.VaultAuth.TargetCACertSecretRef; ref != nil {
+				check(ref.Name, field, hasKeys(corev1.ServiceAccountRootCAKey))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func hasKeys(keys ...string) func(*corev1.Secret) error {
+	return func(secret *corev1.Secret) error {
+		for _, key := range keys {
+			if len(secret.Data[key]) == 0 {
+				return fmt.Errorf("missing key %q", key)
+			}
+		}
+		return nil
+	}
+}
+
+func validJSONKey(key string) func(*corev1.Secret) error {
+	return func(secret *corev1.Secret) error {
+		if err := hasKeys(key)(secret); err != nil {
+			return err
+		}
+		if !json.Valid(secret.Data[key]) {
+			return fmt.Errorf("key %q is not valid JSON", key)
+		}
+		return nil
+	}
+}
+
+// PodSecretNames returns the names of the Secrets the pods of the pod spec mount or read env vars from.
+// Image pull secrets are only used when the pods are created, so they are not included.
+func PodSecretNames(podSpec corev1.PodSpec) []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil {
+			add(volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					add(source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, container := range slices.Concat(podSpec.InitContainers, podSpec.Containers) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				add(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				add(envFrom.SecretRef.Name)
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// SetSecretsHashAnnotation sets the SecretsHashAnnotation of the pod template to the hash of the content
+// of the Secrets its pods use. Missing Secrets are part of the hash, so the pods are rolled once they are
+// created.
+func SetSecretsHashAnnotation(ctx context.Context, kubeClient client.Client, namespace string, template *corev1.PodTemplateSpec) error {
+	hash := sha256.New()
+	for _, name := range PodSecretNames(template.Spec) {
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get secret %s: %w", name, err)
+			}
+			fmt.Fprintf(hash, "%s:missing\n", name)
+			continue
+		}
+
+		fmt.Fprintf(hash, "%s:%s\n", name, secret.Type)
+		keys := make([]string, 0, len(secret.Data))
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s=%x\n", key, sha256.Sum256(secret.Data[key]))
+		}
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[SecretsHashAnnotation] = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
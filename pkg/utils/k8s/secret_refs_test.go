@@ -0,0 +1,154 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func secretRefsAuditConfig() v1alpha2.MondooAuditConfig {
+	return v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client", Namespace: "mondoo-operator"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "mondoo-creds"},
+			Nodes: v1alpha2.Nodes{
+				MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "nodes-creds"},
+			},
+			Scanner: v1alpha2.Scanner{
+				PrivateRegistriesPullSecretRefs: []corev1.LocalObjectReference{{Name: "registry"}},
+			},
+			KubernetesResources: v1alpha2.KubernetesResources{
+				ExternalClusters: []v1alpha2.ExternalCluster{
+					{Name: "remote", KubeconfigSecretRef: &corev1.LocalObjectReference{Name: "remote-kubeconfig"}},
+					{
+						Name: "vault",
+						VaultAuth: &v1alpha2.VaultAuthConfig{
+							CACertSecretRef: &corev1.LocalObjectReference{Name: "vault-ca"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestReferencedSecretNames(t *testing.T) {
+	m := secretRefsAuditConfig()
+	cfg := &v1alpha2.MondooOperatorConfig{
+		Spec: v1alpha2.MondooOperatorConfigSpec{
+			CABundleSecretRef:   &corev1.LocalObjectReference{Name: "ca-bundle"},
+			HttpsProxySecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}, Key: "url"},
+		},
+	}
+
+	assert.ElementsMatch(t,
+		[]string{"mondoo-creds", "nodes-creds", "registry", "remote-kubeconfig", "vault-ca", "ca-bundle", "proxy"},
+		ReferencedSecretNames(m, cfg))
+}
+
+func TestReferencedSecretNames_DefaultPrivateRegistriesSecret(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{
+		Spec: v1alpha2.MondooAuditConfigSpec{MondooCredsSecretRef: corev1.LocalObjectReference{Name: "mondoo-creds"}},
+	}
+
+	assert.ElementsMatch(t, []string{"mondoo-creds", DefaultPrivateRegistriesSecretName}, ReferencedSecretNames(m, nil))
+}
+
+func TestValidateReferencedSecrets(t *testing.T) {
+	m := secretRefsAuditConfig()
+	secret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: m.Namespace}, Data: data}
+	}
+	valid := []*corev1.Secret{
+		secret("mondoo-creds", map[string][]byte{constants.MondooCredsSecretServiceAccountKey: []byte(`{"mrn":"sa"}`)}),
+		secret("nodes-creds", map[string][]byte{constants.MondooCredsSecretServiceAccountKey: []byte(`{"mrn":"nodes"}`)}),
+		secret("registry", map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)}),
+		secret("remote-kubeconfig", map[string][]byte{"kubeconfig": []byte("apiVersion: v1")}),
+		secret("vault-ca", map[string][]byte{"ca.crt": []byte("cert")}),
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		kubeClient := fake.NewClientBuilder().WithObjects(valid[0], valid[1], valid[2], valid[3], valid[4]).Build()
+		require.NoError(t, ValidateReferencedSecrets(context.Background(), kubeClient, m))
+	})
+
+	t.Run("missing and malformed", func(t *testing.T) {
+		malformedCreds := secret("mondoo-creds", map[string][]byte{constants.MondooCredsSecretServiceAccountKey: []byte("not json")})
+		noKubeconfig := secret("remote-kubeconfig", map[string][]byte{"config": []byte("apiVersion: v1")})
+		kubeClient := fake.NewClientBuilder().WithObjects(malformedCreds, valid[1], noKubeconfig, valid[4]).Build()
+
+		err := ValidateReferencedSecrets(context.Background(), kubeClient, m)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `secret mondoo-creds of mondooCredsSecretRef: key "config" is not valid JSON`)
+		assert.Contains(t, err.Error(), "secret registry of privateRegistriesPullSecretRefs not found")
+		assert.Contains(t, err.Error(), `secret remote-kubeconfig of external cluster remote: missing key "kubeconfig"`)
+		assert.NotContains(t, err.Error(), "vault-ca")
+	})
+}
+
+func TestPodSecretNames(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "mondoo-creds"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "ca-bundle"}}}},
+			}}},
+		},
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{{Name: "HTTPS_PROXY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "proxy"}, Key: "url",
+			}}}},
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "mondoo-creds"}}}},
+		}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+
+	assert.Equal(t, []string{"ca-bundle", "mondoo-creds", "proxy"}, PodSecretNames(podSpec))
+}
+
+func TestSetSecretsHashAnnotation(t *testing.T) {
+	ctx := context.Background()
+	creds := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-creds", Namespace: "mondoo-operator"},
+		Data:       map[string][]byte{"config": []byte("old")},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(creds).Build()
+	template := func() *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "mondoo-creds"}}},
+			{Name: "ca", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "ca-bundle", Optional: ptr.To(true)}}},
+		}}}
+	}
+	hash := func() string {
+		tmpl := template()
+		require.NoError(t, SetSecretsHashAnnotation(ctx, kubeClient, "mondoo-operator", tmpl))
+		require.NotEmpty(t, tmpl.Annotations[SecretsHashAnnotation])
+		return tmpl.Annotations[SecretsHashAnnotation]
+	}
+
+	initial := hash()
+	assert.Equal(t, initial, hash(), "the hash must be stable")
+
+	creds.Data["config"] = []byte("new")
+	require.NoError(t, kubeClient.Update(ctx, creds))
+	rotated := hash()
+	assert.NotEqual(t, initial, rotated)
+
+	require.NoError(t, kubeClient.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca-bundle", Namespace: "mondoo-operator"},
+		Data:       map[string][]byte{"ca.crt": []byte("cert")},
+	}))
+	assert.NotEqual(t, rotated, hash(), "creating a missing Secret must change the hash")
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,14 +28,87 @@ const (
 	SpaceMrnPrefix = "//captain.api.mondoo.app/spaces/"
 )
 
-// ConfigSecretRef returns the Secret reference to use for mounting the mondoo config.
-// When spaceId is set, it returns the derived config override Secret; otherwise it
-// returns the original MondooCredsSecretRef.
-func ConfigSecretRef(m v1alpha2.MondooAuditConfig) corev1.LocalObjectReference {
-	if m.Spec.SpaceID != "" {
+// ScanType identifies the scans that can be sent to their own space with their own credentials.
+type ScanType string
+
+const (
+	KubernetesResourcesScan ScanType = "k8s-resources"
+	ContainersScan          ScanType = "containers"
+	NodesScan               ScanType = "nodes"
+
+	externalClusterScanPrefix = "cluster-"
+)
+
+// ExternalClusterScan returns the ScanType of the scans of the external cluster with the given name.
+func ExternalClusterScan(clusterName string) ScanType {
+	return ScanType(externalClusterScanPrefix + clusterName)
+}
+
+// ScanTypes returns the ScanTypes of all scans configured in the MondooAuditConfig, whether they are
+// enabled or not.
+func ScanTypes(m v1alpha2.MondooAuditConfig) []ScanType {
+	scans := []ScanType{KubernetesResourcesScan, ContainersScan, NodesScan}
+	for _, cluster := range m.Spec.KubernetesResources.ExternalClusters {
+		scans = append(scans, ExternalClusterScan(cluster.Name))
+	}
+	return scans
+}
+
+// ScanCredentials returns the space ID and the credentials Secret the given scan uses. The overrides of
+// an external cluster fall back to the ones of kubernetesResources, which fall back to the ones of the spec.
+func ScanCredentials(m v1alpha2.MondooAuditConfig, scan ScanType) (string, corev1.LocalObjectReference) {
+	spaceID, credsRef := m.Spec.SpaceID, m.Spec.MondooCredsSecretRef
+	override := func(id string, ref *corev1.LocalObjectReference) {
+		if id != "" {
+			spaceID = id
+		}
+		if ref != nil && ref.Name != "" {
+			credsRef = *ref
+		}
+	}
+
+	switch {
+	case scan == NodesScan:
+		override(m.Spec.Nodes.SpaceID, m.Spec.Nodes.MondooCredsSecretRef)
+	case scan == ContainersScan:
+		override(m.Spec.Containers.SpaceID, m.Spec.Containers.MondooCredsSecretRef)
+	case scan == KubernetesResourcesScan:
+		override(m.Spec.KubernetesResources.SpaceID, m.Spec.KubernetesResources.MondooCredsSecretRef)
+	case strings.HasPrefix(string(scan), externalClusterScanPrefix):
+		override(m.Spec.KubernetesResources.SpaceID, m.Spec.KubernetesResources.MondooCredsSecretRef)
+		for _, cluster := range m.Spec.KubernetesResources.ExternalClusters {
+			if ExternalClusterScan(cluster.Name) == scan {
+				override(cluster.SpaceID, cluster.MondooCredsSecretRef)
+			}
+		}
+	}
+	return spaceID, credsRef
+}
+
+// hasScanOverride reports whether the given scan uses another space or other credentials than the spec.
+func hasScanOverride(m v1alpha2.MondooAuditConfig, scan ScanType) bool {
+	spaceID, credsRef := ScanCredentials(m, scan)
+	return spaceID != m.Spec.SpaceID || credsRef.Name != m.Spec.MondooCredsSecretRef.Name
+}
+
+// ScanConfigSecretName returns the name of the derived config Secret of a scan that overrides the space.
+func ScanConfigSecretName(name string, scan ScanType) string {
+	return name + "-" + string(scan) + ConfigOverrideSecretSuffix
+}
+
+// ConfigSecretRef returns the Secret reference to use for mounting the mondoo config of the given scan.
+// When the scan sends its assets to a space set by spaceId, it returns the derived config override Secret;
+// otherwise it returns the credentials Secret of the scan.
+func ConfigSecretRef(m v1alpha2.MondooAuditConfig, scan ScanType) corev1.LocalObjectReference {
+	spaceID, credsRef := ScanCredentials(m, scan)
+	switch {
+	case spaceID == "":
+		return credsRef
+	case hasScanOverride(m, scan):
+		return corev1.LocalObjectReference{Name: ScanConfigSecretName(m.Name, scan)}
+	default:
 		return corev1.LocalObjectReference{Name: m.Name + ConfigOverrideSecretSuffix}
 	}
-	return m.Spec.MondooCredsSecretRef
 }
 
 // SyncConfigOverrideSecret creates or updates a derived Secret that injects scope_mrn
@@ -61,6 +136,87 @@ func SyncConfigOverrideSecret(
 		return fmt.Errorf("failed to get credentials secret: %w", err)
 	}
 
+	return syncDerivedConfigSecret(ctx, kubeClient, m, origSecret, m.Name+ConfigOverrideSecretSuffix, m.Spec.SpaceID, nil)
+}
+
+// SyncSpaceConfigSecrets creates or updates the derived config Secrets that route assets to the spaces
+// of spec.spaceRouting, and deletes the Secrets of spaces that are no longer routed.
+func SyncSpaceConfigSecrets(
+	ctx context.Context,
+	kubeClient client.Client,
+	m *v1alpha2.MondooAuditConfig,
+) error {
+	spaceIDs := RoutedSpaceIDs(*m)
+	for _, status := range m.Status.SpaceRouting {
+		if slices.Contains(spaceIDs, status.SpaceID) {
+			continue
+		}
+		stale := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      SpaceConfigSecretName(m.Name, status.SpaceID),
+			Namespace: m.Namespace,
+		}}
+		if err := DeleteIfExists(ctx, kubeClient, stale); err != nil {
+			return fmt.Errorf("failed to clean up config secret of space %s: %w", status.SpaceID, err)
+		}
+	}
+	if len(spaceIDs) == 0 {
+		return nil
+	}
+
+	origSecret, err := GetIntegrationSecretForAuditConfig(ctx, kubeClient, *m)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials secret: %w", err)
+	}
+
+	for _, id := range spaceIDs {
+		labels := map[string]string{"mondoo_cr": m.Name, SpaceIDLabel: id}
+		if err := syncDerivedConfigSecret(ctx, kubeClient, m, origSecret, SpaceConfigSecretName(m.Name, id), id, labels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncScanConfigSecrets creates or updates the derived config Secrets of the scans that override the space
+// or the credentials of the spec and send their assets to a space set by spaceId, and deletes the derived
+// config Secrets of the other scans.
+func SyncScanConfigSecrets(
+	ctx context.Context,
+	kubeClient client.Client,
+	m *v1alpha2.MondooAuditConfig,
+) error {
+	for _, scan := range ScanTypes(*m) {
+		name := ScanConfigSecretName(m.Name, scan)
+		spaceID, credsRef := ScanCredentials(*m, scan)
+		if spaceID == "" || !hasScanOverride(*m, scan) {
+			stale := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: m.Namespace}}
+			if err := DeleteIfExists(ctx, kubeClient, stale); err != nil {
+				return fmt.Errorf("failed to clean up config secret of %s scans: %w", scan, err)
+			}
+			continue
+		}
+
+		origSecret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Name: credsRef.Name, Namespace: m.Namespace}, origSecret); err != nil {
+			return fmt.Errorf("failed to get credentials secret of %s scans: %w", scan, err)
+		}
+		if err := syncDerivedConfigSecret(ctx, kubeClient, m, origSecret, name, spaceID, map[string]string{"mondoo_cr": m.Name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDerivedConfigSecret creates or updates a copy of the credentials Secret with scope_mrn injected
+// into the service account config.
+func syncDerivedConfigSecret(
+	ctx context.Context,
+	kubeClient client.Client,
+	m *v1alpha2.MondooAuditConfig,
+	origSecret *corev1.Secret,
+	name, spaceID string,
+	labels map[string]string,
+) error {
 	saData, ok := origSecret.Data[constants.MondooCredsSecretServiceAccountKey]
 	if !ok {
 		return fmt.Errorf("credentials secret missing key %q", constants.MondooCredsSecretServiceAccountKey)
@@ -74,7 +230,7 @@ func SyncConfigOverrideSecret(
 		return fmt.Errorf("failed to unmarshal service account config: %w", err)
 	}
 
-	config["scope_mrn"] = SpaceMrnPrefix + m.Spec.SpaceID
+	config["scope_mrn"] = SpaceMrnPrefix + spaceID
 
 	modifiedData, err := json.Marshal(config)
 	if err != nil {
@@ -83,7 +239,7 @@ func SyncConfigOverrideSecret(
 
 	derivedSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      m.Name + ConfigOverrideSecretSuffix,
+			Name:      name,
 			Namespace: m.Namespace,
 		},
 	}
@@ -94,6 +250,9 @@ func SyncConfigOverrideSecret(
 			return err
 		}
 
+		if labels != nil {
+			derivedSecret.Labels = labels
+		}
 		if derivedSecret.Data == nil {
 			derivedSecret.Data = make(map[string][]byte)
 		}
@@ -113,11 +272,11 @@ func SyncConfigOverrideSecret(
 	return nil
 }
 
-// SpaceMrnForAuditConfig returns the space MRN for the given MondooAuditConfig.
-// If spaceId is set, it constructs the MRN from that. Otherwise returns empty string.
-func SpaceMrnForAuditConfig(m v1alpha2.MondooAuditConfig) string {
-	if m.Spec.SpaceID != "" {
-		return SpaceMrnPrefix + m.Spec.SpaceID
+// SpaceMrnForAuditConfig returns the space MRN the given scan of the MondooAuditConfig sends its assets to.
+// If a spaceId applies to the scan, it constructs the MRN from that. Otherwise returns empty string.
+func SpaceMrnForAuditConfig(m v1alpha2.MondooAuditConfig, scan ScanType) string {
+	if spaceID, _ := ScanCredentials(m, scan); spaceID != "" {
+		return SpaceMrnPrefix + spaceID
 	}
 	return ""
 }
@@ -26,7 +26,7 @@ func TestConfigSecretRef_NoSpaceID(t *testing.T) {
 			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "my-creds"},
 		},
 	}
-	ref := ConfigSecretRef(m)
+	ref := ConfigSecretRef(m, KubernetesResourcesScan)
 	assert.Equal(t, "my-creds", ref.Name)
 }
 
@@ -38,21 +38,76 @@ func TestConfigSecretRef_WithSpaceID(t *testing.T) {
 			SpaceID:              "abc123",
 		},
 	}
-	ref := ConfigSecretRef(m)
+	ref := ConfigSecretRef(m, KubernetesResourcesScan)
 	assert.Equal(t, "test-audit"+ConfigOverrideSecretSuffix, ref.Name)
 }
 
 func TestSpaceMrnForAuditConfig(t *testing.T) {
 	t.Run("empty when no spaceId", func(t *testing.T) {
 		m := v1alpha2.MondooAuditConfig{}
-		assert.Equal(t, "", SpaceMrnForAuditConfig(m))
+		assert.Equal(t, "", SpaceMrnForAuditConfig(m, KubernetesResourcesScan))
 	})
 
 	t.Run("constructs MRN when spaceId set", func(t *testing.T) {
 		m := v1alpha2.MondooAuditConfig{
 			Spec: v1alpha2.MondooAuditConfigSpec{SpaceID: "abc123"},
 		}
-		assert.Equal(t, "//captain.api.mondoo.app/spaces/abc123", SpaceMrnForAuditConfig(m))
+		assert.Equal(t, "//captain.api.mondoo.app/spaces/abc123", SpaceMrnForAuditConfig(m, KubernetesResourcesScan))
+	})
+}
+
+func TestScanCredentials(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-audit"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "org-creds"},
+			SpaceID:              "default-space",
+			Nodes:                v1alpha2.Nodes{SpaceID: "nodes-space"},
+			Containers:           v1alpha2.Containers{MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "containers-creds"}},
+			KubernetesResources: v1alpha2.KubernetesResources{
+				SpaceID:              "k8s-space",
+				MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "k8s-creds"},
+				ExternalClusters: []v1alpha2.ExternalCluster{
+					{Name: "inherits"},
+					{Name: "prod", SpaceID: "prod-space"},
+					{Name: "shared", SpaceID: "default-space", MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "org-creds"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		scan       ScanType
+		spaceID    string
+		creds      string
+		configName string
+	}{
+		{scan: NodesScan, spaceID: "nodes-space", creds: "org-creds", configName: "test-audit-nodes-config-override"},
+		{scan: ContainersScan, spaceID: "default-space", creds: "containers-creds", configName: "test-audit-containers-config-override"},
+		{scan: KubernetesResourcesScan, spaceID: "k8s-space", creds: "k8s-creds", configName: "test-audit-k8s-resources-config-override"},
+		{scan: ExternalClusterScan("inherits"), spaceID: "k8s-space", creds: "k8s-creds", configName: "test-audit-cluster-inherits-config-override"},
+		{scan: ExternalClusterScan("prod"), spaceID: "prod-space", creds: "k8s-creds", configName: "test-audit-cluster-prod-config-override"},
+		// The same space and credentials as the spec use the spec's config override Secret
+		{scan: ExternalClusterScan("shared"), spaceID: "default-space", creds: "org-creds", configName: "test-audit-config-override"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scan), func(t *testing.T) {
+			spaceID, credsRef := ScanCredentials(m, tt.scan)
+			assert.Equal(t, tt.spaceID, spaceID)
+			assert.Equal(t, tt.creds, credsRef.Name)
+			assert.Equal(t, tt.configName, ConfigSecretRef(m, tt.scan).Name)
+			assert.Equal(t, SpaceMrnPrefix+tt.spaceID, SpaceMrnForAuditConfig(m, tt.scan))
+		})
+	}
+
+	t.Run("credentials override without spaceId", func(t *testing.T) {
+		m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "org-creds"},
+			Nodes:                v1alpha2.Nodes{MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "nodes-creds"}},
+		}}
+		assert.Equal(t, "nodes-creds", ConfigSecretRef(m, NodesScan).Name)
+		assert.Equal(t, "org-creds", ConfigSecretRef(m, ContainersScan).Name)
+		assert.Empty(t, SpaceMrnForAuditConfig(m, NodesScan))
 	})
 }
 
@@ -190,3 +245,110 @@ func TestSyncConfigOverrideSecret(t *testing.T) {
 		assert.Equal(t, "//captain.api.mondoo.app/spaces/new-space", derivedConfig["scope_mrn"])
 	})
 }
+
+func TestSyncSpaceConfigSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	origConfigBytes, _ := json.Marshal(map[string]any{
+		"mrn":         "//agents.api.mondoo.app/organizations/org1/serviceaccounts/sa1",
+		"private_key": "test-key",
+	})
+	origSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "org-creds", Namespace: "default"},
+		Data: map[string][]byte{
+			constants.MondooCredsSecretServiceAccountKey: origConfigBytes,
+		},
+	}
+	staleSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: SpaceConfigSecretName("test", "old-space"), Namespace: "default"},
+	}
+
+	m := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "uid-123"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "org-creds"},
+			SpaceRouting: []v1alpha2.SpaceRoutingRule{
+				{SpaceID: "team-a", Namespaces: []string{"team-a-*"}},
+				{SpaceID: "team-b", Namespaces: []string{"team-b-*"}},
+			},
+		},
+		Status: v1alpha2.MondooAuditConfigStatus{
+			SpaceRouting: []v1alpha2.SpaceRoutingStatus{{SpaceID: "old-space"}, {SpaceID: "team-a"}},
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(origSecret, staleSecret).Build()
+	require.NoError(t, SyncSpaceConfigSecrets(context.Background(), kubeClient, m))
+
+	for _, id := range []string{"team-a", "team-b"} {
+		secret := &corev1.Secret{}
+		require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKey{Name: SpaceConfigSecretName("test", id), Namespace: "default"}, secret))
+		assert.Equal(t, id, secret.Labels[SpaceIDLabel])
+
+		var config map[string]any
+		require.NoError(t, json.Unmarshal(secret.Data[constants.MondooCredsSecretServiceAccountKey], &config))
+		assert.Equal(t, SpaceMrnPrefix+id, config["scope_mrn"])
+		assert.Equal(t, "test-key", config["private_key"])
+	}
+
+	err := kubeClient.Get(context.Background(), client.ObjectKeyFromObject(staleSecret), &corev1.Secret{})
+	assert.Error(t, err, "config secret of a space that is no longer routed should have been deleted")
+}
+
+func TestSyncScanConfigSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	newCredsSecret := func(name, key string) *corev1.Secret {
+		config, _ := json.Marshal(map[string]any{"mrn": "//agents.api.mondoo.app/serviceaccounts/" + name, "private_key": key})
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string][]byte{constants.MondooCredsSecretServiceAccountKey: config},
+		}
+	}
+	staleSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: ScanConfigSecretName("test", NodesScan), Namespace: "default"},
+	}
+
+	m := &v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "uid-123"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "org-creds"},
+			Containers:           v1alpha2.Containers{SpaceID: "images"},
+			KubernetesResources: v1alpha2.KubernetesResources{
+				ExternalClusters: []v1alpha2.ExternalCluster{
+					{Name: "prod", SpaceID: "prod-space", MondooCredsSecretRef: &corev1.LocalObjectReference{Name: "prod-creds"}},
+				},
+			},
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(newCredsSecret("org-creds", "org-key"), newCredsSecret("prod-creds", "prod-key"), staleSecret).
+		Build()
+	require.NoError(t, SyncScanConfigSecrets(context.Background(), kubeClient, m))
+
+	for scan, want := range map[ScanType][2]string{
+		ContainersScan:              {"images", "org-key"},
+		ExternalClusterScan("prod"): {"prod-space", "prod-key"},
+	} {
+		secret := &corev1.Secret{}
+		require.NoError(t, kubeClient.Get(context.Background(), client.ObjectKey{Name: ScanConfigSecretName("test", scan), Namespace: "default"}, secret))
+
+		var config map[string]any
+		require.NoError(t, json.Unmarshal(secret.Data[constants.MondooCredsSecretServiceAccountKey], &config))
+		assert.Equal(t, SpaceMrnPrefix+want[0], config["scope_mrn"])
+		assert.Equal(t, want[1], config["private_key"])
+	}
+
+	err := kubeClient.Get(context.Background(), client.ObjectKeyFromObject(staleSecret), &corev1.Secret{})
+	assert.Error(t, err, "config secret of a scan without overrides should have been deleted")
+
+	// A missing credentials Secret is reported
+	m.Spec.Nodes.MondooCredsSecretRef = &corev1.LocalObjectReference{Name: "missing"}
+	m.Spec.Nodes.SpaceID = "nodes-space"
+	assert.ErrorContains(t, SyncScanConfigSecrets(context.Background(), kubeClient, m), "credentials secret of nodes scans")
+}
@@ -0,0 +1,163 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+)
+
+// SpaceIDLabel is set on the workloads and Secrets that belong to a space of spec.spaceRouting.
+const SpaceIDLabel = "space_id"
+
+// SpaceRoute holds the namespaces whose assets spec.spaceRouting routes to a Mondoo space.
+type SpaceRoute struct {
+	SpaceID    string
+	Namespaces []string
+}
+
+// SpaceRouting is spec.spaceRouting resolved against the namespaces of the operator's cluster.
+type SpaceRouting struct {
+	// Default selects the namespaces whose assets go to the default space.
+	Default v1alpha2.Filtering
+	// DefaultEmpty is true if all namespaces selected by the filtering are routed to other spaces. Default
+	// has no Include list then, which would select all namespaces, so only cluster-wide assets go to the
+	// default space.
+	DefaultEmpty bool
+	// Routes are the routed spaces with at least one namespace, sorted by space ID.
+	Routes []SpaceRoute
+}
+
+// ValidateSpaceRouting checks that every rule of spec.spaceRouting selects namespaces.
+func ValidateSpaceRouting(m v1alpha2.MondooAuditConfig) error {
+	for i, rule := range m.Spec.SpaceRouting {
+		if rule.SpaceID == "" {
+			return fmt.Errorf("spaceRouting[%d]: spaceId must not be empty", i)
+		}
+		if len(rule.Namespaces) == 0 && rule.NamespaceSelector == nil {
+			return fmt.Errorf("spaceRouting[%d]: namespaces or namespaceSelector must be set", i)
+		}
+		if rule.NamespaceSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector); err != nil {
+				return fmt.Errorf("spaceRouting[%d]: invalid namespace selector: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RoutedSpaceIDs returns the distinct space IDs of spec.spaceRouting, sorted.
+func RoutedSpaceIDs(m v1alpha2.MondooAuditConfig) []string {
+	ids := make([]string, 0, len(m.Spec.SpaceRouting))
+	for _, rule := range m.Spec.SpaceRouting {
+		ids = append(ids, rule.SpaceID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// ResolveSpaceRouting routes the namespaces selected by the resolved filtering to the spaces of
+// spec.spaceRouting. Namespaces that no rule matches stay in the default space.
+func ResolveSpaceRouting(ctx context.Context, kubeClient client.Reader, m v1alpha2.MondooAuditConfig, filtering v1alpha2.Filtering) (SpaceRouting, error) {
+	if len(m.Spec.SpaceRouting) == 0 {
+		return SpaceRouting{Default: filtering}, nil
+	}
+	if err := ValidateSpaceRouting(m); err != nil {
+		return SpaceRouting{}, err
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := kubeClient.List(ctx, namespaces); err != nil {
+		return SpaceRouting{}, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	bySpace := map[string][]string{}
+	var routed []string
+	for _, ns := range namespaces.Items {
+		inScope, err := NamespaceMatchesFiltering(filtering.Namespaces, ns.Name, ns.Labels)
+		if err != nil {
+			return SpaceRouting{}, err
+		}
+		if !inScope {
+			continue
+		}
+		for _, rule := range m.Spec.SpaceRouting {
+			spec := v1alpha2.FilteringSpec{Include: rule.Namespaces, NamespaceSelector: rule.NamespaceSelector}
+			matches, err := NamespaceMatchesFiltering(spec, ns.Name, ns.Labels)
+			if err != nil {
+				return SpaceRouting{}, err
+			}
+			if matches {
+				bySpace[rule.SpaceID] = append(bySpace[rule.SpaceID], ns.Name)
+				routed = append(routed, ns.Name)
+				break
+			}
+		}
+	}
+
+	routing := SpaceRouting{Default: *filtering.DeepCopy()}
+	for _, id := range slices.Sorted(maps.Keys(bySpace)) {
+		names := bySpace[id]
+		slices.Sort(names)
+		routing.Routes = append(routing.Routes, SpaceRoute{SpaceID: id, Namespaces: names})
+	}
+
+	// The routed namespaces are removed from the default space
+	if len(filtering.Namespaces.Include) > 0 {
+		routing.Default.Namespaces.Include = slices.DeleteFunc(routing.Default.Namespaces.Include, func(ns string) bool {
+			return slices.Contains(routed, ns)
+		})
+		routing.DefaultEmpty = len(routing.Default.Namespaces.Include) == 0
+	} else {
+		exclude := append(routing.Default.Namespaces.Exclude, routed...)
+		slices.Sort(exclude)
+		routing.Default.Namespaces.Exclude = slices.Compact(exclude)
+	}
+	return routing, nil
+}
+
+// SpaceConfigSecretName returns the name of the derived config Secret that routes assets to a space of
+// spec.spaceRouting.
+func SpaceConfigSecretName(auditConfigName, spaceID string) string {
+	return auditConfigName + ConfigOverrideSecretSuffix + "-" + spaceID
+}
+
+// SpaceAuditConfig returns a copy of the MondooAuditConfig for the workloads that scan the namespaces of
+// a route. Its credentials are the derived config Secret of the space and its filtering only includes the
+// routed namespaces.
+func SpaceAuditConfig(m v1alpha2.MondooAuditConfig, route SpaceRoute) v1alpha2.MondooAuditConfig {
+	routed := *m.DeepCopy()
+	routed.Spec.SpaceID = ""
+	routed.Spec.SpaceRouting = nil
+	routed.Spec.MondooCredsSecretRef = corev1.LocalObjectReference{Name: SpaceConfigSecretName(m.Name, route.SpaceID)}
+	// Routed scans always use the space's config Secret, regardless of the scan type overrides
+	routed.Spec.KubernetesResources.SpaceID, routed.Spec.KubernetesResources.MondooCredsSecretRef = "", nil
+	routed.Spec.Containers.SpaceID, routed.Spec.Containers.MondooCredsSecretRef = "", nil
+	routed.Spec.Filtering.Namespaces = v1alpha2.FilteringSpec{Include: slices.Clone(route.Namespaces)}
+	return routed
+}
+
+// SpaceRoutingStatus returns the number of namespaces routed to each space of spec.spaceRouting.
+func SpaceRoutingStatus(m v1alpha2.MondooAuditConfig, routing SpaceRouting) []v1alpha2.SpaceRoutingStatus {
+	if len(m.Spec.SpaceRouting) == 0 {
+		return nil
+	}
+	counts := map[string]int32{}
+	for _, route := range routing.Routes {
+		counts[route.SpaceID] = int32(len(route.Namespaces)) //nolint:gosec
+	}
+	status := make([]v1alpha2.SpaceRoutingStatus, 0, len(m.Spec.SpaceRouting))
+	for _, id := range RoutedSpaceIDs(m) {
+		status = append(status, v1alpha2.SpaceRoutingStatus{SpaceID: id, Namespaces: counts[id]})
+	}
+	return status
+}
@@ -0,0 +1,187 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+)
+
+func TestValidateSpaceRouting(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []v1alpha2.SpaceRoutingRule
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rules: []v1alpha2.SpaceRoutingRule{
+				{SpaceID: "team-a", Namespaces: []string{"team-a-*"}},
+				{SpaceID: "team-b", NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}}},
+			},
+		},
+		{
+			name:    "missing space ID",
+			rules:   []v1alpha2.SpaceRoutingRule{{Namespaces: []string{"team-a"}}},
+			wantErr: "spaceRouting[0]: spaceId must not be empty",
+		},
+		{
+			name:    "no namespaces selected",
+			rules:   []v1alpha2.SpaceRoutingRule{{SpaceID: "team-a", Namespaces: []string{"a"}}, {SpaceID: "team-b"}},
+			wantErr: "spaceRouting[1]: namespaces or namespaceSelector must be set",
+		},
+		{
+			name: "invalid selector",
+			rules: []v1alpha2.SpaceRoutingRule{{
+				SpaceID: "team-a",
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Invalid"}},
+				},
+			}},
+			wantErr: "spaceRouting[0]: invalid namespace selector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{SpaceRouting: tt.rules}}
+			err := ValidateSpaceRouting(m)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRoutedSpaceIDs(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{SpaceRouting: []v1alpha2.SpaceRoutingRule{
+		{SpaceID: "team-b", Namespaces: []string{"b"}},
+		{SpaceID: "team-a", Namespaces: []string{"a"}},
+		{SpaceID: "team-b", Namespaces: []string{"c"}},
+	}}}
+	assert.Equal(t, []string{"team-a", "team-b"}, RoutedSpaceIDs(m))
+	assert.Empty(t, RoutedSpaceIDs(v1alpha2.MondooAuditConfig{}))
+}
+
+func TestResolveSpaceRouting(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		testNamespace("kube-system", nil),
+		testNamespace("team-a-prod", map[string]string{"team": "b"}),
+		testNamespace("team-a-dev", nil),
+		testNamespace("payments", map[string]string{"team": "b"}),
+		testNamespace("team-b-prod", map[string]string{"team": "b"}),
+	).Build()
+
+	rules := []v1alpha2.SpaceRoutingRule{
+		{SpaceID: "team-a", Namespaces: []string{"team-a-*"}},
+		// The first matching rule wins, so team-a-prod is not routed to team-b
+		{SpaceID: "team-b", NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}}},
+		// Names and the selector have to match both
+		{SpaceID: "team-c", Namespaces: []string{"kube-system"}, NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "c"}}},
+		{SpaceID: "team-c", Namespaces: []string{"not-created-yet"}},
+	}
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{SpaceRouting: rules}}
+
+	t.Run("no rules", func(t *testing.T) {
+		filtering := v1alpha2.Filtering{Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}}}
+		routing, err := ResolveSpaceRouting(context.Background(), kubeClient, v1alpha2.MondooAuditConfig{}, filtering)
+		require.NoError(t, err)
+		assert.Equal(t, SpaceRouting{Default: filtering}, routing)
+	})
+
+	t.Run("exclude list", func(t *testing.T) {
+		filtering := v1alpha2.Filtering{Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"team-a-dev"}}}
+		routing, err := ResolveSpaceRouting(context.Background(), kubeClient, m, filtering)
+		require.NoError(t, err)
+
+		assert.Equal(t, []SpaceRoute{
+			{SpaceID: "team-a", Namespaces: []string{"team-a-prod"}},
+			{SpaceID: "team-b", Namespaces: []string{"payments", "team-b-prod"}},
+		}, routing.Routes)
+		assert.Equal(t, []string{"payments", "team-a-dev", "team-a-prod", "team-b-prod"}, routing.Default.Namespaces.Exclude)
+		assert.Empty(t, routing.Default.Namespaces.Include)
+		assert.False(t, routing.DefaultEmpty)
+	})
+
+	t.Run("include list", func(t *testing.T) {
+		filtering := v1alpha2.Filtering{Namespaces: v1alpha2.FilteringSpec{Include: []string{"kube-system", "team-a-dev"}}}
+		routing, err := ResolveSpaceRouting(context.Background(), kubeClient, m, filtering)
+		require.NoError(t, err)
+
+		assert.Equal(t, []SpaceRoute{{SpaceID: "team-a", Namespaces: []string{"team-a-dev"}}}, routing.Routes)
+		assert.Equal(t, []string{"kube-system"}, routing.Default.Namespaces.Include)
+		assert.False(t, routing.DefaultEmpty)
+		// The resolved filtering is not modified
+		assert.Equal(t, []string{"kube-system", "team-a-dev"}, filtering.Namespaces.Include)
+	})
+
+	t.Run("all namespaces routed", func(t *testing.T) {
+		filtering := v1alpha2.Filtering{Namespaces: v1alpha2.FilteringSpec{Include: []string{"team-a-dev", "payments"}}}
+		routing, err := ResolveSpaceRouting(context.Background(), kubeClient, m, filtering)
+		require.NoError(t, err)
+
+		assert.Len(t, routing.Routes, 2)
+		assert.Empty(t, routing.Default.Namespaces.Include)
+		assert.True(t, routing.DefaultEmpty)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		invalid := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{
+			SpaceRouting: []v1alpha2.SpaceRoutingRule{{SpaceID: "team-a"}},
+		}}
+		_, err := ResolveSpaceRouting(context.Background(), kubeClient, invalid, v1alpha2.Filtering{})
+		assert.Error(t, err)
+	})
+}
+
+func TestSpaceAuditConfig(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo", Namespace: "mondoo-operator"},
+		Spec: v1alpha2.MondooAuditConfigSpec{
+			MondooCredsSecretRef: corev1.LocalObjectReference{Name: "mondoo-creds"},
+			SpaceID:              "default-space",
+			SpaceRouting:         []v1alpha2.SpaceRoutingRule{{SpaceID: "team-a", Namespaces: []string{"team-a-*"}}},
+			Filtering:            v1alpha2.Filtering{Namespaces: v1alpha2.FilteringSpec{Exclude: []string{"kube-system"}}},
+			Containers:           v1alpha2.Containers{SpaceID: "images"},
+		},
+	}
+
+	routed := SpaceAuditConfig(m, SpaceRoute{SpaceID: "team-a", Namespaces: []string{"team-a-prod"}})
+	assert.Empty(t, routed.Spec.SpaceID)
+	assert.Nil(t, routed.Spec.SpaceRouting)
+	assert.Equal(t, "mondoo-config-override-team-a", routed.Spec.MondooCredsSecretRef.Name)
+	assert.Equal(t, "mondoo-config-override-team-a", ConfigSecretRef(routed, KubernetesResourcesScan).Name)
+	// Scan type overrides don't apply to routed namespaces
+	assert.Equal(t, "mondoo-config-override-team-a", ConfigSecretRef(routed, ContainersScan).Name)
+	assert.Equal(t, v1alpha2.FilteringSpec{Include: []string{"team-a-prod"}}, routed.Spec.Filtering.Namespaces)
+
+	// The original is not modified
+	assert.Equal(t, "default-space", m.Spec.SpaceID)
+	assert.Equal(t, []string{"kube-system"}, m.Spec.Filtering.Namespaces.Exclude)
+}
+
+func TestSpaceRoutingStatus(t *testing.T) {
+	m := v1alpha2.MondooAuditConfig{Spec: v1alpha2.MondooAuditConfigSpec{SpaceRouting: []v1alpha2.SpaceRoutingRule{
+		{SpaceID: "team-b", Namespaces: []string{"team-b-*"}},
+		{SpaceID: "team-a", Namespaces: []string{"team-a-*"}},
+	}}}
+	routing := SpaceRouting{Routes: []SpaceRoute{{SpaceID: "team-a", Namespaces: []string{"team-a-dev", "team-a-prod"}}}}
+
+	assert.Equal(t, []v1alpha2.SpaceRoutingStatus{
+		{SpaceID: "team-a", Namespaces: 2},
+		{SpaceID: "team-b", Namespaces: 0},
+	}, SpaceRoutingStatus(m, routing))
+	assert.Nil(t, SpaceRoutingStatus(v1alpha2.MondooAuditConfig{}, routing))
+}
@@ -0,0 +1,58 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	vault "github.com/hashicorp/vault-client-go"
+	"github.com/hashicorp/vault-client-go/schema"
+)
+
+// DefaultServiceAccountTokenPath is where the token of the pod's service account is mounted.
+const DefaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+
+// NewVaultClient creates a Vault client that is logged in with the Kubernetes auth method. authPath defaults to
+// "auth/kubernetes". vaultCACert is used to verify Vault's certificate if it is not empty.
+func NewVaultClient(ctx context.Context, vaultAddr, authPath, authRole, saToken string, vaultCACert []byte) (*vault.Client, error) {
+	opts := []vault.ClientOption{
+		vault.WithAddress(vaultAddr),
+	}
+	if len(vaultCACert) > 0 {
+		opts = append(opts, vault.WithTLS(vault.TLSConfiguration{
+			ServerCertificate: vault.ServerCertificateEntry{
+				FromBytes: vaultCACert,
+			},
+		}))
+	}
+
+	client, err := vault.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	if authPath == "" {
+		authPath = "auth/kubernetes"
+	}
+	// The library prepends "auth/" automatically, so strip it if present
+	authMount := strings.TrimPrefix(authPath, "auth/")
+
+	loginResp, err := client.Auth.KubernetesLogin(ctx, schema.KubernetesLoginRequest{
+		Jwt:  saToken,
+		Role: authRole,
+	}, vault.WithMountPath(authMount))
+	if err != nil {
+		return nil, fmt.Errorf("vault Kubernetes auth login failed: %w", err)
+	}
+	if loginResp == nil || loginResp.Auth == nil {
+		return nil, fmt.Errorf("vault Kubernetes auth login returned empty response")
+	}
+
+	if err := client.SetToken(loginResp.Auth.ClientToken); err != nil {
+		return nil, fmt.Errorf("failed to set Vault token: %w", err)
+	}
+	return client, nil
+}
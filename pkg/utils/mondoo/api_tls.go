@@ -0,0 +1,102 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+)
+
+// NewAPITLSConfig returns the TLS configuration for calls to the Mondoo API. The PEM encoded CA certificates
+// are trusted in addition to the system CAs, and the client certificate is presented if certPEM is set. It
+// returns nil if neither is set.
+func NewAPITLSConfig(caPEM, certPEM, keyPEM []byte) (*tls.Config, error) {
+	if len(caPEM) == 0 && len(certPEM) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA bundle contains no valid PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(certPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// APITLSConfig reads the CA bundle and the client certificate of the MondooOperatorConfig from the given
+// namespace and returns the TLS configuration for calls to the Mondoo API. It returns nil if neither is
+// configured.
+func APITLSConfig(ctx context.Context, kubeClient client.Client, namespace string, cfg *v1alpha2.MondooOperatorConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var caPEM, certPEM, keyPEM []byte
+	if ref := cfg.Spec.CABundleSecretRef; ref != nil && ref.Name != "" {
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle secret %s: %w", ref.Name, err)
+		}
+		if caPEM = secret.Data[k8s.CABundleSecretKey]; len(caPEM) == 0 {
+			return nil, fmt.Errorf("CA bundle secret %s missing key %q", ref.Name, k8s.CABundleSecretKey)
+		}
+	}
+	if ref := cfg.Spec.ClientCertificateSecretRef; ref != nil && ref.Name != "" {
+		secret := &corev1.Secret{}
+		if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get client certificate secret %s: %w", ref.Name, err)
+		}
+		certPEM, keyPEM = secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+		if len(certPEM) == 0 || len(keyPEM) == 0 {
+			return nil, fmt.Errorf("client certificate secret %s must have the keys %q and %q", ref.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+		}
+	}
+	return NewAPITLSConfig(caPEM, certPEM, keyPEM)
+}
+
+// APITLSConfigFromFiles returns the TLS configuration for calls to the Mondoo API from the mounted CA bundle
+// and client certificate files. Empty paths are skipped.
+func APITLSConfigFromFiles(caFile, certFile, keyFile string) (*tls.Config, error) {
+	read := func(path string) ([]byte, error) {
+		if path == "" {
+			return nil, nil
+		}
+		return os.ReadFile(path) //nolint:gosec
+	}
+	caPEM, err := read(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	certPEM, err := read(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyPEM, err := read(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate key: %w", err)
+	}
+	return NewAPITLSConfig(caPEM, certPEM, keyPEM)
+}
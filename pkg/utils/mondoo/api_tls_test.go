@@ -0,0 +1,148 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
+)
+
+// testClientCertificate returns a self-signed client certificate and its key, PEM encoded.
+func testClientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mondoo-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestNewAPITLSConfig(t *testing.T) {
+	tlsConfig, err := NewAPITLSConfig(nil, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = NewAPITLSConfig([]byte("not a certificate"), nil, nil)
+	assert.ErrorContains(t, err, "no valid PEM encoded certificates")
+
+	certPEM, keyPEM := testClientCertificate(t)
+	_, err = NewAPITLSConfig(nil, certPEM, []byte("not a key"))
+	assert.ErrorContains(t, err, "invalid client certificate")
+
+	tlsConfig, err = NewAPITLSConfig(certPEM, certPEM, keyPEM)
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+}
+
+func TestNewAPITLSConfig_MutualTLS(t *testing.T) {
+	certPEM, keyPEM := testClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(certPEM))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// Without the client certificate the handshake fails
+	tlsConfig, err := NewAPITLSConfig(caPEM, nil, nil)
+	require.NoError(t, err)
+	httpClient, err := common.DefaultHttpClientWithTLS(nil, nil, nil, nil, tlsConfig)
+	require.NoError(t, err)
+	_, err = common.Request(context.Background(), httpClient, server.URL, "", nil)
+	assert.ErrorIs(t, err, common.ErrNetwork)
+
+	tlsConfig, err = NewAPITLSConfig(caPEM, certPEM, keyPEM)
+	require.NoError(t, err)
+	httpClient, err = common.DefaultHttpClientWithTLS(nil, nil, nil, nil, tlsConfig)
+	require.NoError(t, err)
+	body, err := common.Request(context.Background(), httpClient, server.URL, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+}
+
+func TestAPITLSConfig(t *testing.T) {
+	certPEM, keyPEM := testClientCertificate(t)
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mondoo-ca", Namespace: "mondoo-operator"},
+			Data:       map[string][]byte{"ca.crt": certPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client-cert", Namespace: "mondoo-operator"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "mondoo-operator"},
+		},
+	).Build()
+
+	cfg := func(ca, cert string) *v1alpha2.MondooOperatorConfig {
+		cfg := &v1alpha2.MondooOperatorConfig{}
+		if ca != "" {
+			cfg.Spec.CABundleSecretRef = &corev1.LocalObjectReference{Name: ca}
+		}
+		if cert != "" {
+			cfg.Spec.ClientCertificateSecretRef = &corev1.LocalObjectReference{Name: cert}
+		}
+		return cfg
+	}
+
+	tlsConfig, err := APITLSConfig(context.Background(), kubeClient, "mondoo-operator", nil)
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = APITLSConfig(context.Background(), kubeClient, "mondoo-operator", cfg("", ""))
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = APITLSConfig(context.Background(), kubeClient, "mondoo-operator", cfg("mondoo-ca", "mondoo-client-cert"))
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+
+	_, err = APITLSConfig(context.Background(), kubeClient, "other", cfg("mondoo-ca", ""))
+	assert.ErrorContains(t, err, "failed to get CA bundle secret mondoo-ca")
+
+	_, err = APITLSConfig(context.Background(), kubeClient, "mondoo-operator", cfg("empty", ""))
+	assert.ErrorContains(t, err, `CA bundle secret empty missing key "ca.crt"`)
+
+	_, err = APITLSConfig(context.Background(), kubeClient, "mondoo-operator", cfg("", "empty"))
+	assert.ErrorContains(t, err, "client certificate secret empty must have the keys")
+}
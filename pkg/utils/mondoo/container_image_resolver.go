@@ -15,6 +15,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/imagecache"
 	"go.mondoo.com/mondoo-operator/pkg/version"
 )
@@ -301,3 +302,12 @@ func userImageOrDefault(defaultImage, defaultTag, userImage, userTag, userDigest
 	}
 	return fmt.Sprintf("%s:%s", image, tag)
 }
+
+// CredsSourceInitImage returns the image of the init container that reads the credentials of
+// spec.mondooCredsSource.vault into the scan pods, or an empty string if the credentials are not read from Vault.
+func CredsSourceInitImage(ctx context.Context, resolver ContainerImageResolver, m v1alpha2.MondooAuditConfig, skipImageResolution bool) (string, error) {
+	if m.Spec.MondooCredsSource == nil || m.Spec.MondooCredsSource.Vault == nil {
+		return "", nil
+	}
+	return resolver.MondooOperatorImage(ctx, "", "", "", skipImageResolution)
+}
@@ -0,0 +1,72 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+)
+
+// ErrInvalidCredentials is returned by CheckServiceAccount if the service account can't be loaded or the
+// Mondoo API rejects it.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// CheckServiceAccount validates the service account in the credentials Secret against the Mondoo API. The
+// health of the API is checked first, so an unavailable API is not mistaken for rejected credentials. Only
+// errors that wrap ErrInvalidCredentials mean that the credentials are invalid.
+func CheckServiceAccount(
+	ctx context.Context,
+	kubeClient client.Client,
+	credsSecret *corev1.Secret,
+	operatorConfig *v1alpha2.MondooOperatorConfig,
+	clientBuilder MondooClientBuilder,
+) error {
+	tokenSource, err := TokenSourceForSecret(credsSecret)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load service account: %w", ErrInvalidCredentials, err)
+	}
+
+	opts := mondooclient.MondooClientOptions{
+		ApiEndpoint: tokenSource.ServiceAccount().ApiEndpoint,
+		TokenSource: tokenSource,
+	}
+	proxies, err := k8s.ResolveProxyURLs(ctx, kubeClient, credsSecret.Namespace, operatorConfig)
+	if err != nil {
+		return fmt.Errorf("failed to resolve proxies: %w", err)
+	}
+	opts.HttpProxy, opts.HttpsProxy, opts.NoProxy = proxies.HttpProxy, proxies.HttpsProxy, proxies.NoProxy
+	if opts.TLSConfig, err = APITLSConfig(ctx, kubeClient, credsSecret.Namespace, operatorConfig); err != nil {
+		return fmt.Errorf("failed to load API TLS configuration: %w", err)
+	}
+
+	mc, err := clientBuilder(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create mondoo client: %w", err)
+	}
+
+	health, err := mc.HealthCheck(ctx, &common.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to check the health of the Mondoo API: %w", err)
+	}
+	if health.Status != "SERVING" {
+		return fmt.Errorf("mondoo API is not serving, status %q", health.Status)
+	}
+
+	if _, err := mc.PingPong(ctx, &mondooclient.Ping{}); err != nil {
+		if errors.Is(err, common.ErrUnauthorized) || errors.Is(err, common.ErrForbidden) {
+			return fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+		}
+		return fmt.Errorf("failed to validate the credentials: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient/mock"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+)
+
+func TestCheckServiceAccount(t *testing.T) {
+	tests := []struct {
+		name        string
+		health      string
+		pingErr     error
+		wantInvalid bool
+		wantErr     bool
+	}{
+		{name: "valid", health: "SERVING"},
+		{name: "unauthorized", health: "SERVING", pingErr: fmt.Errorf("ping: %w", common.ErrUnauthorized), wantInvalid: true, wantErr: true},
+		{name: "forbidden", health: "SERVING", pingErr: common.ErrForbidden, wantInvalid: true, wantErr: true},
+		{name: "server error", health: "SERVING", pingErr: errors.New("internal error"), wantErr: true},
+		{name: "not serving", health: "NOT_SERVING", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mClient := mock.NewMockMondooClient(mockCtrl)
+			mClient.EXPECT().HealthCheck(gomock.Any(), gomock.Any()).Return(&common.HealthCheckResponse{Status: test.health}, nil)
+			if test.health == "SERVING" {
+				mClient.EXPECT().PingPong(gomock.Any(), gomock.Any()).Return(&mondooclient.Pong{}, test.pingErr)
+			}
+			builder := func(opts mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) {
+				assert.Equal(t, "https://us.api.mondoo.com", opts.ApiEndpoint)
+				return mClient, nil
+			}
+
+			err := CheckServiceAccount(context.Background(), fake.NewClientBuilder().Build(),
+				testCredsSecret(t, testServiceAccount(t, "sa"), "", ""), nil, builder)
+			if !test.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.wantInvalid, errors.Is(err, ErrInvalidCredentials))
+		})
+	}
+}
+
+func TestCheckServiceAccount_MalformedSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client"},
+		Data:       map[string][]byte{constants.MondooCredsSecretServiceAccountKey: []byte("not json")},
+	}
+	builder := func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) {
+		t.Fatal("the Mondoo API must not be called")
+		return nil, nil
+	}
+
+	err := CheckServiceAccount(context.Background(), fake.NewClientBuilder().Build(), secret, nil, builder)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestSaveServiceAccount_ReplacesExistingServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client", Namespace: "mondoo-operator"},
+		Data: map[string][]byte{
+			constants.MondooCredsSecretServiceAccountKey: []byte("revoked"),
+			"other": []byte("kept"),
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithObjects(existing).Build()
+
+	require.NoError(t, saveServiceAccount(ctx, kubeClient, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client", Namespace: "mondoo-operator"},
+		StringData: map[string]string{constants.MondooCredsSecretServiceAccountKey: "new"},
+	}))
+
+	saved := &corev1.Secret{}
+	require.NoError(t, kubeClient.Get(ctx, client.ObjectKeyFromObject(existing), saved))
+	assert.Equal(t, "new", string(saved.Data[constants.MondooCredsSecretServiceAccountKey]))
+	assert.Equal(t, "kept", string(saved.Data["other"]))
+}
@@ -5,24 +5,30 @@ package mondoo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
-	"go.mondoo.com/mondoo-operator/pkg/constants"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 )
 
 const (
 	defaultGCOlderThan = 2 * time.Hour
 	gcMultiplier       = 2
+
+	// maxReportedGCAssets is the number of stale assets listed in the status of a garbage collection
+	maxReportedGCAssets = 20
 )
 
 // ManagedByLabel returns the ManagedBy value for assets owned by this operator instance.
@@ -50,6 +56,15 @@ func ManagedByNodesLabel(clusterUID string) string {
 	return "mondoo-operator-nodes-" + clusterUID
 }
 
+// ManagedByExternalClusterLabel returns a ManagedBy value specific to the assets of an external cluster, so
+// they can be collected on the cluster's own schedule and purged when the cluster is removed.
+func ManagedByExternalClusterLabel(clusterUID, clusterName string) string {
+	if clusterUID == "" {
+		return "mondoo-operator-external-" + clusterName
+	}
+	return "mondoo-operator-external-" + clusterName + "-" + clusterUID
+}
+
 // GCOlderThan returns the duration threshold for garbage collection based on
 // the scan schedule. It computes 2x the interval between consecutive cron runs
 // so that assets are only GC'd after missing at least one full scan cycle.
@@ -88,49 +103,40 @@ func gcOlderThanFromSchedule(schedule string) time.Duration {
 	return time.Duration(gcMultiplier) * interval
 }
 
-// GarbageCollectAssets builds a Mondoo API client from the operator's credentials and
+// GarbageCollectAssets builds a Mondoo API client from the credentials of the given scan and
 // calls GarbageCollectAssets with the provided request. The server automatically
-// scopes deletion to assets created by the calling service account.
+// scopes deletion to assets created by the calling service account. If spec.gc of the
+// MondooAuditConfig asks for it, the matching assets are listed first, and they are only deleted
+// if dry-run is off and there are not more of them than the confirm threshold. It returns the
+// outcome, or nil if the garbage collection was skipped.
 func GarbageCollectAssets(
 	ctx context.Context,
 	kubeClient client.Client,
 	mondoo *v1alpha2.MondooAuditConfig,
+	scan k8s.ScanType,
 	operatorConfig *v1alpha2.MondooOperatorConfig,
 	clientBuilder func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error),
 	req *mondooclient.GarbageCollectAssetsRequest,
 	logger logr.Logger,
-) error {
+) (*v1alpha2.GarbageCollectionStatus, error) {
 	if clientBuilder == nil {
 		logger.Info("MondooClientBuilder not configured, skipping garbage collection")
-		return nil
+		return nil, nil
 	}
 
-	credsSecret := &corev1.Secret{}
-	credsSecretKey := client.ObjectKey{
-		Namespace: mondoo.Namespace,
-		Name:      mondoo.Spec.MondooCredsSecretRef.Name,
-	}
-	if err := kubeClient.Get(ctx, credsSecretKey, credsSecret); err != nil {
-		return fmt.Errorf("failed to get credentials secret: %w", err)
-	}
-
-	saData, ok := credsSecret.Data[constants.MondooCredsSecretServiceAccountKey]
-	if !ok {
-		return fmt.Errorf("credentials secret missing key %q", constants.MondooCredsSecretServiceAccountKey)
-	}
-
-	sa, err := LoadServiceAccountFromFile(saData)
+	credsSecret, err := k8s.ScanCredentialsSecret(ctx, kubeClient, *mondoo, scan)
 	if err != nil {
-		return fmt.Errorf("failed to load service account: %w", err)
+		return nil, fmt.Errorf("failed to get credentials secret: %w", err)
 	}
 
-	token, err := GenerateTokenFromServiceAccount(*sa, logger)
+	tokenSource, err := TokenSourceForSecret(credsSecret)
 	if err != nil {
-		return fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to load service account: %w", err)
 	}
+	sa := tokenSource.ServiceAccount()
 
 	// Use spaceId override from MondooAuditConfig if set, otherwise use scope from SA credentials.
-	if spaceMrn := k8s.SpaceMrnForAuditConfig(*mondoo); spaceMrn != "" {
+	if spaceMrn := k8s.SpaceMrnForAuditConfig(*mondoo, scan); spaceMrn != "" {
 		req.ScopeMrn = spaceMrn
 		if saSpaceMrn := sa.SpaceMrn; saSpaceMrn != "" && saSpaceMrn != spaceMrn {
 			logger.V(1).Info("spaceId override targets a different space than the service account",
@@ -145,30 +151,327 @@ func GarbageCollectAssets(
 
 	if req.ScopeMrn == "" {
 		logger.Info("Skipping garbage collection: no scope MRN determinable from service account")
-		return nil
+		return nil, nil
 	}
 
 	logger.Info("Preparing GarbageCollectAssets request", "scopeMrn", req.ScopeMrn, "managedBy", req.ManagedBy)
 
 	opts := mondooclient.MondooClientOptions{
 		ApiEndpoint: sa.ApiEndpoint,
-		Token:       token,
+		TokenSource: tokenSource,
 	}
-	if operatorConfig != nil {
-		opts.HttpProxy = operatorConfig.Spec.HttpProxy
-		opts.HttpsProxy = operatorConfig.Spec.HttpsProxy
-		opts.NoProxy = operatorConfig.Spec.NoProxy
+	proxies, err := k8s.ResolveProxyURLs(ctx, kubeClient, mondoo.Namespace, operatorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve proxies: %w", err)
 	}
+	opts.HttpProxy, opts.HttpsProxy, opts.NoProxy = proxies.HttpProxy, proxies.HttpsProxy, proxies.NoProxy
+	tlsConfig, err := APITLSConfig(ctx, kubeClient, mondoo.Namespace, operatorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API TLS configuration: %w", err)
+	}
+	opts.TLSConfig = tlsConfig
 
 	mc, err := clientBuilder(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create mondoo client: %w", err)
+		return nil, fmt.Errorf("failed to create mondoo client: %w", err)
+	}
+
+	result := &v1alpha2.GarbageCollectionStatus{
+		ScopeMrn: req.ScopeMrn,
+		Time:     metav1.Now(),
+		Result:   v1alpha2.GarbageCollectionPurged,
+	}
+	gc := mondoo.Spec.GarbageCollection
+	if gc.DryRun || gc.ConfirmThreshold > 0 {
+		preview, err := mc.PreviewGarbageCollectAssets(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("preview garbage collect assets API call failed: %w", err)
+		}
+		count := GarbageCollectAssetCount(preview)
+		result.MatchingAssets = ptr.To(count)
+		result.Assets = GarbageCollectAssetNames(preview, maxReportedGCAssets)
+		logger.Info("Listed stale assets", "scopeMrn", req.ScopeMrn, "managedBy", req.ManagedBy, "count", count, "assets", result.Assets)
+
+		switch {
+		case gc.DryRun:
+			result.Result = v1alpha2.GarbageCollectionDryRun
+			logger.Info("Dry run, not deleting the stale assets")
+			return result, nil
+		case count > int64(gc.ConfirmThreshold):
+			result.Result = v1alpha2.GarbageCollectionNeedsConfirmation
+			logger.Info("Not deleting the stale assets, more of them match than the confirm threshold allows",
+				"count", count, "confirmThreshold", gc.ConfirmThreshold)
+			return result, nil
+		case count == 0:
+			return result, nil
+		}
 	}
 
 	if err := mc.GarbageCollectAssets(ctx, req); err != nil {
-		return fmt.Errorf("garbage collect assets API call failed: %w", err)
+		return nil, fmt.Errorf("garbage collect assets API call failed: %w", err)
 	}
 
 	logger.Info("GarbageCollectAssets completed successfully")
+	return result, nil
+}
+
+// SharedSpaceError is returned by PurgeAssets if another MondooAuditConfig of the cluster sends assets with the
+// same managed-by label to a space the purge would delete them from. The assets can't be told apart, so the
+// purge is refused.
+type SharedSpaceError struct {
+	// AuditConfig is the namespace and name of the other MondooAuditConfig
+	AuditConfig string
+	ScopeMrn    string
+}
+
+func (e *SharedSpaceError) Error() string {
+	return fmt.Sprintf("MondooAuditConfig %s also sends assets to %s, refusing to purge them", e.AuditConfig, e.ScopeMrn)
+}
+
+// PurgeAssets deletes the assets of all scans of the MondooAuditConfig, whatever their age: the Kubernetes
+// resources of the operator's cluster, the assets of the external clusters, including removed ones listed in
+// the status, the container images and the nodes, in all spaces they are sent to. spec.gc applies, so a dry
+// run only lists the assets, and a purge matching more assets than the confirm threshold fails. The assets of
+// all MondooAuditConfigs of a cluster carry the same managed-by labels, so the purge is refused with a
+// SharedSpaceError while another MondooAuditConfig, which isn't being purged itself, sends assets to one of
+// the spaces.
+func PurgeAssets(
+	ctx context.Context,
+	kubeClient client.Client,
+	m *v1alpha2.MondooAuditConfig,
+	operatorConfig *v1alpha2.MondooOperatorConfig,
+	clientBuilder func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error),
+	clusterUID string,
+	logger logr.Logger,
+) error {
+	purges := assetPurges(m, clusterUID)
+	if err := checkSharedSpaces(ctx, kubeClient, m, purges, clusterUID); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range purges {
+		result, err := GarbageCollectAssets(ctx, kubeClient, p.m, p.scan, operatorConfig, clientBuilder, &p.req, logger)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to purge the assets of the %s scans: %w", p.scan, err))
+		case result != nil && result.Result == v1alpha2.GarbageCollectionNeedsConfirmation:
+			errs = append(errs, fmt.Errorf("%d assets of the %s scans match, more than spec.gc.confirmThreshold allows",
+				*result.MatchingAssets, p.scan))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// assetPurge is a garbage collection of all assets of one scan of a MondooAuditConfig.
+type assetPurge struct {
+	m    *v1alpha2.MondooAuditConfig
+	scan k8s.ScanType
+	req  mondooclient.GarbageCollectAssetsRequest
+}
+
+// assetPurges returns the garbage collections that delete all assets of the MondooAuditConfig.
+func assetPurges(m *v1alpha2.MondooAuditConfig, clusterUID string) []assetPurge {
+	type purgeRequest struct {
+		scans []k8s.ScanType
+		// routed purges the assets of the namespaces routed to other spaces, too
+		routed bool
+		req    mondooclient.GarbageCollectAssetsRequest
+	}
+	requests := []purgeRequest{
+		{
+			scans:  []k8s.ScanType{k8s.KubernetesResourcesScan},
+			routed: true,
+			req:    mondooclient.GarbageCollectAssetsRequest{ManagedBy: ManagedByLabel(clusterUID), PlatformRuntime: "k8s-cluster"},
+		},
+		{
+			scans:  []k8s.ScanType{k8s.ContainersScan},
+			routed: true,
+			req: mondooclient.GarbageCollectAssetsRequest{
+				ManagedBy:       ManagedByContainersLabel(clusterUID),
+				PlatformRuntime: "docker-image",
+				Labels:          map[string]string{"k8s.mondoo.com/kind": "container-image"},
+			},
+		},
+		{
+			scans: []k8s.ScanType{k8s.NodesScan},
+			req: mondooclient.GarbageCollectAssetsRequest{
+				ManagedBy: ManagedByNodesLabel(clusterUID),
+				Labels:    map[string]string{"k8s.mondoo.com/kind": "node"},
+			},
+		},
+	}
+
+	for _, cluster := range m.Spec.KubernetesResources.ExternalClusters {
+		requests = append(requests, purgeRequest{
+			scans: []k8s.ScanType{k8s.ExternalClusterScan(cluster.Name)},
+			req:   mondooclient.GarbageCollectAssetsRequest{ManagedBy: ManagedByExternalClusterLabel(clusterUID, cluster.Name)},
+		})
+	}
+
+	var purges []assetPurge
+	for _, r := range requests {
+		// Scans that send their assets to the same space with the same credentials are purged once
+		purged := map[string]bool{}
+		for _, scan := range r.scans {
+			spaceID, credsRef := k8s.ScanCredentials(*m, scan)
+			if key := spaceID + "/" + credsRef.Name; !purged[key] {
+				purged[key] = true
+				purges = append(purges, assetPurge{m: m, scan: scan, req: r.req})
+			}
+		}
+		if !r.routed {
+			continue
+		}
+		for _, spaceID := range k8s.RoutedSpaceIDs(*m) {
+			spaceConfig := k8s.SpaceAuditConfig(*m, k8s.SpaceRoute{SpaceID: spaceID})
+			purges = append(purges, assetPurge{m: &spaceConfig, scan: r.scans[0], req: r.req})
+		}
+	}
+	// Removed external clusters whose assets weren't purged yet still use the space recorded in the status
+	for _, cluster := range RemovedExternalClusters(*m) {
+		removed := RemovedExternalClusterAuditConfig(*m, cluster)
+		purges = append(purges, assetPurge{
+			m:    &removed,
+			scan: k8s.ExternalClusterScan(cluster.Name),
+			req:  mondooclient.GarbageCollectAssetsRequest{ManagedBy: ManagedByExternalClusterLabel(clusterUID, cluster.Name)},
+		})
+	}
+	return purges
+}
+
+// checkSharedSpaces returns a SharedSpaceError if another MondooAuditConfig sends assets with the managed-by
+// label of one of the purges to the same space. MondooAuditConfigs that are being deleted with deletionPolicy
+// Purge themselves don't count, their assets are purged anyway.
+func checkSharedSpaces(
+	ctx context.Context, kubeClient client.Client, m *v1alpha2.MondooAuditConfig, purges []assetPurge, clusterUID string,
+) error {
+	auditConfigs := &v1alpha2.MondooAuditConfigList{}
+	if err := kubeClient.List(ctx, auditConfigs); err != nil {
+		return fmt.Errorf("failed to list the MondooAuditConfigs: %w", err)
+	}
+
+	scopes := map[string]string{}
+	purged := map[string]bool{}
+	for _, p := range purges {
+		scopeMrn, err := scanScopeMrn(ctx, kubeClient, *p.m, p.scan, scopes)
+		if err != nil {
+			return err
+		}
+		purged[p.req.ManagedBy+" "+scopeMrn] = true
+	}
+
+	for i := range auditConfigs.Items {
+		other := &auditConfigs.Items[i]
+		if other.UID == m.UID ||
+			(other.DeletionTimestamp != nil && other.Spec.DeletionPolicy == v1alpha2.DeletionPolicyPurge) {
+			continue
+		}
+		for _, p := range assetPurges(other, clusterUID) {
+			if !slices.ContainsFunc(purges, func(own assetPurge) bool { return own.req.ManagedBy == p.req.ManagedBy }) {
+				continue
+			}
+			scopeMrn, err := scanScopeMrn(ctx, kubeClient, *p.m, p.scan, scopes)
+			if err != nil {
+				return fmt.Errorf("failed to check the space of MondooAuditConfig %s/%s: %w", other.Namespace, other.Name, err)
+			}
+			if purged[p.req.ManagedBy+" "+scopeMrn] {
+				return &SharedSpaceError{AuditConfig: other.Namespace + "/" + other.Name, ScopeMrn: scopeMrn}
+			}
+		}
+	}
 	return nil
 }
+
+// scanScopeMrn returns the MRN of the space the assets of the scan are sent to, like GarbageCollectAssets
+// determines it. The scopes of the service accounts are cached by namespace and credentials.
+func scanScopeMrn(
+	ctx context.Context, kubeClient client.Client, m v1alpha2.MondooAuditConfig, scan k8s.ScanType, cache map[string]string,
+) (string, error) {
+	if spaceMrn := k8s.SpaceMrnForAuditConfig(m, scan); spaceMrn != "" {
+		return spaceMrn, nil
+	}
+	_, credsRef := k8s.ScanCredentials(m, scan)
+	key := m.Namespace + "/" + credsRef.Name
+	if k8s.UsesCredsSource(m, scan) {
+		key = m.Namespace + "/" + string(m.UID) + "/creds-source"
+	}
+	if scopeMrn, ok := cache[key]; ok {
+		return scopeMrn, nil
+	}
+
+	credsSecret, err := k8s.ScanCredentialsSecret(ctx, kubeClient, m, scan)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials secret: %w", err)
+	}
+	sa, err := k8s.GetServiceAccountFromSecret(*credsSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to load service account: %w", err)
+	}
+	scopeMrn := sa.ScopeMrn
+	if scopeMrn == "" {
+		scopeMrn = sa.SpaceMrn
+	}
+	cache[key] = scopeMrn
+	return scopeMrn, nil
+}
+
+// RemovedExternalClusters returns the external clusters in the status that were removed from the spec.
+func RemovedExternalClusters(m v1alpha2.MondooAuditConfig) []v1alpha2.ExternalClusterStatus {
+	var removed []v1alpha2.ExternalClusterStatus
+	for _, cluster := range m.Status.ExternalClusters {
+		if !slices.ContainsFunc(m.Spec.KubernetesResources.ExternalClusters, func(c v1alpha2.ExternalCluster) bool {
+			return c.Name == cluster.Name
+		}) {
+			removed = append(removed, cluster)
+		}
+	}
+	return removed
+}
+
+// RemovedExternalClusterAuditConfig returns a copy of the MondooAuditConfig whose scans of the removed external
+// cluster use the space and credentials recorded in its status.
+func RemovedExternalClusterAuditConfig(m v1alpha2.MondooAuditConfig, cluster v1alpha2.ExternalClusterStatus) v1alpha2.MondooAuditConfig {
+	removed := *m.DeepCopy()
+	removed.Spec.SpaceID = cluster.SpaceID
+	removed.Spec.MondooCredsSecretRef = corev1.LocalObjectReference{Name: cluster.MondooCredsSecretRef}
+	removed.Spec.KubernetesResources.SpaceID, removed.Spec.KubernetesResources.MondooCredsSecretRef = "", nil
+	removed.Spec.KubernetesResources.ExternalClusters = nil
+	return removed
+}
+
+// GarbageCollectAssetCount returns the number of assets a garbage collection matches.
+func GarbageCollectAssetCount(preview *mondooclient.PreviewGarbageCollectAssetsResponse) int64 {
+	return max(preview.TotalCount, int64(len(preview.Assets)))
+}
+
+// GarbageCollectAssetNames returns the names of up to limit assets a garbage collection matches. Assets
+// without a name are listed with their MRN.
+func GarbageCollectAssetNames(preview *mondooclient.PreviewGarbageCollectAssetsResponse, limit int) []string {
+	var names []string
+	for _, asset := range preview.Assets {
+		if len(names) == limit {
+			break
+		}
+		name := asset.Name
+		if name == "" {
+			name = asset.Mrn
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// SetGarbageCollectionStatus replaces the garbage collection results of the given scan in the status.
+func SetGarbageCollectionStatus(status *v1alpha2.MondooAuditConfigStatus, scan k8s.ScanType, results []*v1alpha2.GarbageCollectionStatus) {
+	status.GarbageCollection = slices.DeleteFunc(status.GarbageCollection, func(gc v1alpha2.GarbageCollectionStatus) bool {
+		return gc.Scan == string(scan)
+	})
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		result.Scan = string(scan)
+		status.GarbageCollection = append(status.GarbageCollection, *result)
+	}
+}
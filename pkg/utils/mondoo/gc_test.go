@@ -4,10 +4,22 @@
 package mondoo
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"go.mondoo.com/mondoo-operator/api/v1alpha2"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient/mock"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 )
 
 func TestManagedByLabels(t *testing.T) {
@@ -26,9 +38,14 @@ func TestManagedByLabels(t *testing.T) {
 		assert.Equal(t, "mondoo-operator-nodes-abc123", ManagedByNodesLabel("abc123"))
 	})
 
+	t.Run("ManagedByExternalClusterLabel", func(t *testing.T) {
+		assert.Equal(t, "mondoo-operator-external-prod", ManagedByExternalClusterLabel("", "prod"))
+		assert.Equal(t, "mondoo-operator-external-prod-abc123", ManagedByExternalClusterLabel("abc123", "prod"))
+	})
+
 	t.Run("labels are distinct", func(t *testing.T) {
 		uid := "test-uid"
-		labels := []string{ManagedByLabel(uid), ManagedByContainersLabel(uid), ManagedByNodesLabel(uid)}
+		labels := []string{ManagedByLabel(uid), ManagedByContainersLabel(uid), ManagedByNodesLabel(uid), ManagedByExternalClusterLabel(uid, "prod")}
 		for i := range labels {
 			for j := i + 1; j < len(labels); j++ {
 				assert.NotEqual(t, labels[i], labels[j])
@@ -81,3 +98,72 @@ func TestGCOlderThanFromSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestGarbageCollectAssets_Preview(t *testing.T) {
+	preview := &mondooclient.PreviewGarbageCollectAssetsResponse{
+		Assets: []mondooclient.GarbageCollectAsset{
+			{Mrn: "//assets.api.mondoo.app/spaces/test/assets/1", Name: "deployment-a"},
+			{Mrn: "//assets.api.mondoo.app/spaces/test/assets/2"},
+		},
+		TotalCount: 3,
+	}
+	tests := []struct {
+		name       string
+		gc         v1alpha2.GarbageCollection
+		wantResult v1alpha2.GarbageCollectionResult
+		wantPurge  bool
+	}{
+		{name: "dry run", gc: v1alpha2.GarbageCollection{DryRun: true, ConfirmThreshold: 10}, wantResult: v1alpha2.GarbageCollectionDryRun},
+		{name: "above threshold", gc: v1alpha2.GarbageCollection{ConfirmThreshold: 2}, wantResult: v1alpha2.GarbageCollectionNeedsConfirmation},
+		{name: "at threshold", gc: v1alpha2.GarbageCollection{ConfirmThreshold: 3}, wantResult: v1alpha2.GarbageCollectionPurged, wantPurge: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sa := testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/sa")
+			sa.SpaceMrn = "//captain.api.mondoo.app/spaces/test"
+			secret := testCredsSecret(t, sa, "", "")
+			secret.Namespace = "mondoo-operator"
+			m := &v1alpha2.MondooAuditConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "mondoo", Namespace: "mondoo-operator"},
+				Spec: v1alpha2.MondooAuditConfigSpec{
+					MondooCredsSecretRef: corev1.LocalObjectReference{Name: secret.Name},
+					GarbageCollection:    test.gc,
+				},
+			}
+
+			mClient := mock.NewMockMondooClient(gomock.NewController(t))
+			req := &mondooclient.GarbageCollectAssetsRequest{ManagedBy: "mondoo-operator-nodes"}
+			mClient.EXPECT().PreviewGarbageCollectAssets(gomock.Any(), req).Return(preview, nil)
+			if test.wantPurge {
+				mClient.EXPECT().GarbageCollectAssets(gomock.Any(), req).Return(nil)
+			}
+			builder := func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error) { return mClient, nil }
+
+			result, err := GarbageCollectAssets(context.Background(), fake.NewClientBuilder().WithObjects(secret).Build(),
+				m, k8s.NodesScan, nil, builder, req, logr.Discard())
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			assert.Equal(t, test.wantResult, result.Result)
+			assert.Equal(t, "//captain.api.mondoo.app/spaces/test", result.ScopeMrn)
+			assert.Equal(t, int64(3), *result.MatchingAssets)
+			assert.Equal(t, []string{"deployment-a", "//assets.api.mondoo.app/spaces/test/assets/2"}, result.Assets)
+		})
+	}
+}
+
+func TestSetGarbageCollectionStatus(t *testing.T) {
+	status := &v1alpha2.MondooAuditConfigStatus{GarbageCollection: []v1alpha2.GarbageCollectionStatus{
+		{Scan: "nodes", ScopeMrn: "old", Result: v1alpha2.GarbageCollectionPurged},
+		{Scan: "containers", ScopeMrn: "kept", Result: v1alpha2.GarbageCollectionPurged},
+	}}
+
+	SetGarbageCollectionStatus(status, k8s.NodesScan, []*v1alpha2.GarbageCollectionStatus{
+		{ScopeMrn: "new", Result: v1alpha2.GarbageCollectionDryRun},
+		nil,
+	})
+
+	assert.Equal(t, []v1alpha2.GarbageCollectionStatus{
+		{Scan: "containers", ScopeMrn: "kept", Result: v1alpha2.GarbageCollectionPurged},
+		{Scan: "nodes", ScopeMrn: "new", Result: v1alpha2.GarbageCollectionDryRun},
+	}, status.GarbageCollection)
+}
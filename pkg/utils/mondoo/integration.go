@@ -6,10 +6,13 @@ package mondoo
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/go-logr/logr"
+	"go.mondoo.com/mondoo-operator/pkg/client/common"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
 )
 
@@ -30,24 +33,21 @@ func IntegrationCheckIn(
 	ctx context.Context,
 	integrationMrn string,
 	configurationHash string,
-	sa mondooclient.ServiceAccountCredentials,
+	tokenSource *TokenSource,
 	mondooClientBuilder MondooClientBuilder,
 	httpProxy *string,
 	httpsProxy *string,
 	noProxy *string,
+	tlsConfig *tls.Config,
 	logger logr.Logger,
 ) (*IntegrationCheckInResult, error) {
-	token, err := GenerateTokenFromServiceAccount(sa, logger)
-	if err != nil {
-		msg := "unable to generate token from service account"
-		return nil, fmt.Errorf("%s: %s", msg, err)
-	}
 	mondooClient, err := mondooClientBuilder(mondooclient.MondooClientOptions{
-		ApiEndpoint: sa.ApiEndpoint,
-		Token:       token,
+		ApiEndpoint: tokenSource.ServiceAccount().ApiEndpoint,
+		TokenSource: tokenSource,
 		HttpProxy:   httpProxy,
 		HttpsProxy:  httpsProxy,
 		NoProxy:     noProxy,
+		TLSConfig:   tlsConfig,
 	})
 	if err != nil {
 		return nil, err
@@ -59,7 +59,10 @@ func IntegrationCheckIn(
 	})
 	if err != nil {
 		msg := "failed to CheckIn() to Mondoo API"
-		return nil, fmt.Errorf("%s: %s", msg, err)
+		if errors.Is(err, common.ErrUnauthorized) || errors.Is(err, common.ErrForbidden) {
+			msg = "Mondoo API rejected the integration credentials on CheckIn()"
+		}
+		return nil, fmt.Errorf("%s: %w", msg, err)
 	}
 
 	logger.Info("CheckIn response", "configurationMatch", checkInResp.ConfigurationMatch, "sentHash", configurationHash)
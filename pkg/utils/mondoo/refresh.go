@@ -9,12 +9,10 @@ import (
 	"sort"
 
 	"github.com/go-logr/logr"
-	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"go.mondoo.com/mondoo-operator/api/v1alpha2"
 	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
-	"go.mondoo.com/mondoo-operator/pkg/constants"
 	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
 )
 
@@ -32,29 +30,17 @@ func RefreshAssetScores(
 		return nil, nil
 	}
 
-	credsSecret := &corev1.Secret{}
-	credsSecretKey := client.ObjectKey{
-		Namespace: mondoo.Namespace,
-		Name:      mondoo.Spec.MondooCredsSecretRef.Name,
-	}
-	if err := kubeClient.Get(ctx, credsSecretKey, credsSecret); err != nil {
+	// Scores are refreshed for container images, so the credentials and space of the container scans apply
+	credsSecret, err := k8s.ScanCredentialsSecret(ctx, kubeClient, *mondoo, k8s.ContainersScan)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get credentials secret: %w", err)
 	}
 
-	saData, ok := credsSecret.Data[constants.MondooCredsSecretServiceAccountKey]
-	if !ok {
-		return nil, fmt.Errorf("credentials secret missing key %q", constants.MondooCredsSecretServiceAccountKey)
-	}
-
-	sa, err := LoadServiceAccountFromFile(saData)
+	tokenSource, err := TokenSourceForSecret(credsSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load service account: %w", err)
 	}
-
-	token, err := GenerateTokenFromServiceAccount(*sa, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
-	}
+	sa := tokenSource.ServiceAccount()
 
 	req := &mondooclient.RefreshAssetScoresRequest{
 		ManagedBy:         ManagedByContainersLabel(clusterUID),
@@ -66,7 +52,7 @@ func RefreshAssetScores(
 		req.CacheTTLSeconds = int64(sc.CacheTTL.Seconds())
 	}
 
-	if spaceMrn := k8s.SpaceMrnForAuditConfig(*mondoo); spaceMrn != "" {
+	if spaceMrn := k8s.SpaceMrnForAuditConfig(*mondoo, k8s.ContainersScan); spaceMrn != "" {
 		req.ScopeMrn = spaceMrn
 	} else {
 		req.ScopeMrn = sa.ScopeMrn
@@ -84,13 +70,18 @@ func RefreshAssetScores(
 
 	opts := mondooclient.MondooClientOptions{
 		ApiEndpoint: sa.ApiEndpoint,
-		Token:       token,
+		TokenSource: tokenSource,
 	}
-	if operatorConfig != nil {
-		opts.HttpProxy = operatorConfig.Spec.HttpProxy
-		opts.HttpsProxy = operatorConfig.Spec.HttpsProxy
-		opts.NoProxy = operatorConfig.Spec.NoProxy
+	proxies, err := k8s.ResolveProxyURLs(ctx, kubeClient, mondoo.Namespace, operatorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve proxies: %w", err)
+	}
+	opts.HttpProxy, opts.HttpsProxy, opts.NoProxy = proxies.HttpProxy, proxies.HttpsProxy, proxies.NoProxy
+	tlsConfig, err := APITLSConfig(ctx, kubeClient, mondoo.Namespace, operatorConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API TLS configuration: %w", err)
 	}
+	opts.TLSConfig = tlsConfig
 
 	mc, err := clientBuilder(opts)
 	if err != nil {
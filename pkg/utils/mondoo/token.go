@@ -40,13 +40,17 @@ func GenerateTokenFromServiceAccount(serviceAccount mondooclient.ServiceAccountC
 }
 
 func CreateSignedToken(pk *ecdsa.PrivateKey, sa mondooclient.ServiceAccountCredentials, logger logr.Logger) (string, error) {
-	issuedAt := time.Now().Unix()
+	return createSignedToken(pk, sa, time.Now(), logger)
+}
+
+func createSignedToken(pk *ecdsa.PrivateKey, sa mondooclient.ServiceAccountCredentials, now time.Time, logger logr.Logger) (string, error) {
+	issuedAt := now.Unix()
 
 	token := jwt.NewWithClaims(jwt.SigningMethodES384, jwt.MapClaims{
 		"sub": sa.Mrn,
 		"iss": tokenIssuer,
 		"iat": issuedAt,
-		"exp": issuedAt + 60, // expire in 1 minute
+		"exp": issuedAt + int64(tokenLifetime.Seconds()),
 		"nbf": issuedAt,
 	})
 
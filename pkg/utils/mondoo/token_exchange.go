@@ -5,6 +5,7 @@ package mondoo
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -25,8 +26,8 @@ import (
 type MondooClientBuilder func(mondooclient.MondooClientOptions) (mondooclient.MondooClient, error)
 
 // CreateServiceAccountFromToken will take the provided Mondoo token and exchange it with the Mondoo API
-// for a long lived Mondoo ServiceAccount
-func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client, mondooClientBuilder MondooClientBuilder, withConsoleIntegration bool, serviceAccountSecret types.NamespacedName, tokenSecretData string, httpProxy *string, httpsProxy *string, noProxy *string, log logr.Logger) error {
+// for a long lived Mondoo ServiceAccount. If the Secret already exists, its service account is replaced.
+func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client, mondooClientBuilder MondooClientBuilder, withConsoleIntegration bool, serviceAccountSecret types.NamespacedName, tokenSecretData string, httpProxy *string, httpsProxy *string, noProxy *string, tlsConfig *tls.Config, log logr.Logger) error {
 	jwtString := strings.TrimSpace(tokenSecretData)
 
 	parser := &jwt.Parser{}
@@ -50,6 +51,7 @@ func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client
 		HttpProxy:   httpProxy,
 		HttpsProxy:  httpsProxy,
 		NoProxy:     noProxy,
+		TLSConfig:   tlsConfig,
 	}
 
 	mClient, err := mondooClientBuilder(opts)
@@ -92,15 +94,14 @@ func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client
 			constants.MondooCredsSecretServiceAccountKey: credsBytes,
 			constants.MondooCredsSecretIntegrationMRNKey: []byte(integrationMrn),
 		}
-		_, err = k8s.CreateIfNotExist(ctx, kubeClient, tokenSecret, tokenSecret)
-		if err != nil {
+		if err := saveServiceAccount(ctx, kubeClient, tokenSecret); err != nil {
 			log.Error(err, "error while trying to save Mondoo service account into secret")
 			return err
 		}
 
 		// No easy way to retry this one-off CheckIn(). An error on initial CheckIn()
 		// means we'll just retry on the regularly scheduled interval via the integration controller
-		_ = performInitialCheckIn(ctx, mondooClientBuilder, integrationMrn, *resp.Creds, httpProxy, httpsProxy, noProxy, log)
+		_ = performInitialCheckIn(ctx, mondooClientBuilder, integrationMrn, *resp.Creds, httpProxy, httpsProxy, noProxy, tlsConfig, log)
 	} else {
 		// Do a vanilla token-for-service-account exchange
 		resp, err := mClient.ExchangeRegistrationToken(ctx, &mondooclient.ExchangeRegistrationTokenInput{
@@ -115,8 +116,7 @@ func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client
 		tokenSecret.StringData = map[string]string{
 			constants.MondooCredsSecretServiceAccountKey: resp.ServiceAccount,
 		}
-		_, err = k8s.CreateIfNotExist(ctx, kubeClient, tokenSecret, tokenSecret)
-		if err != nil {
+		if err := saveServiceAccount(ctx, kubeClient, tokenSecret); err != nil {
 			log.Error(err, "error while trying to save Mondoo service account into secret")
 			return err
 		}
@@ -127,8 +127,34 @@ func CreateServiceAccountFromToken(ctx context.Context, kubeClient client.Client
 	return nil
 }
 
-func performInitialCheckIn(ctx context.Context, mondooClientBuilder MondooClientBuilder, integrationMrn string, sa mondooclient.ServiceAccountCredentials, httpProxy *string, httpsProxy *string, noProxy *string, logger logr.Logger) error {
-	if _, err := IntegrationCheckIn(ctx, integrationMrn, "", sa, mondooClientBuilder, httpProxy, httpsProxy, noProxy, logger); err != nil {
+// saveServiceAccount creates the Secret of the service account. If the Secret exists because the service
+// account is re-created, the keys of the new Secret replace the ones of the existing Secret.
+func saveServiceAccount(ctx context.Context, kubeClient client.Client, secret *corev1.Secret) error {
+	existing := &corev1.Secret{}
+	created, err := k8s.CreateIfNotExist(ctx, kubeClient, existing, secret)
+	if err != nil || created {
+		return err
+	}
+
+	if existing.Data == nil {
+		existing.Data = map[string][]byte{}
+	}
+	for key, value := range secret.Data {
+		existing.Data[key] = value
+	}
+	for key, value := range secret.StringData {
+		existing.Data[key] = []byte(value)
+	}
+	return kubeClient.Update(ctx, existing)
+}
+
+func performInitialCheckIn(ctx context.Context, mondooClientBuilder MondooClientBuilder, integrationMrn string, sa mondooclient.ServiceAccountCredentials, httpProxy *string, httpsProxy *string, noProxy *string, tlsConfig *tls.Config, logger logr.Logger) error {
+	tokenSource, err := NewTokenSource(sa)
+	if err != nil {
+		logger.Error(err, "failed to load the created service account for the initial CheckIn()", "integrationMRN", integrationMrn)
+		return err
+	}
+	if _, err := IntegrationCheckIn(ctx, integrationMrn, "", tokenSource, mondooClientBuilder, httpProxy, httpsProxy, noProxy, tlsConfig, logger); err != nil {
 		logger.Error(err, "initial CheckIn() failed, will CheckIn() periodically", "integrationMRN", integrationMrn)
 		return err
 	}
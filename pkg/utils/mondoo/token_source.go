@@ -0,0 +1,164 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/pkg/utils/k8s"
+)
+
+const (
+	// tokenLifetime is how long the tokens signed for the Mondoo API are valid
+	tokenLifetime = 60 * time.Second
+	// tokenRefreshMargin is how long before its expiry a token is replaced, so requests don't race its expiry
+	tokenRefreshMargin = 15 * time.Second
+)
+
+// TokenSource signs the tokens of a service account for the Mondoo API. The private key is parsed once, and
+// a token is reused until shortly before it expires. It is safe for concurrent use.
+type TokenSource struct {
+	sa  mondooclient.ServiceAccountCredentials
+	key *ecdsa.PrivateKey
+	now func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+var _ mondooclient.TokenSource = &TokenSource{}
+
+// NewTokenSource parses the private key of the service account and returns a TokenSource for it.
+func NewTokenSource(sa mondooclient.ServiceAccountCredentials) (*TokenSource, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("found no PEM block in private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key data: %w", err)
+	}
+	pk, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("AuthKey must be of type ecdsa.PrivateKey")
+	}
+	return &TokenSource{sa: sa, key: pk, now: time.Now}, nil
+}
+
+// ServiceAccount returns the credentials of the service account.
+func (s *TokenSource) ServiceAccount() mondooclient.ServiceAccountCredentials {
+	return s.sa
+}
+
+// Token returns a token that is valid for at least tokenRefreshMargin.
+func (s *TokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Add(tokenRefreshMargin).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	token, err := createSignedToken(s.key, s.sa, now, logr.Discard())
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry = token, now.Add(tokenLifetime)
+	return token, nil
+}
+
+// tokenSourceCache holds the token sources shared by all components of the operator. An entry is replaced
+// when its version changes, so rotated credentials are used right away. Entries of deleted Secrets are
+// removed with ForgetSecretTokenSource.
+type tokenSourceCache struct {
+	mu      sync.Mutex
+	entries map[string]tokenSourceEntry
+}
+
+type tokenSourceEntry struct {
+	version string
+	source  *TokenSource
+}
+
+var tokenSources = &tokenSourceCache{entries: map[string]tokenSourceEntry{}}
+
+func (c *tokenSourceCache) get(key, version string, load func() (*mondooclient.ServiceAccountCredentials, error)) (*TokenSource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok && entry.version == version {
+		return entry.source, nil
+	}
+
+	sa, err := load()
+	if err != nil {
+		return nil, err
+	}
+	source, err := NewTokenSource(*sa)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = tokenSourceEntry{version: version, source: source}
+	return source, nil
+}
+
+func (c *tokenSourceCache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// TokenSourceForSecret returns the shared TokenSource of the service account in the credentials Secret. It is
+// keyed by the Secret's UID and resourceVersion, so all callers share it until the Secret changes.
+func TokenSourceForSecret(secret *corev1.Secret) (*TokenSource, error) {
+	if _, ok := secret.Data[constants.MondooCredsSecretServiceAccountKey]; !ok {
+		return nil, fmt.Errorf("credentials secret missing key %q", constants.MondooCredsSecretServiceAccountKey)
+	}
+	load := func() (*mondooclient.ServiceAccountCredentials, error) {
+		return k8s.GetServiceAccountFromSecret(*secret)
+	}
+	if secret.UID == "" {
+		// Secrets that were not read from the API server can't be told apart, so they are not cached
+		sa, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return NewTokenSource(*sa)
+	}
+	return tokenSources.get(secretTokenSourceKey(secret.UID), secret.ResourceVersion, load)
+}
+
+// ForgetSecretTokenSource drops the shared TokenSource of a deleted Secret, so its private key isn't kept for
+// the life of the operator.
+func ForgetSecretTokenSource(uid types.UID) {
+	tokenSources.forget(secretTokenSourceKey(uid))
+}
+
+func secretTokenSourceKey(uid types.UID) string {
+	return "secret/" + string(uid)
+}
+
+// TokenSourceForFile returns the shared TokenSource of the service account in the mounted config file with the
+// given path and content. It is replaced when the content changes.
+func TokenSourceForFile(path string, data []byte) (*TokenSource, error) {
+	sum := sha256.Sum256(data)
+	return tokenSources.get("file/"+path, hex.EncodeToString(sum[:]), func() (*mondooclient.ServiceAccountCredentials, error) {
+		return LoadServiceAccountFromFile(data)
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright Mondoo, Inc. 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package mondoo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"go.mondoo.com/mondoo-operator/pkg/client/mondooclient"
+	"go.mondoo.com/mondoo-operator/pkg/constants"
+	"go.mondoo.com/mondoo-operator/tests/credentials"
+)
+
+func testServiceAccount(t *testing.T, mrn string) mondooclient.ServiceAccountCredentials {
+	return mondooclient.ServiceAccountCredentials{
+		Mrn:         mrn,
+		PrivateKey:  credentials.MondooServiceAccount(t),
+		ApiEndpoint: "https://us.api.mondoo.com",
+	}
+}
+
+func testCredsSecret(t *testing.T, sa mondooclient.ServiceAccountCredentials, uid, resourceVersion string) *corev1.Secret {
+	data, err := json.Marshal(sa) //nolint:gosec
+	require.NoError(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mondoo-client", UID: types.UID("uid-" + uid), ResourceVersion: resourceVersion},
+		Data:       map[string][]byte{constants.MondooCredsSecretServiceAccountKey: data},
+	}
+}
+
+func TestTokenSource_ReusesTokenUntilShortlyBeforeExpiry(t *testing.T) {
+	source, err := NewTokenSource(testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/sa"))
+	require.NoError(t, err)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token()
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, _, err = (&jwt.Parser{}).ParseUnverified(token, claims)
+	require.NoError(t, err)
+	assert.Equal(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/sa", claims["sub"])
+	assert.Equal(t, float64(now.Unix()+60), claims["exp"])
+
+	now = now.Add(tokenLifetime - tokenRefreshMargin - time.Second)
+	reused, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, token, reused)
+
+	now = now.Add(2 * time.Second)
+	refreshed, err := source.Token()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, refreshed)
+}
+
+func TestNewTokenSource_InvalidKey(t *testing.T) {
+	_, err := NewTokenSource(mondooclient.ServiceAccountCredentials{Mrn: "mrn", PrivateKey: "not a key"})
+	assert.ErrorContains(t, err, "found no PEM block in private key")
+}
+
+func TestTokenSourceForSecret(t *testing.T) {
+	sa := testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/sa")
+
+	first, err := TokenSourceForSecret(testCredsSecret(t, sa, "a", "1"))
+	require.NoError(t, err)
+	assert.Equal(t, sa.Mrn, first.ServiceAccount().Mrn)
+
+	// The same Secret version shares the token source
+	same, err := TokenSourceForSecret(testCredsSecret(t, sa, "a", "1"))
+	require.NoError(t, err)
+	assert.Same(t, first, same)
+
+	// Rotated credentials replace it right away
+	rotated := testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/rotated")
+	updated, err := TokenSourceForSecret(testCredsSecret(t, rotated, "a", "2"))
+	require.NoError(t, err)
+	assert.NotSame(t, first, updated)
+	assert.Equal(t, rotated.Mrn, updated.ServiceAccount().Mrn)
+
+	// Other Secrets have their own token source
+	other, err := TokenSourceForSecret(testCredsSecret(t, sa, "b", "2"))
+	require.NoError(t, err)
+	assert.NotSame(t, updated, other)
+
+	_, err = TokenSourceForSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{UID: "uid-c"}})
+	assert.ErrorContains(t, err, "credentials secret missing key")
+
+	// Deleted Secrets are dropped from the cache
+	ForgetSecretTokenSource("uid-b")
+	recreated, err := TokenSourceForSecret(testCredsSecret(t, sa, "b", "2"))
+	require.NoError(t, err)
+	assert.NotSame(t, other, recreated)
+}
+
+func TestTokenSourceForFile(t *testing.T) {
+	data, err := json.Marshal(testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/sa")) //nolint:gosec
+	require.NoError(t, err)
+
+	first, err := TokenSourceForFile("/etc/opt/mondoo/mondoo.yml", data)
+	require.NoError(t, err)
+	same, err := TokenSourceForFile("/etc/opt/mondoo/mondoo.yml", data)
+	require.NoError(t, err)
+	assert.Same(t, first, same)
+
+	rotated, err := json.Marshal(testServiceAccount(t, "//agents.api.mondoo.app/spaces/test/serviceaccounts/rotated")) //nolint:gosec
+	require.NoError(t, err)
+	updated, err := TokenSourceForFile("/etc/opt/mondoo/mondoo.yml", rotated)
+	require.NoError(t, err)
+	assert.NotSame(t, first, updated)
+
+	_, err = TokenSourceForFile("/etc/opt/mondoo/invalid.yml", []byte("mrn: only"))
+	assert.ErrorContains(t, err, "private_key is missing")
+}